@@ -2,10 +2,12 @@ package account
 
 import (
 	"context"
+	"time"
 
 	"github.com/suryanshp1/go-microservice/account/pb"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
 type Client struct {
@@ -33,10 +35,7 @@ func (c *Client) PostAccount(ctx context.Context, name string) (*Account, error)
 	if err != nil {
 		return nil, err
 	}
-	return &Account{
-		ID:   resp.Account.Id,
-		Name: resp.Account.Name,
-	}, nil
+	return accountFromProto(resp.Account), nil
 }
 
 func (c *Client) GetAccount(ctx context.Context, id string) (*Account, error) {
@@ -45,10 +44,7 @@ func (c *Client) GetAccount(ctx context.Context, id string) (*Account, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &Account{
-		ID:   resp.Account.Id,
-		Name: resp.Account.Name,
-	}, nil
+	return accountFromProto(resp.Account), nil
 }
 
 func (c *Client) GetAccounts(ctx context.Context, skip uint64, take uint64) ([]*Account, error) {
@@ -59,10 +55,119 @@ func (c *Client) GetAccounts(ctx context.Context, skip uint64, take uint64) ([]*
 	}
 	accounts := make([]*Account, len(resp.Accounts))
 	for i, acc := range resp.Accounts {
-		accounts[i] = &Account{
-			ID:   acc.Id,
-			Name: acc.Name,
-		}
+		accounts[i] = accountFromProto(acc)
 	}
 	return accounts, nil
 }
+
+// UpdateAccount changes the name and/or email of an account. Nil values are
+// left as they are.
+func (c *Client) UpdateAccount(ctx context.Context, id string, name, email *string) (*Account, error) {
+	req := &pb.UpdateAccountRequest{Id: id, UpdateMask: &fieldmaskpb.FieldMask{}}
+	if name != nil {
+		req.Name = *name
+		req.UpdateMask.Paths = append(req.UpdateMask.Paths, "name")
+	}
+	if email != nil {
+		req.Email = *email
+		req.UpdateMask.Paths = append(req.UpdateMask.Paths, "email")
+	}
+	resp, err := c.service.UpdateAccount(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return accountFromProto(resp.Account), nil
+}
+
+func (c *Client) DeactivateAccount(ctx context.Context, id string) (*Account, error) {
+	req := &pb.DeactivateAccountRequest{Id: id}
+	resp, err := c.service.DeactivateAccount(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return accountFromProto(resp.Account), nil
+}
+
+func (c *Client) EraseAccount(ctx context.Context, id string) (*Account, error) {
+	req := &pb.EraseAccountRequest{Id: id}
+	resp, err := c.service.EraseAccount(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return accountFromProto(resp.Account), nil
+}
+
+func (c *Client) Register(ctx context.Context, name, email, password string) (*Account, *Tokens, error) {
+	req := &pb.RegisterRequest{Name: name, Email: email, Password: password}
+	resp, err := c.service.Register(ctx, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	return accountFromProto(resp.Account), tokensFromProto(resp.Tokens), nil
+}
+
+func (c *Client) Login(ctx context.Context, email, password string) (*Account, *Tokens, error) {
+	req := &pb.LoginRequest{Email: email, Password: password}
+	resp, err := c.service.Login(ctx, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	return accountFromProto(resp.Account), tokensFromProto(resp.Tokens), nil
+}
+
+func (c *Client) RefreshToken(ctx context.Context, refreshToken string) (*Tokens, error) {
+	req := &pb.RefreshTokenRequest{RefreshToken: refreshToken}
+	resp, err := c.service.RefreshToken(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return tokensFromProto(resp.Tokens), nil
+}
+
+func (c *Client) GrantRole(ctx context.Context, accountID, role string) (*Account, error) {
+	req := &pb.GrantRoleRequest{AccountId: accountID, Role: role}
+	resp, err := c.service.GrantRole(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return accountFromProto(resp.Account), nil
+}
+
+func (c *Client) RevokeRole(ctx context.Context, accountID, role string) (*Account, error) {
+	req := &pb.RevokeRoleRequest{AccountId: accountID, Role: role}
+	resp, err := c.service.RevokeRole(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return accountFromProto(resp.Account), nil
+}
+
+func (c *Client) CheckPermission(ctx context.Context, accountID, permission string) (bool, error) {
+	req := &pb.CheckPermissionRequest{AccountId: accountID, Permission: permission}
+	resp, err := c.service.CheckPermission(ctx, req)
+	if err != nil {
+		return false, err
+	}
+	return resp.Allowed, nil
+}
+
+func accountFromProto(a *pb.Account) *Account {
+	return &Account{
+		ID:     a.Id,
+		Name:   a.Name,
+		Roles:  a.Roles,
+		Status: a.Status,
+	}
+}
+
+func tokensFromProto(tp *pb.Tokens) *Tokens {
+	t := &Tokens{
+		AccessToken:  tp.AccessToken,
+		RefreshToken: tp.RefreshToken,
+	}
+	t.AccessTokenExpiresAt = time.Time{}
+	t.AccessTokenExpiresAt.UnmarshalBinary(tp.AccessTokenExpiresAt)
+	t.RefreshTokenExpiresAt = time.Time{}
+	t.RefreshTokenExpiresAt.UnmarshalBinary(tp.RefreshTokenExpiresAt)
+	return t
+}
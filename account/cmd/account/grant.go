@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"github.com/suryanshp1/go-microservice/account"
+)
+
+// grant gives the account registered with an email a role, without a
+// caller holding role:manage. It is how the first admin comes about, and
+// how service accounts get their roles.
+func grant(r account.Repository, s account.Service, args []string) {
+	if len(args) != 2 {
+		log.Fatal("usage: account grant <email> <role>")
+	}
+	email, role := args[0], args[1]
+
+	ctx := context.Background()
+	c, err := r.GetCredentialsByEmail(ctx, strings.ToLower(strings.TrimSpace(email)))
+	if err != nil {
+		log.Fatalf("Error looking up %s: %v", email, err)
+	}
+	if c == nil {
+		log.Fatalf("No account is registered with %s", email)
+	}
+	a, err := s.GrantRole(ctx, c.AccountID, role, "")
+	if err != nil {
+		log.Fatalf("Error granting %s to %s: %v", role, email, err)
+	}
+	log.Printf("Account %s now has the roles %s", strings.TrimSpace(a.ID), strings.Join(a.Roles, ", "))
+}
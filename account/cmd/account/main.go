@@ -1,7 +1,9 @@
 package main
 
 import (
+	"crypto/ed25519"
 	"log"
+	"os"
 	"time"
 
 	"github.com/kelseyhightower/envconfig"
@@ -10,7 +12,8 @@ import (
 )
 
 type config struct {
-	DatabaseURL string `envconfig:"DATABASE_URL"`
+	DatabaseURL     string `envconfig:"DATABASE_URL"`
+	TokenPrivateKey string `envconfig:"TOKEN_PRIVATE_KEY"`
 }
 
 func main() {
@@ -20,6 +23,11 @@ func main() {
 		log.Fatal(err)
 	}
 
+	key, err := account.ParsePrivateKey(cfg.TokenPrivateKey)
+	if err != nil {
+		log.Fatalf("invalid TOKEN_PRIVATE_KEY: %v", err)
+	}
+
 	var r account.Repository
 	retry.ForeverSleep(2*time.Second, func(_ int) (err error) {
 		r, err = account.NewPostgresRepository(cfg.DatabaseURL)
@@ -29,8 +37,19 @@ func main() {
 		return
 	})
 	defer r.Close()
-	log.Println("Listening on Port 8080")
 
-	s := account.NewService(r)
-	log.Fatal(account.ListenGRPC(s, 8080))
+	s := account.NewService(r, account.NewTokenIssuer(key))
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "grant":
+			grant(r, s, os.Args[2:])
+		default:
+			log.Fatalf("unknown command %q, expected grant", os.Args[1])
+		}
+		return
+	}
+
+	log.Println("Listening on Port 8080")
+	verifier := account.NewTokenVerifier(key.Public().(ed25519.PublicKey))
+	log.Fatal(account.ListenGRPC(s, verifier, 8080))
 }
@@ -3,8 +3,9 @@ package account
 import (
 	"context"
 	"database/sql"
+	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 type Repository interface {
@@ -12,6 +13,16 @@ type Repository interface {
 	PutAccount(ctx context.Context, account Account) error
 	GetAccountByID(ctx context.Context, id string) (*Account, error)
 	ListAccounts(ctx context.Context, skip uint64, take uint64) ([]Account, error)
+	UpdateAccount(ctx context.Context, id string, name, email *string) error
+	DeactivateAccount(ctx context.Context, id string, deactivatedAt time.Time) error
+	EraseAccount(ctx context.Context, id, erasedName string, erasedAt time.Time) error
+	PutAccountWithCredentials(ctx context.Context, account Account, credentials Credentials) error
+	GetCredentialsByEmail(ctx context.Context, email string) (*Credentials, error)
+	PutRefreshToken(ctx context.Context, token RefreshToken) error
+	RevokeRefreshToken(ctx context.Context, tokenHash string, revokedAt time.Time) (*RefreshToken, error)
+	PutAccountRole(ctx context.Context, accountID, role, grantedBy string) error
+	DeleteAccountRole(ctx context.Context, accountID, role string) error
+	HasPermission(ctx context.Context, accountID, permission string) (bool, error)
 }
 
 type postgresRepository struct {
@@ -52,13 +63,14 @@ func (r *postgresRepository) PutAccount(ctx context.Context, account Account) er
 
 func (r *postgresRepository) GetAccountByID(ctx context.Context, id string) (*Account, error) {
 	row := r.db.QueryRowContext(ctx, `
-		SELECT id, name
-		FROM accounts
-		WHERE id = $1
+		SELECT TRIM(a.id), a.name, a.status, COALESCE(array_agg(ar.role ORDER BY ar.role) FILTER (WHERE ar.role IS NOT NULL), '{}')
+		FROM accounts a LEFT JOIN account_roles ar ON (a.id = ar.account_id)
+		WHERE a.id = $1
+		GROUP BY a.id
 	`, id)
 
 	account := &Account{}
-	err := row.Scan(&account.ID, &account.Name)
+	err := row.Scan(&account.ID, &account.Name, &account.Status, pq.Array(&account.Roles))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -71,9 +83,10 @@ func (r *postgresRepository) GetAccountByID(ctx context.Context, id string) (*Ac
 
 func (r *postgresRepository) ListAccounts(ctx context.Context, skip uint64, take uint64) ([]Account, error) {
 	rows, err := r.db.QueryContext(ctx, `
-		SELECT id, name
-		FROM accounts
-		ORDER BY id DESC
+		SELECT TRIM(a.id), a.name, a.status, COALESCE(array_agg(ar.role ORDER BY ar.role) FILTER (WHERE ar.role IS NOT NULL), '{}')
+		FROM accounts a LEFT JOIN account_roles ar ON (a.id = ar.account_id)
+		GROUP BY a.id
+		ORDER BY a.id DESC
 		OFFSET $1 LIMIT $2
 	`, skip, take)
 	if err != nil {
@@ -84,7 +97,7 @@ func (r *postgresRepository) ListAccounts(ctx context.Context, skip uint64, take
 	accounts := []Account{}
 	for rows.Next() {
 		account := &Account{}
-		err := rows.Scan(&account.ID, &account.Name)
+		err := rows.Scan(&account.ID, &account.Name, &account.Status, pq.Array(&account.Roles))
 		if err != nil {
 			return nil, err
 		}
@@ -97,3 +110,286 @@ func (r *postgresRepository) ListAccounts(ctx context.Context, skip uint64, take
 
 	return accounts, nil
 }
+
+func (r *postgresRepository) UpdateAccount(ctx context.Context, id string, name, email *string) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	if name != nil {
+		var res sql.Result
+		res, err = tx.ExecContext(ctx, `
+			UPDATE accounts SET name = $2
+			WHERE id = $1
+		`, id, *name)
+		if err != nil {
+			return
+		}
+		var n int64
+		if n, err = res.RowsAffected(); err != nil {
+			return
+		}
+		if n == 0 {
+			err = ErrAccountNotFound
+			return
+		}
+	}
+
+	if email != nil {
+		var res sql.Result
+		res, err = tx.ExecContext(ctx, `
+			UPDATE account_credentials SET email = $2
+			WHERE account_id = $1
+		`, id, *email)
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			err = ErrEmailTaken
+		}
+		if err != nil {
+			return
+		}
+		var n int64
+		if n, err = res.RowsAffected(); err != nil {
+			return
+		}
+		// Accounts created without registering have no credentials.
+		if n == 0 {
+			err = ErrNoCredentials
+		}
+	}
+	return
+}
+
+// DeactivateAccount marks an active account as deactivated and revokes its
+// refresh tokens. Deactivating an account that is not active does nothing.
+func (r *postgresRepository) DeactivateAccount(ctx context.Context, id string, deactivatedAt time.Time) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	var exists bool
+	err = tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM accounts WHERE id = $1)`, id).Scan(&exists)
+	if err != nil {
+		return
+	}
+	if !exists {
+		err = ErrAccountNotFound
+		return
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE accounts SET status = 'deactivated', deactivated_at = $2
+		WHERE id = $1 AND status = 'active'
+	`, id, deactivatedAt)
+	if err != nil {
+		return
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE refresh_tokens SET revoked_at = $2
+		WHERE account_id = $1 AND revoked_at IS NULL
+	`, id, deactivatedAt)
+	return
+}
+
+// EraseAccount anonymizes an account in place. Everything that identifies
+// the person behind it is deleted or overwritten, but the row and its ID are
+// kept.
+func (r *postgresRepository) EraseAccount(ctx context.Context, id, erasedName string, erasedAt time.Time) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE accounts
+		SET name = $2, status = 'erased', erased_at = $3,
+			deactivated_at = COALESCE(deactivated_at, $3)
+		WHERE id = $1
+	`, id, erasedName, erasedAt)
+	if err != nil {
+		return
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return
+	}
+	if n == 0 {
+		err = ErrAccountNotFound
+		return
+	}
+
+	for _, query := range []string{
+		`DELETE FROM account_credentials WHERE account_id = $1`,
+		`DELETE FROM refresh_tokens WHERE account_id = $1`,
+		`DELETE FROM account_roles WHERE account_id = $1`,
+		`UPDATE account_roles SET granted_by = NULL WHERE granted_by = $1`,
+	} {
+		if _, err = tx.ExecContext(ctx, query, id); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (r *postgresRepository) PutAccountWithCredentials(ctx context.Context, account Account, credentials Credentials) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO accounts (id, name)
+		VALUES ($1, $2)
+	`, account.ID, account.Name)
+	if err != nil {
+		return
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO account_credentials (account_id, email, password_hash)
+		VALUES ($1, $2, $3)
+	`, credentials.AccountID, credentials.Email, credentials.PasswordHash)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		err = ErrEmailTaken
+	}
+	if err != nil {
+		return
+	}
+
+	for _, role := range account.Roles {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO account_roles (account_id, role)
+			VALUES ($1, $2)
+		`, account.ID, role)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (r *postgresRepository) GetCredentialsByEmail(ctx context.Context, email string) (*Credentials, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT account_id, email, password_hash
+		FROM account_credentials
+		WHERE email = $1
+	`, email)
+
+	credentials := &Credentials{}
+	err := row.Scan(&credentials.AccountID, &credentials.Email, &credentials.PasswordHash)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return credentials, nil
+}
+
+func (r *postgresRepository) PutRefreshToken(ctx context.Context, token RefreshToken) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO refresh_tokens (token_hash, account_id, expires_at)
+		VALUES ($1, $2, $3)
+	`, token.TokenHash, token.AccountID, token.ExpiresAt)
+	return err
+}
+
+// RevokeRefreshToken marks a token as used and returns it. It returns nil if
+// the token is unknown or was already revoked, so a token can be exchanged
+// at most once even under concurrent requests.
+func (r *postgresRepository) RevokeRefreshToken(ctx context.Context, tokenHash string, revokedAt time.Time) (*RefreshToken, error) {
+	row := r.db.QueryRowContext(ctx, `
+		UPDATE refresh_tokens
+		SET revoked_at = $2
+		WHERE token_hash = $1 AND revoked_at IS NULL
+		RETURNING token_hash, account_id, expires_at, revoked_at
+	`, tokenHash, revokedAt)
+
+	token := &RefreshToken{}
+	err := row.Scan(&token.TokenHash, &token.AccountID, &token.ExpiresAt, &token.RevokedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+func (r *postgresRepository) PutAccountRole(ctx context.Context, accountID, role, grantedBy string) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO account_roles (account_id, role, granted_by)
+		VALUES ($1, $2, NULLIF($3, ''))
+		ON CONFLICT (account_id, role) DO NOTHING
+	`, accountID, role, grantedBy)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+		switch pqErr.Constraint {
+		case "account_roles_role_fkey":
+			return ErrUnknownRole
+		case "account_roles_account_id_fkey":
+			return ErrAccountNotFound
+		}
+	}
+	return err
+}
+
+func (r *postgresRepository) DeleteAccountRole(ctx context.Context, accountID, role string) error {
+	_, err := r.db.ExecContext(ctx, `
+		DELETE FROM account_roles
+		WHERE account_id = $1 AND role = $2
+	`, accountID, role)
+	return err
+}
+
+// HasPermission reports whether one of the account's roles grants
+// permission. Accounts that are no longer active have no permissions, even
+// though deactivation keeps their roles.
+func (r *postgresRepository) HasPermission(ctx context.Context, accountID, permission string) (bool, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM accounts a
+			JOIN account_roles ar ON (a.id = ar.account_id)
+			JOIN role_permissions rp ON (ar.role = rp.role)
+			WHERE a.id = $1 AND a.status = 'active' AND rp.permission = $2
+		)
+	`, accountID, permission)
+
+	var allowed bool
+	if err := row.Scan(&allowed); err != nil {
+		return false, err
+	}
+	return allowed, nil
+}
@@ -5,10 +5,13 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"strings"
 
 	"github.com/suryanshp1/go-microservice/account/pb"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 )
 
 type grpcServer struct {
@@ -16,12 +19,12 @@ type grpcServer struct {
 	service Service
 }
 
-func ListenGRPC(s Service, port int) error {
+func ListenGRPC(s Service, verifier *TokenVerifier, port int) error {
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
 	if err != nil {
 		panic(err)
 	}
-	serv := grpc.NewServer()
+	serv := grpc.NewServer(grpc.UnaryInterceptor(UnaryServerInterceptor(verifier)))
 	pb.RegisterAccountServiceServer(serv, &grpcServer{service: s})
 	reflection.Register(serv)
 	return serv.Serve(lis)
@@ -32,15 +35,15 @@ func (s *grpcServer) PostAccount(ctx context.Context, req *pb.PostAccountRequest
 	if err != nil {
 		return nil, err
 	}
-	return &pb.PostAccountResponse{Account: &pb.Account{Id: a.ID, Name: a.Name}}, nil
+	return &pb.PostAccountResponse{Account: accountToProto(a)}, nil
 }
 
 func (s *grpcServer) GetAccount(ctx context.Context, req *pb.GetAccountRequest) (*pb.GetAccountResponse, error) {
 	a, err := s.service.GetAccount(ctx, req.Id)
 	if err != nil {
-		return nil, err
+		return nil, toStatus(err)
 	}
-	return &pb.GetAccountResponse{Account: &pb.Account{Id: a.ID, Name: a.Name}}, nil
+	return &pb.GetAccountResponse{Account: accountToProto(a)}, nil
 }
 
 func (s *grpcServer) GetAccounts(ctx context.Context, req *pb.GetAccountsRequest) (*pb.GetAccountsResponse, error) {
@@ -50,7 +53,173 @@ func (s *grpcServer) GetAccounts(ctx context.Context, req *pb.GetAccountsRequest
 	}
 	pbAccounts := []*pb.Account{}
 	for _, a := range accounts {
-		pbAccounts = append(pbAccounts, &pb.Account{Id: a.ID, Name: a.Name})
+		pbAccounts = append(pbAccounts, accountToProto(&a))
 	}
 	return &pb.GetAccountsResponse{Accounts: pbAccounts}, nil
 }
+
+func (s *grpcServer) UpdateAccount(ctx context.Context, req *pb.UpdateAccountRequest) (*pb.UpdateAccountResponse, error) {
+	if err := s.authorizeAccount(ctx, req.Id); err != nil {
+		return nil, err
+	}
+
+	// Without a mask every field is replaced.
+	paths := []string{"name", "email"}
+	if req.UpdateMask != nil {
+		paths = req.UpdateMask.Paths
+	}
+	var name, email *string
+	for _, path := range paths {
+		switch path {
+		case "name":
+			name = &req.Name
+		case "email":
+			email = &req.Email
+		default:
+			return nil, status.Errorf(codes.InvalidArgument, "field %q cannot be updated", path)
+		}
+	}
+
+	a, err := s.service.UpdateAccount(ctx, req.Id, name, email)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.UpdateAccountResponse{Account: accountToProto(a)}, nil
+}
+
+func (s *grpcServer) DeactivateAccount(ctx context.Context, req *pb.DeactivateAccountRequest) (*pb.DeactivateAccountResponse, error) {
+	if err := s.authorizeAccount(ctx, req.Id); err != nil {
+		return nil, err
+	}
+	a, err := s.service.DeactivateAccount(ctx, req.Id)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.DeactivateAccountResponse{Account: accountToProto(a)}, nil
+}
+
+func (s *grpcServer) EraseAccount(ctx context.Context, req *pb.EraseAccountRequest) (*pb.EraseAccountResponse, error) {
+	if err := s.authorizeAccount(ctx, req.Id); err != nil {
+		return nil, err
+	}
+	a, err := s.service.EraseAccount(ctx, req.Id)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.EraseAccountResponse{Account: accountToProto(a)}, nil
+}
+
+func (s *grpcServer) Register(ctx context.Context, req *pb.RegisterRequest) (*pb.RegisterResponse, error) {
+	a, tokens, err := s.service.Register(ctx, req.Name, req.Email, req.Password)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.RegisterResponse{
+		Account: accountToProto(a),
+		Tokens:  tokensToProto(tokens),
+	}, nil
+}
+
+func (s *grpcServer) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
+	a, tokens, err := s.service.Login(ctx, req.Email, req.Password)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.LoginResponse{
+		Account: accountToProto(a),
+		Tokens:  tokensToProto(tokens),
+	}, nil
+}
+
+func (s *grpcServer) RefreshToken(ctx context.Context, req *pb.RefreshTokenRequest) (*pb.RefreshTokenResponse, error) {
+	tokens, err := s.service.RefreshToken(ctx, req.RefreshToken)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.RefreshTokenResponse{Tokens: tokensToProto(tokens)}, nil
+}
+
+func (s *grpcServer) GrantRole(ctx context.Context, req *pb.GrantRoleRequest) (*pb.GrantRoleResponse, error) {
+	if err := s.authorize(ctx, PermissionRoleManage); err != nil {
+		return nil, err
+	}
+	a, err := s.service.GrantRole(ctx, req.AccountId, req.Role, ClaimsFromContext(ctx).AccountID())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.GrantRoleResponse{Account: accountToProto(a)}, nil
+}
+
+func (s *grpcServer) RevokeRole(ctx context.Context, req *pb.RevokeRoleRequest) (*pb.RevokeRoleResponse, error) {
+	if err := s.authorize(ctx, PermissionRoleManage); err != nil {
+		return nil, err
+	}
+	a, err := s.service.RevokeRole(ctx, req.AccountId, req.Role)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.RevokeRoleResponse{Account: accountToProto(a)}, nil
+}
+
+// CheckPermission lets callers check their own permissions, and holders of
+// account:read:any check those of any account.
+func (s *grpcServer) CheckPermission(ctx context.Context, req *pb.CheckPermissionRequest) (*pb.CheckPermissionResponse, error) {
+	claims := ClaimsFromContext(ctx)
+	if claims == nil {
+		return nil, status.Error(codes.Unauthenticated, "authentication required")
+	}
+	if strings.TrimSpace(req.AccountId) != claims.AccountID() {
+		if err := s.authorize(ctx, PermissionAccountReadAny); err != nil {
+			return nil, err
+		}
+	}
+	allowed, err := s.service.CheckPermission(ctx, req.AccountId, req.Permission)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.CheckPermissionResponse{Allowed: allowed}, nil
+}
+
+func (s *grpcServer) authorize(ctx context.Context, permission string) error {
+	return authorize(ctx, s.service, permission)
+}
+
+// authorizeAccount lets callers manage their own account, and holders of
+// account:write:any manage any account.
+func (s *grpcServer) authorizeAccount(ctx context.Context, accountID string) error {
+	claims := ClaimsFromContext(ctx)
+	if claims != nil && claims.AccountID() == strings.TrimSpace(accountID) {
+		return nil
+	}
+	return s.authorize(ctx, PermissionAccountWriteAny)
+}
+
+func accountToProto(a *Account) *pb.Account {
+	return &pb.Account{Id: a.ID, Name: a.Name, Roles: a.Roles, Status: a.Status}
+}
+
+func tokensToProto(t *Tokens) *pb.Tokens {
+	tp := &pb.Tokens{
+		AccessToken:  t.AccessToken,
+		RefreshToken: t.RefreshToken,
+	}
+	tp.AccessTokenExpiresAt, _ = t.AccessTokenExpiresAt.MarshalBinary()
+	tp.RefreshTokenExpiresAt, _ = t.RefreshTokenExpiresAt.MarshalBinary()
+	return tp
+}
+
+func toStatus(err error) error {
+	switch err {
+	case ErrInvalidCredentials, ErrInvalidToken:
+		return status.Error(codes.Unauthenticated, err.Error())
+	case ErrEmailTaken:
+		return status.Error(codes.AlreadyExists, err.Error())
+	case ErrAccountNotFound:
+		return status.Error(codes.NotFound, err.Error())
+	case ErrWeakPassword, ErrUnknownRole, ErrInvalidAccount:
+		return status.Error(codes.InvalidArgument, err.Error())
+	case ErrAccountDeactivated, ErrNoCredentials:
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return err
+}
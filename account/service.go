@@ -2,33 +2,96 @@ package account
 
 import (
 	"context"
+	"errors"
+	"strings"
+	"time"
 
 	"github.com/segmentio/ksuid"
+	"golang.org/x/crypto/bcrypt"
 )
 
+var (
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrEmailTaken         = errors.New("email is already registered")
+	ErrWeakPassword       = errors.New("password must be between 8 and 72 bytes long")
+	ErrAccountNotFound    = errors.New("account not found")
+	ErrUnknownRole        = errors.New("unknown role")
+	ErrAccountDeactivated = errors.New("account is deactivated")
+	ErrInvalidAccount     = errors.New("account must have a name and a valid email")
+	ErrNoCredentials      = errors.New("account has no email to change")
+)
+
+const (
+	AccountActive      = "active"
+	AccountDeactivated = "deactivated"
+	AccountErased      = "erased"
+)
+
+// erasedName replaces the name of erased accounts.
+const erasedName = "Erased account"
+
 type Service interface {
 	PostAccount(ctx context.Context, name string) (*Account, error)
 	GetAccount(ctx context.Context, id string) (*Account, error)
 	GetAccounts(ctx context.Context, skip uint64, take uint64) ([]Account, error)
+	UpdateAccount(ctx context.Context, id string, name, email *string) (*Account, error)
+	DeactivateAccount(ctx context.Context, id string) (*Account, error)
+	EraseAccount(ctx context.Context, id string) (*Account, error)
+	Register(ctx context.Context, name, email, password string) (*Account, *Tokens, error)
+	Login(ctx context.Context, email, password string) (*Account, *Tokens, error)
+	RefreshToken(ctx context.Context, refreshToken string) (*Tokens, error)
+	GrantRole(ctx context.Context, accountID, role, grantedBy string) (*Account, error)
+	RevokeRole(ctx context.Context, accountID, role string) (*Account, error)
+	CheckPermission(ctx context.Context, accountID, permission string) (bool, error)
 }
 
 type Account struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
+	ID     string   `json:"id"`
+	Name   string   `json:"name"`
+	Roles  []string `json:"roles"`
+	Status string   `json:"status"`
+}
+
+// IsActive reports whether the account may sign in and place orders.
+// Accounts created before statuses existed count as active.
+func (a *Account) IsActive() bool {
+	return a.Status == AccountActive || a.Status == ""
+}
+
+type Credentials struct {
+	AccountID    string
+	Email        string
+	PasswordHash string
+}
+
+type RefreshToken struct {
+	TokenHash string
+	AccountID string
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+}
+
+type Tokens struct {
+	AccessToken           string
+	AccessTokenExpiresAt  time.Time
+	RefreshToken          string
+	RefreshTokenExpiresAt time.Time
 }
 
 type accountService struct {
 	repository Repository
+	issuer     *TokenIssuer
 }
 
-func NewService(repository Repository) Service {
-	return &accountService{repository}
+func NewService(repository Repository, issuer *TokenIssuer) Service {
+	return &accountService{repository, issuer}
 }
 
 func (s *accountService) PostAccount(ctx context.Context, name string) (*Account, error) {
 	a := &Account{
-		Name: name,
-		ID:   ksuid.New().String(),
+		Name:   name,
+		ID:     ksuid.New().String(),
+		Status: AccountActive,
 	}
 	if err := s.repository.PutAccount(ctx, *a); err != nil {
 		return nil, err
@@ -37,7 +100,14 @@ func (s *accountService) PostAccount(ctx context.Context, name string) (*Account
 }
 
 func (s *accountService) GetAccount(ctx context.Context, id string) (*Account, error) {
-	return s.repository.GetAccountByID(ctx, id)
+	a, err := s.repository.GetAccountByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if a == nil {
+		return nil, ErrAccountNotFound
+	}
+	return a, nil
 }
 
 func (s *accountService) GetAccounts(ctx context.Context, skip uint64, take uint64) ([]Account, error) {
@@ -46,3 +116,203 @@ func (s *accountService) GetAccounts(ctx context.Context, skip uint64, take uint
 	}
 	return s.repository.ListAccounts(ctx, skip, take)
 }
+
+// UpdateAccount changes the name and/or email of an active account. Nil
+// values are left as they are.
+func (s *accountService) UpdateAccount(ctx context.Context, id string, name, email *string) (*Account, error) {
+	if name != nil && strings.TrimSpace(*name) == "" {
+		return nil, ErrInvalidAccount
+	}
+	if email != nil {
+		normalized := normalizeEmail(*email)
+		if !strings.Contains(normalized, "@") {
+			return nil, ErrInvalidAccount
+		}
+		email = &normalized
+	}
+
+	a, err := s.GetAccount(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !a.IsActive() {
+		return nil, ErrAccountDeactivated
+	}
+	if err := s.repository.UpdateAccount(ctx, id, name, email); err != nil {
+		return nil, err
+	}
+	return s.GetAccount(ctx, id)
+}
+
+// DeactivateAccount stops an account from signing in and placing orders.
+// Its refresh tokens are revoked. Access tokens already issued stay valid
+// until they expire, but permission checks fail for the account right away.
+func (s *accountService) DeactivateAccount(ctx context.Context, id string) (*Account, error) {
+	if err := s.repository.DeactivateAccount(ctx, id, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	return s.GetAccount(ctx, id)
+}
+
+// EraseAccount removes the personal data of an account: its name is
+// replaced, and its credentials, tokens and roles are deleted. The account
+// row itself stays, so orders placed by it still refer to an existing
+// account.
+func (s *accountService) EraseAccount(ctx context.Context, id string) (*Account, error) {
+	if err := s.repository.EraseAccount(ctx, id, erasedName, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	return s.GetAccount(ctx, id)
+}
+
+// Register creates an account that signs in with email and password. Since
+// bcrypt only looks at the first 72 bytes, longer passwords are refused.
+func (s *accountService) Register(ctx context.Context, name, email, password string) (*Account, *Tokens, error) {
+	email = normalizeEmail(email)
+	if strings.TrimSpace(name) == "" || !strings.Contains(email, "@") {
+		return nil, nil, ErrInvalidAccount
+	}
+	if len(password) < 8 || len(password) > 72 {
+		return nil, nil, ErrWeakPassword
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	a := &Account{
+		Name:   name,
+		ID:     ksuid.New().String(),
+		Roles:  []string{RoleCustomer},
+		Status: AccountActive,
+	}
+	c := Credentials{
+		AccountID:    a.ID,
+		Email:        email,
+		PasswordHash: string(hash),
+	}
+	if err := s.repository.PutAccountWithCredentials(ctx, *a, c); err != nil {
+		return nil, nil, err
+	}
+
+	tokens, err := s.issueTokens(ctx, *a)
+	if err != nil {
+		return nil, nil, err
+	}
+	return a, tokens, nil
+}
+
+func (s *accountService) Login(ctx context.Context, email, password string) (*Account, *Tokens, error) {
+	c, err := s.repository.GetCredentialsByEmail(ctx, normalizeEmail(email))
+	if err != nil {
+		return nil, nil, err
+	}
+	if c == nil {
+		return nil, nil, ErrInvalidCredentials
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(c.PasswordHash), []byte(password)); err != nil {
+		return nil, nil, ErrInvalidCredentials
+	}
+
+	a, err := s.repository.GetAccountByID(ctx, c.AccountID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if a == nil {
+		return nil, nil, ErrInvalidCredentials
+	}
+	if !a.IsActive() {
+		return nil, nil, ErrAccountDeactivated
+	}
+
+	tokens, err := s.issueTokens(ctx, *a)
+	if err != nil {
+		return nil, nil, err
+	}
+	return a, tokens, nil
+}
+
+func (s *accountService) RefreshToken(ctx context.Context, refreshToken string) (*Tokens, error) {
+	// Refresh tokens are single use: the presented token is revoked and a
+	// new pair is issued in its place.
+	rt, err := s.repository.RevokeRefreshToken(ctx, hashRefreshToken(refreshToken), time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	if rt == nil || rt.ExpiresAt.Before(time.Now()) {
+		return nil, ErrInvalidToken
+	}
+
+	a, err := s.repository.GetAccountByID(ctx, rt.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if a == nil || !a.IsActive() {
+		return nil, ErrInvalidToken
+	}
+	return s.issueTokens(ctx, *a)
+}
+
+func (s *accountService) GrantRole(ctx context.Context, accountID, role, grantedBy string) (*Account, error) {
+	if err := s.repository.PutAccountRole(ctx, accountID, role, grantedBy); err != nil {
+		return nil, err
+	}
+	a, err := s.repository.GetAccountByID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if a == nil {
+		return nil, ErrAccountNotFound
+	}
+	return a, nil
+}
+
+func (s *accountService) RevokeRole(ctx context.Context, accountID, role string) (*Account, error) {
+	if err := s.repository.DeleteAccountRole(ctx, accountID, role); err != nil {
+		return nil, err
+	}
+	a, err := s.repository.GetAccountByID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if a == nil {
+		return nil, ErrAccountNotFound
+	}
+	return a, nil
+}
+
+func (s *accountService) CheckPermission(ctx context.Context, accountID, permission string) (bool, error) {
+	return s.repository.HasPermission(ctx, accountID, permission)
+}
+
+func (s *accountService) issueTokens(ctx context.Context, a Account) (*Tokens, error) {
+	now := time.Now().UTC()
+	accessToken, accessExpiresAt, err := s.issuer.Issue(a, now)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, refreshHash, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	rt := RefreshToken{
+		TokenHash: refreshHash,
+		AccountID: a.ID,
+		ExpiresAt: now.Add(RefreshTokenTTL),
+	}
+	if err := s.repository.PutRefreshToken(ctx, rt); err != nil {
+		return nil, err
+	}
+
+	return &Tokens{
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessExpiresAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: rt.ExpiresAt,
+	}, nil
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
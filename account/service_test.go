@@ -0,0 +1,209 @@
+package account
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeRepository keeps accounts, credentials and refresh tokens in memory.
+type fakeRepository struct {
+	Repository
+	accounts    map[string]Account
+	credentials map[string]Credentials
+	tokens      map[string]RefreshToken
+	vanishing   bool
+}
+
+func newFakeRepository() *fakeRepository {
+	return &fakeRepository{
+		accounts:    map[string]Account{},
+		credentials: map[string]Credentials{},
+		tokens:      map[string]RefreshToken{},
+	}
+}
+
+func (r *fakeRepository) PutAccountWithCredentials(ctx context.Context, a Account, c Credentials) error {
+	if _, ok := r.credentials[c.Email]; ok {
+		return ErrEmailTaken
+	}
+	r.accounts[a.ID] = a
+	r.credentials[c.Email] = c
+	return nil
+}
+
+func (r *fakeRepository) PutRefreshToken(ctx context.Context, token RefreshToken) error {
+	r.tokens[token.TokenHash] = token
+	return nil
+}
+
+func (r *fakeRepository) RevokeRefreshToken(ctx context.Context, tokenHash string, revokedAt time.Time) (*RefreshToken, error) {
+	token, ok := r.tokens[tokenHash]
+	if !ok || token.RevokedAt != nil {
+		return nil, nil
+	}
+	token.RevokedAt = &revokedAt
+	r.tokens[tokenHash] = token
+	return &token, nil
+}
+
+func (r *fakeRepository) PutAccountRole(ctx context.Context, accountID, role, grantedBy string) error {
+	a, ok := r.accounts[accountID]
+	if !ok {
+		return ErrAccountNotFound
+	}
+	a.Roles = append(a.Roles, role)
+	r.accounts[accountID] = a
+	return nil
+}
+
+// GetAccountByID finds no account once vanishing is set, as if the account
+// was deleted after a role was granted.
+func (r *fakeRepository) GetAccountByID(ctx context.Context, id string) (*Account, error) {
+	a, ok := r.accounts[id]
+	if !ok || r.vanishing {
+		return nil, nil
+	}
+	return &a, nil
+}
+
+func newTestService(t *testing.T) (Service, *fakeRepository) {
+	_, private := newTestKeys(t)
+	repository := newFakeRepository()
+	return NewService(repository, NewTokenIssuer(private)), repository
+}
+
+func TestRegisterValidates(t *testing.T) {
+	tests := []struct {
+		name     string
+		email    string
+		password string
+		err      error
+	}{
+		{"Ada", "ada@example.com", "correct horse", nil},
+		{"Ada", " Ada@Example.com ", "correct horse", nil},
+		{"Ada", "ada@example.com", strings.Repeat("x", 72), nil},
+		{"", "ada@example.com", "correct horse", ErrInvalidAccount},
+		{"   ", "ada@example.com", "correct horse", ErrInvalidAccount},
+		{"Ada", "", "correct horse", ErrInvalidAccount},
+		{"Ada", "ada.example.com", "correct horse", ErrInvalidAccount},
+		{"Ada", "ada@example.com", "", ErrWeakPassword},
+		{"Ada", "ada@example.com", "1234567", ErrWeakPassword},
+		{"Ada", "ada@example.com", strings.Repeat("x", 73), ErrWeakPassword},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name+"/"+tt.email+"/"+tt.password, func(t *testing.T) {
+			s, _ := newTestService(t)
+			a, tokens, err := s.Register(context.Background(), tt.name, tt.email, tt.password)
+			if err != tt.err {
+				t.Fatalf("Register() error = %v, want %v", err, tt.err)
+			}
+			if err == nil && (a == nil || tokens == nil || tokens.RefreshToken == "") {
+				t.Errorf("Register() = %v, %v, want an account and tokens", a, tokens)
+			}
+		})
+	}
+}
+
+func TestRegisterTakenEmail(t *testing.T) {
+	s, _ := newTestService(t)
+	if _, _, err := s.Register(context.Background(), "Ada", "ada@example.com", "correct horse"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.Register(context.Background(), "Ada", "ADA@example.com", "correct horse"); err != ErrEmailTaken {
+		t.Errorf("Register() error = %v, want %v", err, ErrEmailTaken)
+	}
+}
+
+func TestRefreshTokenRotates(t *testing.T) {
+	s, _ := newTestService(t)
+	_, tokens, err := s.Register(context.Background(), "Ada", "ada@example.com", "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotated, err := s.RefreshToken(context.Background(), tokens.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotated.RefreshToken == tokens.RefreshToken {
+		t.Errorf("refresh token was not rotated")
+	}
+	if _, err := s.RefreshToken(context.Background(), tokens.RefreshToken); err != ErrInvalidToken {
+		t.Errorf("reusing a refresh token: error = %v, want %v", err, ErrInvalidToken)
+	}
+	if _, err := s.RefreshToken(context.Background(), rotated.RefreshToken); err != nil {
+		t.Errorf("using the rotated refresh token: %v", err)
+	}
+}
+
+func TestRefreshTokenRejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(r *fakeRepository, a *Account, hash string)
+	}{
+		{"unknown token", func(r *fakeRepository, a *Account, hash string) {
+			delete(r.tokens, hash)
+		}},
+		{"expired token", func(r *fakeRepository, a *Account, hash string) {
+			token := r.tokens[hash]
+			token.ExpiresAt = time.Now().Add(-time.Minute)
+			r.tokens[hash] = token
+		}},
+		{"deactivated account", func(r *fakeRepository, a *Account, hash string) {
+			a.Status = AccountDeactivated
+			r.accounts[a.ID] = *a
+		}},
+		{"erased account", func(r *fakeRepository, a *Account, hash string) {
+			a.Status = AccountErased
+			r.accounts[a.ID] = *a
+		}},
+		{"deleted account", func(r *fakeRepository, a *Account, hash string) {
+			delete(r.accounts, a.ID)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, repository := newTestService(t)
+			a, tokens, err := s.Register(context.Background(), "Ada", "ada@example.com", "correct horse")
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.setup(repository, a, hashRefreshToken(tokens.RefreshToken))
+			if _, err := s.RefreshToken(context.Background(), tokens.RefreshToken); err != ErrInvalidToken {
+				t.Errorf("RefreshToken() error = %v, want %v", err, ErrInvalidToken)
+			}
+		})
+	}
+}
+
+func TestGrantRole(t *testing.T) {
+	tests := []struct {
+		name      string
+		vanishing bool
+		err       error
+	}{
+		{"granted", false, nil},
+		{"account deleted after the grant", true, ErrAccountNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, repository := newTestService(t)
+			a, _, err := s.Register(context.Background(), "Ada", "ada@example.com", "correct horse")
+			if err != nil {
+				t.Fatal(err)
+			}
+			repository.vanishing = tt.vanishing
+
+			granted, err := s.GrantRole(context.Background(), a.ID, RoleAdmin, "")
+			if err != tt.err {
+				t.Fatalf("GrantRole() error = %v, want %v", err, tt.err)
+			}
+			if err == nil && !slices.Contains(granted.Roles, RoleAdmin) {
+				t.Errorf("roles = %v, want %s among them", granted.Roles, RoleAdmin)
+			}
+		})
+	}
+}
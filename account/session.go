@@ -0,0 +1,68 @@
+package account
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// refreshMargin is how long before it expires an access token is replaced.
+const refreshMargin = time.Minute
+
+// ServiceSession signs a service in as its own account, so that it can call
+// other services when no caller's token is at hand, e.g. while handling a
+// webhook or in a background job. The access token is refreshed before it
+// expires, and the service signs in again when the refresh token runs out.
+type ServiceSession struct {
+	client   *Client
+	email    string
+	password string
+
+	mu     sync.Mutex
+	tokens *Tokens
+}
+
+func NewServiceSession(client *Client, email, password string) *ServiceSession {
+	return &ServiceSession{client: client, email: email, password: password}
+}
+
+// Context returns ctx with the service account's access token in its
+// outgoing metadata, replacing any token already there.
+func (c *ServiceSession) Context(ctx context.Context) (context.Context, error) {
+	token, err := c.accessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	md.Set("authorization", "Bearer "+token)
+	return metadata.NewOutgoingContext(ctx, md), nil
+}
+
+func (c *ServiceSession) accessToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if c.tokens != nil && now.Add(refreshMargin).Before(c.tokens.AccessTokenExpiresAt) {
+		return c.tokens.AccessToken, nil
+	}
+	// Signing in and refreshing must not carry a token of their own.
+	ctx = metadata.NewOutgoingContext(ctx, metadata.MD{})
+	if c.tokens != nil && now.Add(refreshMargin).Before(c.tokens.RefreshTokenExpiresAt) {
+		tokens, err := c.client.RefreshToken(ctx, c.tokens.RefreshToken)
+		if err == nil {
+			c.tokens = tokens
+			return tokens.AccessToken, nil
+		}
+	}
+	_, tokens, err := c.client.Login(ctx, c.email, c.password)
+	if err != nil {
+		c.tokens = nil
+		return "", err
+	}
+	c.tokens = tokens
+	return tokens.AccessToken, nil
+}
@@ -0,0 +1,140 @@
+package account
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	tokenIssuer = "account"
+
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+)
+
+// Claims are carried by every access token. Services holding the public
+// key can verify them without calling the account service.
+type Claims struct {
+	Name  string   `json:"name"`
+	Roles []string `json:"roles,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// AccountID returns the ID of the account the token was issued to.
+func (c *Claims) AccountID() string {
+	return c.Subject
+}
+
+func (c *Claims) HasRole(role string) bool {
+	for _, r := range c.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+type TokenIssuer struct {
+	key ed25519.PrivateKey
+}
+
+func NewTokenIssuer(key ed25519.PrivateKey) *TokenIssuer {
+	return &TokenIssuer{key: key}
+}
+
+// Issue signs an access token for a. The account ID is trimmed because IDs
+// read back from CHAR(36) columns are space padded.
+func (i *TokenIssuer) Issue(a Account, now time.Time) (string, time.Time, error) {
+	expiresAt := now.Add(AccessTokenTTL)
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, Claims{
+		Name:  a.Name,
+		Roles: a.Roles,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    tokenIssuer,
+			Subject:   strings.TrimSpace(a.ID),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	})
+	signed, err := token.SignedString(i.key)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, expiresAt, nil
+}
+
+type TokenVerifier struct {
+	key ed25519.PublicKey
+}
+
+func NewTokenVerifier(key ed25519.PublicKey) *TokenVerifier {
+	return &TokenVerifier{key: key}
+}
+
+func (v *TokenVerifier) Verify(token string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(
+		token,
+		claims,
+		func(*jwt.Token) (interface{}, error) { return v.key, nil },
+		jwt.WithValidMethods([]string{jwt.SigningMethodEdDSA.Alg()}),
+		jwt.WithIssuer(tokenIssuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// ParsePrivateKey decodes a base64 encoded 32 byte Ed25519 seed.
+func ParsePrivateKey(s string) (ed25519.PrivateKey, error) {
+	seed, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(seed) != ed25519.SeedSize {
+		return nil, errors.New("invalid private key size")
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// ParsePublicKey decodes a base64 encoded 32 byte Ed25519 public key.
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return nil, errors.New("invalid public key size")
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// newRefreshToken returns an opaque refresh token and the hash under which
+// it is stored. Only the hash ever reaches the database.
+func newRefreshToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	return token, hashRefreshToken(token), nil
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,107 @@
+package account
+
+import (
+	"crypto/ed25519"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func newTestKeys(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return public, private
+}
+
+func TestIssueVerify(t *testing.T) {
+	public, private := newTestKeys(t)
+	a := Account{ID: "2f1c9d4e-6c1b-4e4a-9a39-1f6f1f7e9b2a    ", Name: "Ada", Roles: []string{RoleCustomer}}
+
+	token, expiresAt, err := NewTokenIssuer(private).Issue(a, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := NewTokenVerifier(public).Verify(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := claims.AccountID(), "2f1c9d4e-6c1b-4e4a-9a39-1f6f1f7e9b2a"; got != want {
+		t.Errorf("AccountID() = %q, want %q", got, want)
+	}
+	if claims.Name != a.Name || !reflect.DeepEqual(claims.Roles, a.Roles) {
+		t.Errorf("claims = %q %v, want %q %v", claims.Name, claims.Roles, a.Name, a.Roles)
+	}
+	if !claims.ExpiresAt.Time.Equal(expiresAt.Truncate(time.Second)) {
+		t.Errorf("expires at %v, want %v", claims.ExpiresAt.Time, expiresAt)
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	public, private := newTestKeys(t)
+	_, otherPrivate := newTestKeys(t)
+	a := Account{ID: "account", Name: "Ada"}
+
+	issue := func(key ed25519.PrivateKey, now time.Time) string {
+		token, _, err := NewTokenIssuer(key).Issue(a, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	sign := func(method jwt.SigningMethod, key interface{}, claims Claims) string {
+		token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	valid := jwt.RegisteredClaims{
+		Issuer:    tokenIssuer,
+		Subject:   a.ID,
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+	}
+	otherIssuer := valid
+	otherIssuer.Issuer = "someone"
+	noExpiry := valid
+	noExpiry.ExpiresAt = nil
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"garbage", "not a token"},
+		{"expired", issue(private, time.Now().Add(-AccessTokenTTL-time.Minute))},
+		{"signed with another key", issue(otherPrivate, time.Now())},
+		{"signed with HMAC", sign(jwt.SigningMethodHS256, []byte(public), Claims{RegisteredClaims: valid})},
+		{"other issuer", sign(jwt.SigningMethodEdDSA, private, Claims{RegisteredClaims: otherIssuer})},
+		{"no expiry", sign(jwt.SigningMethodEdDSA, private, Claims{RegisteredClaims: noExpiry})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewTokenVerifier(public).Verify(tt.token); err != ErrInvalidToken {
+				t.Errorf("Verify() error = %v, want %v", err, ErrInvalidToken)
+			}
+		})
+	}
+}
+
+func TestNewRefreshToken(t *testing.T) {
+	token, hash, err := newRefreshToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash != hashRefreshToken(token) {
+		t.Errorf("hash = %q, want the hash of the token %q", hash, hashRefreshToken(token))
+	}
+	other, _, err := newRefreshToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token == other {
+		t.Errorf("two refresh tokens are both %q", token)
+	}
+}
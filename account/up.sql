@@ -1,4 +1,66 @@
 CREATE TABLE IF NOT EXISTS accounts (
     id CHAR(36) PRIMARY KEY,
-    name VARCHAR(100) NOT NULL
-);
\ No newline at end of file
+    name VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    deactivated_at TIMESTAMP WITH TIME ZONE,
+    erased_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE TABLE IF NOT EXISTS account_credentials (
+    account_id CHAR(36) PRIMARY KEY REFERENCES accounts(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL UNIQUE,
+    password_hash VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    token_hash CHAR(64) PRIMARY KEY,
+    account_id CHAR(36) NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS refresh_tokens_account_id_idx ON refresh_tokens (account_id);
+
+CREATE TABLE IF NOT EXISTS roles (
+    name VARCHAR(50) PRIMARY KEY
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role VARCHAR(50) NOT NULL REFERENCES roles(name) ON DELETE CASCADE,
+    permission VARCHAR(100) NOT NULL,
+    PRIMARY KEY (role, permission)
+);
+
+CREATE TABLE IF NOT EXISTS account_roles (
+    account_id CHAR(36) NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    role VARCHAR(50) NOT NULL REFERENCES roles(name) ON DELETE CASCADE,
+    granted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    granted_by CHAR(36),
+    PRIMARY KEY (account_id, role)
+);
+
+INSERT INTO roles (name) VALUES
+    ('customer'),
+    ('catalog-manager'),
+    ('support'),
+    ('admin')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('customer', 'order:create'),
+    ('catalog-manager', 'product:write'),
+    ('catalog-manager', 'stock:reserve'),
+    ('support', 'account:read:any'),
+    ('support', 'order:read:any'),
+    ('support', 'order:write:any'),
+    ('support', 'account:write:any'),
+    ('admin', 'product:write'),
+    ('admin', 'stock:reserve'),
+    ('admin', 'order:create'),
+    ('admin', 'order:read:any'),
+    ('admin', 'order:write:any'),
+    ('admin', 'account:read:any'),
+    ('admin', 'account:write:any'),
+    ('admin', 'role:manage')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,155 @@
+package cart
+
+import (
+	"context"
+	"time"
+
+	"github.com/suryanshp1/go-microservice/cart/pb"
+	"github.com/suryanshp1/go-microservice/money"
+	"google.golang.org/grpc"
+)
+
+type Client struct {
+	conn    *grpc.ClientConn
+	service pb.CartServiceClient
+}
+
+func NewClient(url string) (*Client, error) {
+	conn, err := grpc.Dial(url, grpc.WithInsecure())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		conn:    conn,
+		service: pb.NewCartServiceClient(conn),
+	}, nil
+}
+
+func (c *Client) Close() {
+	c.conn.Close()
+}
+
+// GetCart returns the caller's cart, or that of sessionID for anonymous
+// callers, priced in currency if it is not empty.
+func (c *Client) GetCart(ctx context.Context, sessionID, currency string) (*Cart, error) {
+	r, err := c.service.GetCart(ctx, &pb.GetCartRequest{
+		SessionId: sessionID,
+		Currency:  currency,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cartFromProto(r.Cart), nil
+}
+
+// AddItem adds quantity of a product to the caller's cart. Anonymous
+// callers without a session get a new one, returned in Cart.SessionID.
+func (c *Client) AddItem(ctx context.Context, sessionID, productID, variantID string, quantity uint32, currency string) (*Cart, error) {
+	r, err := c.service.AddItem(ctx, &pb.AddItemRequest{
+		SessionId: sessionID,
+		ProductId: productID,
+		VariantId: variantID,
+		Quantity:  quantity,
+		Currency:  currency,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cartFromProto(r.Cart), nil
+}
+
+// UpdateItem sets the quantity of a product in the caller's cart. Zero
+// removes it.
+func (c *Client) UpdateItem(ctx context.Context, sessionID, productID, variantID string, quantity uint32, currency string) (*Cart, error) {
+	r, err := c.service.UpdateItem(ctx, &pb.UpdateItemRequest{
+		SessionId: sessionID,
+		ProductId: productID,
+		VariantId: variantID,
+		Quantity:  quantity,
+		Currency:  currency,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cartFromProto(r.Cart), nil
+}
+
+func (c *Client) ClearCart(ctx context.Context, sessionID string) (*Cart, error) {
+	r, err := c.service.ClearCart(ctx, &pb.ClearCartRequest{
+		SessionId: sessionID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cartFromProto(r.Cart), nil
+}
+
+// Checkout orders the items of the caller's cart and returns the ID of the
+// order together with the emptied cart.
+func (c *Client) Checkout(ctx context.Context, sessionID, currency, idempotencyKey string) (string, *Cart, error) {
+	r, err := c.service.Checkout(ctx, &pb.CheckoutRequest{
+		SessionId:      sessionID,
+		Currency:       currency,
+		IdempotencyKey: idempotencyKey,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return r.OrderId, cartFromProto(r.Cart), nil
+}
+
+func cartFromProto(cp *pb.Cart) *Cart {
+	c := &Cart{
+		ID:        cp.Id,
+		AccountID: cp.AccountId,
+		SessionID: cp.SessionId,
+		Items:     []Item{},
+		Pricing: Pricing{
+			Total:    moneyFromProto(cp.Total),
+			Quantity: cp.Quantity,
+			Problems: int(cp.Problems),
+		},
+	}
+	c.CreatedAt = time.Time{}
+	c.CreatedAt.UnmarshalBinary(cp.CreatedAt)
+	c.UpdatedAt = time.Time{}
+	c.UpdatedAt.UnmarshalBinary(cp.UpdatedAt)
+
+	for _, ip := range cp.Items {
+		item := Item{
+			ProductID: ip.ProductId,
+			VariantID: ip.VariantId,
+			Quantity:  ip.Quantity,
+			Details: ItemDetails{
+				Name:      ip.Name,
+				SKU:       ip.Sku,
+				Price:     optionalMoneyFromProto(ip.Price),
+				Total:     optionalMoneyFromProto(ip.Total),
+				Available: ip.Available,
+				Problem:   Problem(ip.Problem),
+			},
+		}
+		item.AddedAt.UnmarshalBinary(ip.AddedAt)
+		if r := ip.ExchangeRate; r != nil {
+			item.Details.ExchangeRate = &money.Rate{From: r.From, To: r.To, Value: r.Rate}
+		}
+		c.Items = append(c.Items, item)
+	}
+	return c
+}
+
+func moneyFromProto(pm *pb.Money) money.Money {
+	if pm == nil {
+		return money.Money{}
+	}
+	return money.New(pm.Amount, pm.Currency)
+}
+
+func optionalMoneyFromProto(pm *pb.Money) *money.Money {
+	if pm == nil {
+		return nil
+	}
+	m := moneyFromProto(pm)
+	return &m
+}
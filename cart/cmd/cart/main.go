@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/suryanshp1/go-microservice/account"
+	"github.com/suryanshp1/go-microservice/cart"
+	"github.com/tinrab/retry"
+)
+
+type Config struct {
+	DatabaseURL string `envconfig:"DATABASE_URL"`
+	CatalogURL  string `envconfig:"CATALOG_SERVICE_URL"`
+	OrderURL    string `envconfig:"ORDER_SERVICE_URL"`
+	PublicKey   string `envconfig:"TOKEN_PUBLIC_KEY"`
+}
+
+func main() {
+	var cfg Config
+	err := envconfig.Process("", &cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	publicKey, err := account.ParsePublicKey(cfg.PublicKey)
+	if err != nil {
+		log.Fatalf("invalid TOKEN_PUBLIC_KEY: %v", err)
+	}
+
+	var r cart.Repository
+	retry.ForeverSleep(2*time.Second, func(_ int) (err error) {
+		r, err = cart.NewPostgresRepository(cfg.DatabaseURL)
+		if err != nil {
+			log.Printf("failed to connect to database: %v", err)
+		}
+		return
+	})
+
+	defer r.Close()
+	log.Println("Listening on port 8080......")
+
+	s := cart.NewService(r)
+
+	// Forget carts of sessions that were abandoned.
+	go func() {
+		for range time.Tick(time.Hour) {
+			n, err := s.DeleteStaleCarts(context.Background())
+			if err != nil {
+				log.Println("Error deleting stale carts:", err)
+			} else if n > 0 {
+				log.Printf("Deleted %d stale carts", n)
+			}
+		}
+	}()
+
+	log.Fatal(cart.ListenGRPC(s, cfg.CatalogURL, cfg.OrderURL, account.NewTokenVerifier(publicKey), 8080))
+}
@@ -0,0 +1,113 @@
+package cart
+
+import (
+	"github.com/suryanshp1/go-microservice/catalog"
+	"github.com/suryanshp1/go-microservice/money"
+)
+
+// Problem tells why an item of a cart cannot be ordered as it is.
+type Problem string
+
+const (
+	ProblemNone              Problem = ""
+	ProblemUnavailable       Problem = "unavailable"
+	ProblemVariantRequired   Problem = "variant_required"
+	ProblemVariantNotFound   Problem = "variant_not_found"
+	ProblemInsufficientStock Problem = "insufficient_stock"
+	ProblemCurrencyMismatch  Problem = "currency_mismatch"
+)
+
+// ItemDetails is what the catalog currently says about an item. Price and
+// Total are nil for items that are unavailable.
+type ItemDetails struct {
+	Name         string
+	SKU          string
+	Price        *money.Money
+	Total        *money.Money
+	Available    int64
+	ExchangeRate *money.Rate
+	Problem      Problem
+}
+
+// Pricing sums up a priced cart. Total covers the items without problems;
+// Problems counts the others. A cart can be checked out if it has items and
+// no problems.
+type Pricing struct {
+	Total    money.Money
+	Quantity uint32
+	Problems int
+}
+
+// price fills in the details of the items of c from products, which must
+// be the catalog's products of c priced in currency, and sums them up. An
+// empty currency keeps the currency products are priced in; items priced in
+// another currency than the first one are then a problem, as orders are
+// placed in a single currency.
+func (c *Cart) price(products []*catalog.Product, currency string) {
+	byID := map[string]*catalog.Product{}
+	for _, p := range products {
+		if p.DeletedAt == nil {
+			byID[p.ID] = p
+		}
+	}
+
+	c.Pricing = Pricing{Total: money.New(0, currency)}
+	for i := range c.Items {
+		item := &c.Items[i]
+		item.Details = priceItem(item, byID[item.ProductID])
+		if item.Details.Price != nil && c.Pricing.Total.Currency == "" {
+			c.Pricing.Total.Currency = item.Details.Price.Currency
+		}
+		if item.Details.Price != nil && item.Details.Price.Currency != c.Pricing.Total.Currency {
+			item.Details.Problem = ProblemCurrencyMismatch
+		}
+		if item.Details.Problem != ProblemNone {
+			c.Pricing.Problems++
+			continue
+		}
+		total, err := c.Pricing.Total.Add(*item.Details.Total)
+		if err != nil {
+			item.Details.Problem = ProblemCurrencyMismatch
+			c.Pricing.Problems++
+			continue
+		}
+		c.Pricing.Total = total
+		c.Pricing.Quantity += item.Quantity
+	}
+	if c.Pricing.Total.Currency == "" {
+		c.Pricing.Total.Currency = money.DefaultCurrency
+	}
+}
+
+func priceItem(item *Item, p *catalog.Product) ItemDetails {
+	if p == nil {
+		return ItemDetails{Problem: ProblemUnavailable}
+	}
+
+	d := ItemDetails{Name: p.Name, Available: p.Available()}
+	if item.VariantID != "" {
+		v := p.Variant(item.VariantID)
+		if v == nil {
+			d.Problem = ProblemVariantNotFound
+			return d
+		}
+		d.SKU, d.Available = v.SKU, v.Available()
+	} else if len(p.Variants) > 0 {
+		d.Problem = ProblemVariantRequired
+		return d
+	}
+
+	price := p.PriceOf(item.VariantID)
+	total, err := price.Mul(int64(item.Quantity))
+	if err != nil {
+		return ItemDetails{Name: p.Name, Problem: ProblemUnavailable}
+	}
+	d.Price, d.Total = &price, &total
+	if p.ExchangeRate != nil {
+		d.ExchangeRate = &p.ExchangeRate.Rate
+	}
+	if int64(item.Quantity) > d.Available {
+		d.Problem = ProblemInsufficientStock
+	}
+	return d
+}
@@ -0,0 +1,333 @@
+package cart
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	_ "github.com/lib/pq"
+)
+
+type Repository interface {
+	Close()
+	PutCart(ctx context.Context, c *Cart) error
+	GetCart(ctx context.Context, owner Owner) (*Cart, error)
+	GetCartByID(ctx context.Context, id string) (*Cart, error)
+	PutItem(ctx context.Context, cartID string, item Item) error
+	AddItem(ctx context.Context, cartID string, item Item, maxItems int, maxQuantity uint32) error
+	DeleteItem(ctx context.Context, cartID, productID, variantID string) error
+	ClearCart(ctx context.Context, cartID string, updatedAt time.Time) error
+	MergeSessionCart(ctx context.Context, sessionID, accountID string, maxItems int, maxQuantity uint32) error
+	DeleteSessionCarts(ctx context.Context, updatedBefore time.Time) (int, error)
+}
+
+type postgresRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresRepository(url string) (Repository, error) {
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		return nil, err
+	}
+	err = db.Ping()
+	if err != nil {
+		return nil, err
+	}
+	return &postgresRepository{db}, nil
+}
+
+func (r *postgresRepository) Close() {
+	r.db.Close()
+}
+
+// PutCart creates c unless its owner already has a cart.
+func (r *postgresRepository) PutCart(ctx context.Context, c *Cart) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		`INSERT INTO carts(id, account_id, session_id, created_at, updated_at)
+    VALUES($1, NULLIF($2, ''), NULLIF($3, ''), $4, $5)
+    ON CONFLICT DO NOTHING`,
+		c.ID,
+		c.AccountID,
+		c.SessionID,
+		c.CreatedAt,
+		c.UpdatedAt,
+	)
+	return err
+}
+
+// GetCart returns the cart of an account, or of a session if owner has no
+// account.
+func (r *postgresRepository) GetCart(ctx context.Context, owner Owner) (*Cart, error) {
+	if owner.AccountID != "" {
+		return r.getCart(ctx, "account_id = $1", owner.AccountID)
+	}
+	return r.getCart(ctx, "session_id = $1", owner.SessionID)
+}
+
+func (r *postgresRepository) GetCartByID(ctx context.Context, id string) (*Cart, error) {
+	return r.getCart(ctx, "id = $1", id)
+}
+
+// queryer is what reading items needs of a database or transaction.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+func (r *postgresRepository) getCart(ctx context.Context, where string, arg string) (*Cart, error) {
+	c := &Cart{Items: []Item{}}
+	var accountID, sessionID sql.NullString
+	err := r.db.QueryRowContext(
+		ctx,
+		`SELECT id, account_id, session_id, created_at, updated_at FROM carts WHERE `+where,
+		arg,
+	).Scan(&c.ID, &accountID, &sessionID, &c.CreatedAt, &c.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrCartNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.AccountID, c.SessionID = accountID.String, sessionID.String
+
+	if c.Items, err = getItems(ctx, r.db, c.ID); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func getItems(ctx context.Context, q queryer, cartID string) ([]Item, error) {
+	rows, err := q.QueryContext(
+		ctx,
+		`SELECT product_id, variant_id, quantity, added_at
+    FROM cart_items
+    WHERE cart_id = $1
+    ORDER BY added_at, product_id, variant_id`,
+		cartID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Item{}
+	for rows.Next() {
+		var item Item
+		if err := rows.Scan(&item.ProductID, &item.VariantID, &item.Quantity, &item.AddedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// PutItem adds item to a cart, or sets its quantity if the cart has it.
+func (r *postgresRepository) PutItem(ctx context.Context, cartID string, item Item) error {
+	return r.updateCart(ctx, cartID, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(
+			ctx,
+			`INSERT INTO cart_items(cart_id, product_id, variant_id, quantity)
+      VALUES($1, $2, $3, $4)
+      ON CONFLICT (cart_id, product_id, variant_id) DO UPDATE SET quantity = EXCLUDED.quantity`,
+			cartID,
+			item.ProductID,
+			item.VariantID,
+			item.Quantity,
+		)
+		return err
+	})
+}
+
+// AddItem adds item to a cart, or adds its quantity to that of the item
+// already in the cart. It fails with ErrCartFull if the cart holds maxItems
+// other items, and with ErrInvalidQuantity if the sum exceeds maxQuantity.
+func (r *postgresRepository) AddItem(ctx context.Context, cartID string, item Item, maxItems int, maxQuantity uint32) error {
+	return r.updateCart(ctx, cartID, func(tx *sql.Tx) error {
+		// Concurrent additions wait for each other, so that they cannot
+		// both take the last free line.
+		var full bool
+		err := tx.QueryRowContext(
+			ctx,
+			`SELECT (SELECT count(*) FROM cart_items WHERE cart_id = $1 AND NOT (product_id = $2 AND variant_id = $3)) >= $4
+      FROM carts WHERE id = $1 FOR UPDATE`,
+			cartID,
+			item.ProductID,
+			item.VariantID,
+			maxItems,
+		).Scan(&full)
+		if err == sql.ErrNoRows {
+			return ErrCartNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if full {
+			return ErrCartFull
+		}
+
+		res, err := tx.ExecContext(
+			ctx,
+			`INSERT INTO cart_items(cart_id, product_id, variant_id, quantity)
+      VALUES($1, $2, $3, $4)
+      ON CONFLICT (cart_id, product_id, variant_id) DO UPDATE SET quantity = cart_items.quantity + EXCLUDED.quantity
+      WHERE cart_items.quantity + EXCLUDED.quantity <= $5`,
+			cartID,
+			item.ProductID,
+			item.VariantID,
+			item.Quantity,
+			maxQuantity,
+		)
+		if err != nil {
+			return err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return ErrInvalidQuantity
+		}
+		return nil
+	})
+}
+
+func (r *postgresRepository) DeleteItem(ctx context.Context, cartID, productID, variantID string) error {
+	return r.updateCart(ctx, cartID, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(
+			ctx,
+			"DELETE FROM cart_items WHERE cart_id = $1 AND product_id = $2 AND variant_id = $3",
+			cartID,
+			productID,
+			variantID,
+		)
+		return err
+	})
+}
+
+// ClearCart removes all items of a cart. A non-zero updatedAt only clears
+// the cart if it was not changed since then.
+func (r *postgresRepository) ClearCart(ctx context.Context, cartID string, updatedAt time.Time) error {
+	return r.updateCart(ctx, cartID, func(tx *sql.Tx) error {
+		var current time.Time
+		err := tx.QueryRowContext(ctx, "SELECT updated_at FROM carts WHERE id = $1 FOR UPDATE", cartID).Scan(&current)
+		if err == sql.ErrNoRows {
+			return ErrCartNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if !updatedAt.IsZero() && !current.Equal(updatedAt) {
+			return ErrCartChanged
+		}
+		_, err = tx.ExecContext(ctx, "DELETE FROM cart_items WHERE cart_id = $1", cartID)
+		return err
+	})
+}
+
+// MergeSessionCart moves the cart of a session to an account. If the
+// account has a cart already, the session's items are added to it as
+// mergeItems does, and the session's cart is deleted.
+func (r *postgresRepository) MergeSessionCart(ctx context.Context, sessionID, accountID string, maxItems int, maxQuantity uint32) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	var sessionCartID string
+	err = tx.QueryRowContext(ctx, "SELECT id FROM carts WHERE session_id = $1 FOR UPDATE", sessionID).Scan(&sessionCartID)
+	if err == sql.ErrNoRows {
+		return ErrCartNotFound
+	}
+	if err != nil {
+		return
+	}
+
+	var accountCartID string
+	err = tx.QueryRowContext(ctx, "SELECT id FROM carts WHERE account_id = $1 FOR UPDATE", accountID).Scan(&accountCartID)
+	if err == sql.ErrNoRows {
+		_, err = tx.ExecContext(
+			ctx,
+			"UPDATE carts SET account_id = $2, session_id = NULL, updated_at = now() WHERE id = $1",
+			sessionCartID,
+			accountID,
+		)
+		return
+	}
+	if err != nil {
+		return
+	}
+
+	accountItems, err := getItems(ctx, tx, accountCartID)
+	if err != nil {
+		return
+	}
+	sessionItems, err := getItems(ctx, tx, sessionCartID)
+	if err != nil {
+		return
+	}
+	for _, item := range mergeItems(accountItems, sessionItems, maxItems, maxQuantity) {
+		_, err = tx.ExecContext(
+			ctx,
+			`INSERT INTO cart_items(cart_id, product_id, variant_id, quantity, added_at)
+      VALUES($1, $2, $3, $4, $5)
+      ON CONFLICT (cart_id, product_id, variant_id) DO UPDATE SET quantity = EXCLUDED.quantity`,
+			accountCartID,
+			item.ProductID,
+			item.VariantID,
+			item.Quantity,
+			item.AddedAt,
+		)
+		if err != nil {
+			return
+		}
+	}
+	_, err = tx.ExecContext(ctx, "DELETE FROM carts WHERE id = $1", sessionCartID)
+	if err != nil {
+		return
+	}
+	_, err = tx.ExecContext(ctx, "UPDATE carts SET updated_at = now() WHERE id = $1", accountCartID)
+	return
+}
+
+// DeleteSessionCarts deletes the carts of sessions that were not changed
+// since updatedBefore and returns how many there were.
+func (r *postgresRepository) DeleteSessionCarts(ctx context.Context, updatedBefore time.Time) (int, error) {
+	res, err := r.db.ExecContext(
+		ctx,
+		"DELETE FROM carts WHERE account_id IS NULL AND updated_at < $1",
+		updatedBefore,
+	)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// updateCart runs fn in a transaction and marks the cart as changed.
+func (r *postgresRepository) updateCart(ctx context.Context, cartID string, fn func(tx *sql.Tx) error) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	if err = fn(tx); err != nil {
+		return
+	}
+	_, err = tx.ExecContext(ctx, "UPDATE carts SET updated_at = now() WHERE id = $1", cartID)
+	return
+}
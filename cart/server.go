@@ -0,0 +1,301 @@
+// protoc --go_out=./ --go-grpc_out=./ cart.proto
+package cart
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+
+	account "github.com/suryanshp1/go-microservice/account"
+	"github.com/suryanshp1/go-microservice/cart/pb"
+	catalog "github.com/suryanshp1/go-microservice/catalog"
+	"github.com/suryanshp1/go-microservice/money"
+	order "github.com/suryanshp1/go-microservice/order"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+type grpcServer struct {
+	pb.UnimplementedCartServiceServer
+	service       Service
+	catalogClient *catalog.Client
+	orderClient   *order.Client
+}
+
+func ListenGRPC(s Service, catalogURL, orderURL string, verifier *account.TokenVerifier, port int) error {
+	catalogClient, err := catalog.NewClient(catalogURL)
+	if err != nil {
+		return err
+	}
+
+	orderClient, err := order.NewClient(orderURL)
+	if err != nil {
+		catalogClient.Close()
+		return err
+	}
+
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		catalogClient.Close()
+		orderClient.Close()
+		return err
+	}
+
+	serv := grpc.NewServer(grpc.UnaryInterceptor(account.UnaryServerInterceptor(verifier)))
+	pb.RegisterCartServiceServer(serv, &grpcServer{
+		pb.UnimplementedCartServiceServer{},
+		s,
+		catalogClient,
+		orderClient,
+	})
+	reflection.Register(serv)
+
+	return serv.Serve(lis)
+}
+
+// owner returns the owner of the caller's cart: the authenticated account,
+// or the session for anonymous callers.
+func owner(ctx context.Context, sessionID string) Owner {
+	o := Owner{SessionID: strings.TrimSpace(sessionID)}
+	if claims := account.ClaimsFromContext(ctx); claims != nil {
+		o.AccountID = claims.AccountID()
+	}
+	return o
+}
+
+func (s *grpcServer) GetCart(ctx context.Context, r *pb.GetCartRequest) (*pb.GetCartResponse, error) {
+	c, err := s.service.GetCart(ctx, owner(ctx, r.SessionId))
+	if err != nil {
+		log.Println(err)
+		return nil, toStatus(err)
+	}
+	if err := s.price(ctx, c, r.Currency); err != nil {
+		return nil, err
+	}
+	return &pb.GetCartResponse{Cart: cartToProto(c)}, nil
+}
+
+func (s *grpcServer) AddItem(ctx context.Context, r *pb.AddItemRequest) (*pb.AddItemResponse, error) {
+	if err := s.checkItem(ctx, r.ProductId, r.VariantId); err != nil {
+		return nil, err
+	}
+	c, err := s.service.AddItem(ctx, owner(ctx, r.SessionId), Item{
+		ProductID: r.ProductId,
+		VariantID: r.VariantId,
+		Quantity:  r.Quantity,
+	})
+	if err != nil {
+		log.Println(err)
+		return nil, toStatus(err)
+	}
+	if err := s.price(ctx, c, r.Currency); err != nil {
+		return nil, err
+	}
+	return &pb.AddItemResponse{Cart: cartToProto(c)}, nil
+}
+
+func (s *grpcServer) UpdateItem(ctx context.Context, r *pb.UpdateItemRequest) (*pb.UpdateItemResponse, error) {
+	c, err := s.service.UpdateItem(ctx, owner(ctx, r.SessionId), Item{
+		ProductID: r.ProductId,
+		VariantID: r.VariantId,
+		Quantity:  r.Quantity,
+	})
+	if err != nil {
+		log.Println(err)
+		return nil, toStatus(err)
+	}
+	if err := s.price(ctx, c, r.Currency); err != nil {
+		return nil, err
+	}
+	return &pb.UpdateItemResponse{Cart: cartToProto(c)}, nil
+}
+
+func (s *grpcServer) ClearCart(ctx context.Context, r *pb.ClearCartRequest) (*pb.ClearCartResponse, error) {
+	c, err := s.service.ClearCart(ctx, owner(ctx, r.SessionId))
+	if err != nil {
+		log.Println(err)
+		return nil, toStatus(err)
+	}
+	c.price(nil, "")
+	return &pb.ClearCartResponse{Cart: cartToProto(c)}, nil
+}
+
+// Checkout places an order for the items of the caller's cart and empties
+// the cart. Items with problems fail the checkout before the order service
+// is called; the order service checks stock again when it reserves it.
+func (s *grpcServer) Checkout(ctx context.Context, r *pb.CheckoutRequest) (*pb.CheckoutResponse, error) {
+	claims := account.ClaimsFromContext(ctx)
+	if claims == nil {
+		return nil, status.Error(codes.Unauthenticated, "authentication required")
+	}
+
+	o := owner(ctx, r.SessionId)
+	c, err := s.service.GetCart(ctx, o)
+	if err != nil {
+		log.Println(err)
+		return nil, toStatus(err)
+	}
+	if err := s.price(ctx, c, r.Currency); err != nil {
+		return nil, err
+	}
+	if len(c.Items) == 0 {
+		return nil, toStatus(ErrCartEmpty)
+	}
+	if c.Pricing.Problems > 0 {
+		return nil, toStatus(ErrCartNotReady)
+	}
+
+	products := []order.OrderedProduct{}
+	for _, item := range c.Items {
+		products = append(products, order.OrderedProduct{
+			ID:        item.ProductID,
+			VariantID: item.VariantID,
+			Quantity:  item.Quantity,
+		})
+	}
+	// The cart's version is part of the default key, so retrying a checkout
+	// of an unchanged cart returns the order placed by the first attempt.
+	key := r.IdempotencyKey
+	if key == "" {
+		key = fmt.Sprintf("cart-%s-%d-%s", c.ID, c.UpdatedAt.UnixNano(), c.Pricing.Total.Currency)
+	}
+	placed, err := s.orderClient.PostOrder(ctx, claims.AccountID(), products, key, strings.ToUpper(strings.TrimSpace(r.Currency)))
+	if err != nil {
+		log.Printf("failed to check out cart %s: %v", c.ID, err)
+		return nil, err
+	}
+
+	err = s.service.CompleteCheckout(context.WithoutCancel(ctx), c)
+	if err != nil && err != ErrCartChanged {
+		log.Printf("failed to clear cart %s after order %s: %v", c.ID, placed.ID, err)
+	}
+	c, err = s.service.GetCart(ctx, Owner{AccountID: claims.AccountID()})
+	if err != nil {
+		log.Println(err)
+		return nil, toStatus(err)
+	}
+	if err := s.price(ctx, c, r.Currency); err != nil {
+		return nil, err
+	}
+	return &pb.CheckoutResponse{OrderId: placed.ID, Cart: cartToProto(c)}, nil
+}
+
+// checkItem checks that a product, or one of its variants, can be added to
+// a cart. Stock is not checked; items that run out are reported when the
+// cart is read.
+func (s *grpcServer) checkItem(ctx context.Context, productID, variantID string) error {
+	if productID == "" {
+		return toStatus(ErrInvalidItem)
+	}
+	p, err := s.catalogClient.GetProduct(ctx, productID, "")
+	if status.Code(err) == codes.NotFound || err == nil && p.DeletedAt != nil {
+		return status.Errorf(codes.NotFound, "product %s does not exist", productID)
+	}
+	if err != nil {
+		log.Printf("failed to get product: %v", err)
+		return errors.New("failed to get product")
+	}
+	if variantID != "" && p.Variant(variantID) == nil {
+		return status.Errorf(codes.NotFound, "product %s has no variant %s", productID, variantID)
+	}
+	if variantID == "" && len(p.Variants) > 0 {
+		return status.Error(codes.InvalidArgument, catalog.ErrVariantRequired.Error())
+	}
+	return nil
+}
+
+// price looks up the items of c in the catalog and prices them in
+// currency, or in the currency of the products if it is empty.
+func (s *grpcServer) price(ctx context.Context, c *Cart, currency string) error {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency != "" && !money.ValidCurrency(currency) {
+		return status.Error(codes.InvalidArgument, money.ErrInvalidCurrency.Error())
+	}
+	if len(c.Items) == 0 {
+		c.price(nil, currency)
+		return nil
+	}
+
+	ids := []string{}
+	seen := map[string]bool{}
+	for _, item := range c.Items {
+		if !seen[item.ProductID] {
+			seen[item.ProductID] = true
+			ids = append(ids, item.ProductID)
+		}
+	}
+	products, err := s.catalogClient.GetProducts(ctx, 0, 0, ids, "", currency)
+	if status.Code(err) == codes.FailedPrecondition {
+		// No exchange rate to the requested currency.
+		return err
+	}
+	if err != nil {
+		log.Printf("failed to get products: %v", err)
+		return errors.New("failed to get products")
+	}
+	c.price(products, currency)
+	return nil
+}
+
+func toStatus(err error) error {
+	switch err {
+	case ErrCartNotFound:
+		return status.Error(codes.NotFound, err.Error())
+	case ErrInvalidItem, ErrInvalidQuantity:
+		return status.Error(codes.InvalidArgument, err.Error())
+	case ErrCartFull, ErrCartEmpty, ErrCartNotReady:
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return err
+}
+
+func cartToProto(c *Cart) *pb.Cart {
+	cp := &pb.Cart{
+		Id:        c.ID,
+		AccountId: c.AccountID,
+		SessionId: c.SessionID,
+		Items:     []*pb.Item{},
+		Total:     moneyToProto(c.Pricing.Total),
+		Quantity:  c.Pricing.Quantity,
+		Problems:  uint32(c.Pricing.Problems),
+	}
+	if !c.CreatedAt.IsZero() {
+		cp.CreatedAt, _ = c.CreatedAt.MarshalBinary()
+		cp.UpdatedAt, _ = c.UpdatedAt.MarshalBinary()
+	}
+	for _, item := range c.Items {
+		ip := &pb.Item{
+			ProductId: item.ProductID,
+			VariantId: item.VariantID,
+			Quantity:  item.Quantity,
+			Name:      item.Details.Name,
+			Sku:       item.Details.SKU,
+			Price:     optionalMoneyToProto(item.Details.Price),
+			Total:     optionalMoneyToProto(item.Details.Total),
+			Available: item.Details.Available,
+			Problem:   string(item.Details.Problem),
+		}
+		ip.AddedAt, _ = item.AddedAt.MarshalBinary()
+		if r := item.Details.ExchangeRate; r != nil {
+			ip.ExchangeRate = &pb.ExchangeRate{From: r.From, To: r.To, Rate: r.Value}
+		}
+		cp.Items = append(cp.Items, ip)
+	}
+	return cp
+}
+
+func moneyToProto(m money.Money) *pb.Money {
+	return &pb.Money{Amount: m.Amount, Currency: m.Currency}
+}
+
+func optionalMoneyToProto(m *money.Money) *pb.Money {
+	if m == nil {
+		return nil
+	}
+	return moneyToProto(*m)
+}
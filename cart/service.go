@@ -0,0 +1,253 @@
+package cart
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/segmentio/ksuid"
+)
+
+const (
+	// MaxItems is how many different products and variants a cart holds.
+	MaxItems = 100
+	// MaxQuantity is the largest quantity of one item.
+	MaxQuantity = 1000
+	// SessionTTL is how long an anonymous cart is kept after its last
+	// change.
+	SessionTTL = 30 * 24 * time.Hour
+)
+
+var (
+	ErrCartNotFound    = errors.New("cart not found")
+	ErrCartEmpty       = errors.New("cart is empty")
+	ErrCartFull        = errors.New("cart cannot hold more items")
+	ErrCartNotReady    = errors.New("cart has items that cannot be ordered")
+	ErrCartChanged     = errors.New("cart was changed")
+	ErrInvalidItem     = errors.New("cart item must have a product")
+	ErrInvalidQuantity = errors.New("quantity must be between 1 and 1000")
+)
+
+type Service interface {
+	GetCart(ctx context.Context, owner Owner) (*Cart, error)
+	AddItem(ctx context.Context, owner Owner, item Item) (*Cart, error)
+	UpdateItem(ctx context.Context, owner Owner, item Item) (*Cart, error)
+	ClearCart(ctx context.Context, owner Owner) (*Cart, error)
+	CompleteCheckout(ctx context.Context, c *Cart) error
+	DeleteStaleCarts(ctx context.Context) (int, error)
+}
+
+// Owner identifies a cart: the cart of an account, or the anonymous cart of
+// a session. An account that also names a session takes over the items of
+// the session's cart, e.g. after logging in. Anonymous callers without a
+// session get a new session when they first add an item.
+type Owner struct {
+	AccountID string
+	SessionID string
+}
+
+// Cart is what an account or session is about to order. Items are stored
+// as product, variant and quantity only; the rest is looked up in the
+// catalog whenever the cart is read, so prices are never stale.
+type Cart struct {
+	ID        string
+	AccountID string
+	SessionID string
+	Items     []Item
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Pricing   Pricing
+}
+
+// Item is a line of a cart. VariantID is set for products with variants.
+type Item struct {
+	ProductID string
+	VariantID string
+	Quantity  uint32
+	AddedAt   time.Time
+	Details   ItemDetails
+}
+
+func (c *Cart) item(productID, variantID string) *Item {
+	for i := range c.Items {
+		if c.Items[i].ProductID == productID && c.Items[i].VariantID == variantID {
+			return &c.Items[i]
+		}
+	}
+	return nil
+}
+
+// mergeItems adds the items of a session's cart to those of an account's
+// cart. Quantities of the same item are summed up to maxQuantity. Items only
+// the session has are added in the order they were added to it, as long as
+// the cart holds fewer than maxItems; the rest are dropped.
+func mergeItems(account, session []Item, maxItems int, maxQuantity uint32) []Item {
+	merged := &Cart{Items: append([]Item{}, account...)}
+	for _, item := range session {
+		if existing := merged.item(item.ProductID, item.VariantID); existing != nil {
+			existing.Quantity = min(existing.Quantity+item.Quantity, maxQuantity)
+			continue
+		}
+		if len(merged.Items) >= maxItems {
+			continue
+		}
+		item.Quantity = min(item.Quantity, maxQuantity)
+		merged.Items = append(merged.Items, item)
+	}
+	return merged.Items
+}
+
+type cartService struct {
+	repository Repository
+}
+
+func NewService(r Repository) Service {
+	return &cartService{r}
+}
+
+// GetCart returns the cart of owner, which is empty if nothing was added to
+// it yet.
+func (s *cartService) GetCart(ctx context.Context, owner Owner) (*Cart, error) {
+	c, err := s.cart(ctx, owner)
+	if err == ErrCartNotFound && !isSession(owner) {
+		return &Cart{AccountID: owner.AccountID, Items: []Item{}}, nil
+	}
+	return c, err
+}
+
+// AddItem adds item.Quantity of a product, or of one of its variants, to the
+// cart of owner. The cart is created if there is none.
+func (s *cartService) AddItem(ctx context.Context, owner Owner, item Item) (*Cart, error) {
+	if item.ProductID == "" {
+		return nil, ErrInvalidItem
+	}
+	if item.Quantity == 0 || item.Quantity > MaxQuantity {
+		return nil, ErrInvalidQuantity
+	}
+	c, err := s.cartForUpdate(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repository.AddItem(ctx, c.ID, item, MaxItems, MaxQuantity); err != nil {
+		return nil, err
+	}
+	return s.repository.GetCartByID(ctx, c.ID)
+}
+
+// UpdateItem sets the quantity of an item of the cart of owner. A zero
+// quantity removes the item.
+func (s *cartService) UpdateItem(ctx context.Context, owner Owner, item Item) (*Cart, error) {
+	if item.ProductID == "" {
+		return nil, ErrInvalidItem
+	}
+	if item.Quantity > MaxQuantity {
+		return nil, ErrInvalidQuantity
+	}
+	c, err := s.cart(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	existing := c.item(item.ProductID, item.VariantID)
+	switch {
+	case existing == nil && item.Quantity == 0:
+		return c, nil
+	case existing == nil && len(c.Items) >= MaxItems:
+		return nil, ErrCartFull
+	case item.Quantity == 0:
+		err = s.repository.DeleteItem(ctx, c.ID, item.ProductID, item.VariantID)
+	default:
+		err = s.repository.PutItem(ctx, c.ID, item)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.repository.GetCartByID(ctx, c.ID)
+}
+
+func (s *cartService) ClearCart(ctx context.Context, owner Owner) (*Cart, error) {
+	c, err := s.GetCart(ctx, owner)
+	if err != nil || c.ID == "" {
+		return c, err
+	}
+	if err := s.repository.ClearCart(ctx, c.ID, time.Time{}); err != nil {
+		return nil, err
+	}
+	return s.repository.GetCartByID(ctx, c.ID)
+}
+
+// CompleteCheckout empties c after it was ordered, unless it was changed
+// while the order was placed.
+func (s *cartService) CompleteCheckout(ctx context.Context, c *Cart) error {
+	return s.repository.ClearCart(ctx, c.ID, c.UpdatedAt)
+}
+
+// DeleteStaleCarts deletes the anonymous carts that were not changed for
+// SessionTTL. Carts of accounts are kept.
+func (s *cartService) DeleteStaleCarts(ctx context.Context) (int, error) {
+	return s.repository.DeleteSessionCarts(ctx, time.Now().UTC().Add(-SessionTTL))
+}
+
+// cart returns the existing cart of owner, after moving the items of the
+// owner's session cart into the account's cart if it names both.
+func (s *cartService) cart(ctx context.Context, owner Owner) (*Cart, error) {
+	if owner.AccountID == "" {
+		if owner.SessionID == "" {
+			return nil, ErrCartNotFound
+		}
+		return s.repository.GetCart(ctx, owner)
+	}
+	if owner.SessionID != "" {
+		// A session that is gone was most likely merged already.
+		err := s.repository.MergeSessionCart(ctx, owner.SessionID, owner.AccountID, MaxItems, MaxQuantity)
+		if err != nil && err != ErrCartNotFound {
+			return nil, err
+		}
+	}
+	return s.repository.GetCart(ctx, Owner{AccountID: owner.AccountID})
+}
+
+// cartForUpdate returns the cart of owner, creating it if there is none.
+// Anonymous owners without a session get a new one; unknown sessions are an
+// error.
+func (s *cartService) cartForUpdate(ctx context.Context, owner Owner) (*Cart, error) {
+	c, err := s.cart(ctx, owner)
+	if err != ErrCartNotFound || isSession(owner) {
+		return c, err
+	}
+
+	now := time.Now().UTC()
+	c = &Cart{
+		ID:        ksuid.New().String(),
+		AccountID: owner.AccountID,
+		Items:     []Item{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if c.AccountID == "" {
+		if c.SessionID, err = newSessionID(); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.repository.PutCart(ctx, c); err != nil {
+		return nil, err
+	}
+	// Another request may have created the account's cart first.
+	return s.repository.GetCart(ctx, Owner{AccountID: c.AccountID, SessionID: c.SessionID})
+}
+
+// isSession reports whether owner is an anonymous session.
+func isSession(owner Owner) bool {
+	return owner.AccountID == "" && owner.SessionID != ""
+}
+
+// newSessionID returns an unguessable session ID; knowing it is all it
+// takes to use an anonymous cart.
+func newSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,86 @@
+package cart
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeItems(t *testing.T) {
+	item := func(productID, variantID string, quantity uint32) Item {
+		return Item{ProductID: productID, VariantID: variantID, Quantity: quantity}
+	}
+	tests := []struct {
+		name     string
+		account  []Item
+		session  []Item
+		maxItems int
+		want     []Item
+	}{
+		{
+			name:     "empty session",
+			account:  []Item{item("a", "", 1)},
+			maxItems: 10,
+			want:     []Item{item("a", "", 1)},
+		},
+		{
+			name:     "empty account",
+			session:  []Item{item("a", "", 1), item("b", "", 2)},
+			maxItems: 10,
+			want:     []Item{item("a", "", 1), item("b", "", 2)},
+		},
+		{
+			name:     "same item summed",
+			account:  []Item{item("a", "", 1)},
+			session:  []Item{item("a", "", 2)},
+			maxItems: 10,
+			want:     []Item{item("a", "", 3)},
+		},
+		{
+			name:     "variants are different items",
+			account:  []Item{item("a", "red", 1)},
+			session:  []Item{item("a", "blue", 2)},
+			maxItems: 10,
+			want:     []Item{item("a", "red", 1), item("a", "blue", 2)},
+		},
+		{
+			name:     "sum capped at max quantity",
+			account:  []Item{item("a", "", 800)},
+			session:  []Item{item("a", "", 300)},
+			maxItems: 10,
+			want:     []Item{item("a", "", MaxQuantity)},
+		},
+		{
+			name:     "new items dropped from full cart",
+			account:  []Item{item("a", "", 1), item("b", "", 1)},
+			session:  []Item{item("c", "", 1), item("b", "", 2)},
+			maxItems: 2,
+			want:     []Item{item("a", "", 1), item("b", "", 3)},
+		},
+		{
+			name:     "new items added until full",
+			account:  []Item{item("a", "", 1)},
+			session:  []Item{item("b", "", 1), item("c", "", 1)},
+			maxItems: 2,
+			want:     []Item{item("a", "", 1), item("b", "", 1)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mergeItems(tt.account, tt.session, tt.maxItems, MaxQuantity)
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mergeItems() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeItemsKeepsAccountItems(t *testing.T) {
+	account := []Item{{ProductID: "a", Quantity: 1}}
+	mergeItems(account, []Item{{ProductID: "a", Quantity: 2}}, MaxItems, MaxQuantity)
+	if account[0].Quantity != 1 {
+		t.Errorf("account item quantity = %d, want 1", account[0].Quantity)
+	}
+}
@@ -0,0 +1,23 @@
+-- A cart belongs to an account or, while its owner is anonymous, to a
+-- session. Items only keep what was chosen; names, prices and stock are
+-- looked up in the catalog whenever the cart is read.
+CREATE TABLE IF NOT EXISTS carts (
+    id VARCHAR(36) PRIMARY KEY,
+    account_id VARCHAR(36) UNIQUE,
+    session_id CHAR(32) UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((account_id IS NULL) <> (session_id IS NULL))
+);
+
+-- Stale session carts are deleted by age.
+CREATE INDEX IF NOT EXISTS carts_session_updated_at_idx ON carts (updated_at) WHERE account_id IS NULL;
+
+CREATE TABLE IF NOT EXISTS cart_items (
+    cart_id VARCHAR(36) NOT NULL REFERENCES carts(id) ON DELETE CASCADE,
+    product_id VARCHAR(36) NOT NULL,
+    variant_id VARCHAR(36) NOT NULL DEFAULT '',
+    quantity INT NOT NULL CHECK (quantity > 0),
+    added_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (cart_id, product_id, variant_id)
+);
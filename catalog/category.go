@@ -0,0 +1,43 @@
+package catalog
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidCategory = errors.New("category must have a name")
+	ErrUnknownCategory = errors.New("unknown category")
+	ErrCategoryCycle   = errors.New("category cannot be moved below itself")
+)
+
+// Category is a node of the category tree. Path is the materialized path of
+// the category: the IDs of its ancestors and its own, each followed by a
+// slash, e.g. "/electronics/computers/". A category and all of its
+// descendants are the categories whose path starts with its path.
+type Category struct {
+	ID        string    `json:"-"`
+	Name      string    `json:"name"`
+	ParentID  string    `json:"parent_id,omitempty"`
+	Path      string    `json:"path"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func categoryPath(parent *Category, id string) string {
+	if parent == nil {
+		return "/" + id + "/"
+	}
+	return parent.Path + id + "/"
+}
+
+// AncestorIDs returns the IDs of the categories above c, root first.
+func (c *Category) AncestorIDs() []string {
+	ids := strings.Split(strings.Trim(c.Path, "/"), "/")
+	return ids[:len(ids)-1]
+}
+
+// Contains reports whether other is c or one of its descendants.
+func (c *Category) Contains(other *Category) bool {
+	return strings.HasPrefix(other.Path, c.Path)
+}
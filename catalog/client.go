@@ -2,9 +2,13 @@ package catalog
 
 import (
 	"context"
+	"io"
+	"time"
 
 	"github.com/suryanshp1/go-microservice/catalog/pb"
+	"github.com/suryanshp1/go-microservice/money"
 	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
 type Client struct {
@@ -25,13 +29,27 @@ func (c *Client) Close() {
 	c.conn.Close()
 }
 
-func (c *Client) PostProduct(ctx context.Context, name, description string, price float64) (*Product, error) {
+func (c *Client) PostProduct(
+	ctx context.Context,
+	name, description string,
+	price money.Money,
+	categories []string,
+	options []ProductOption,
+	variants []Variant,
+) (*Product, error) {
+	protoVariants := []*pb.Variant{}
+	for _, v := range variants {
+		protoVariants = append(protoVariants, variantToProto(v))
+	}
 	r, err := c.service.PostProduct(
 		ctx,
 		&pb.PostProductRequest{
 			Name:        name,
 			Description: description,
-			Price:       price,
+			Price:       moneyToProto(price),
+			Categories:  categories,
+			Options:     optionsToProto(options),
+			Variants:    protoVariants,
 		},
 	)
 
@@ -39,19 +57,64 @@ func (c *Client) PostProduct(ctx context.Context, name, description string, pric
 		return nil, err
 	}
 
-	return &Product{
-		ID:          r.Product.Id,
-		Name:        r.Product.Name,
-		Description: r.Product.Description,
-		Price:       r.Product.Price,
-	}, nil
+	return productFromProto(r.Product), nil
+}
+
+// UpdateProduct changes the fields set in update. An empty version skips the
+// concurrency check.
+func (c *Client) UpdateProduct(ctx context.Context, id string, update ProductUpdate, version string) (*Product, error) {
+	req := &pb.UpdateProductRequest{
+		Id:         id,
+		UpdateMask: &fieldmaskpb.FieldMask{},
+		Version:    version,
+	}
+	if update.Name != nil {
+		req.Name = *update.Name
+		req.UpdateMask.Paths = append(req.UpdateMask.Paths, "name")
+	}
+	if update.Description != nil {
+		req.Description = *update.Description
+		req.UpdateMask.Paths = append(req.UpdateMask.Paths, "description")
+	}
+	if update.Price != nil {
+		req.Price = moneyToProto(*update.Price)
+		req.UpdateMask.Paths = append(req.UpdateMask.Paths, "price")
+	}
+	if update.Categories != nil {
+		req.Categories = *update.Categories
+		req.UpdateMask.Paths = append(req.UpdateMask.Paths, "categories")
+	}
+	if update.Options != nil {
+		req.Options = optionsToProto(*update.Options)
+		req.UpdateMask.Paths = append(req.UpdateMask.Paths, "options")
+	}
+
+	r, err := c.service.UpdateProduct(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return productFromProto(r.Product), nil
+}
+
+func (c *Client) DeleteProduct(ctx context.Context, id string, version string) (*Product, error) {
+	r, err := c.service.DeleteProduct(ctx, &pb.DeleteProductRequest{
+		Id:      id,
+		Version: version,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return productFromProto(r.Product), nil
 }
 
-func (c *Client) GetProduct(ctx context.Context, id string) (*Product, error) {
+// GetProduct returns the product with the given ID. A non-empty currency
+// converts its prices with the catalog's exchange rates.
+func (c *Client) GetProduct(ctx context.Context, id string, currency string) (*Product, error) {
 	r, err := c.service.GetProduct(
 		ctx,
 		&pb.GetProductRequest{
-			Id: id,
+			Id:       id,
+			Currency: currency,
 		},
 	)
 
@@ -59,22 +122,21 @@ func (c *Client) GetProduct(ctx context.Context, id string) (*Product, error) {
 		return nil, err
 	}
 
-	return &Product{
-		ID:          r.Product.Id,
-		Name:        r.Product.Name,
-		Description: r.Product.Description,
-		Price:       r.Product.Price,
-	}, nil
+	return productFromProto(r.Product), nil
 }
 
-func (c *Client) GetProducts(ctx context.Context, skip uint64, take uint64, ids []string, query string) ([]*Product, error) {
+// GetProducts returns the products with the given IDs, or those matching
+// query. A non-empty currency converts their prices with the catalog's
+// exchange rates.
+func (c *Client) GetProducts(ctx context.Context, skip uint64, take uint64, ids []string, query string, currency string) ([]*Product, error) {
 	r, err := c.service.GetProducts(
 		ctx,
 		&pb.GetProductsRequest{
-			Skip:  skip,
-			Take:  take,
-			Ids:   ids,
-			Query: query,
+			Skip:     skip,
+			Take:     take,
+			Ids:      ids,
+			Query:    query,
+			Currency: currency,
 		},
 	)
 
@@ -84,13 +146,433 @@ func (c *Client) GetProducts(ctx context.Context, skip uint64, take uint64, ids
 
 	var products []*Product
 	for _, p := range r.Products {
-		products = append(products, &Product{
-			ID:          p.Id,
-			Name:        p.Name,
-			Description: p.Description,
-			Price:       p.Price,
-		})
+		products = append(products, productFromProto(p))
 	}
 
 	return products, nil
 }
+
+// SearchProducts returns a page of products matching q together with the
+// total number of matches and their facets.
+func (c *Client) SearchProducts(ctx context.Context, q SearchQuery) (*SearchResult, error) {
+	r, err := c.service.GetProducts(ctx, &pb.GetProductsRequest{
+		Skip:          q.Skip,
+		Take:          q.Take,
+		Query:         q.Query,
+		MinPrice:      optionalMoneyToProto(q.MinPrice),
+		MaxPrice:      optionalMoneyToProto(q.MaxPrice),
+		Categories:    q.Categories,
+		Sort:          string(q.Sort),
+		PriceInterval: moneyToProto(q.PriceInterval),
+		After:         q.After,
+		Currency:      q.Currency,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SearchResult{
+		Products:    make([]*Product, 0, len(r.Products)),
+		Cursors:     r.Cursors,
+		Total:       r.Total,
+		EndCursor:   r.EndCursor,
+		HasNextPage: r.HasNextPage,
+		Facets: Facets{
+			Prices:     []PriceBucket{},
+			Categories: []CategoryCount{},
+		},
+	}
+	for _, p := range r.Products {
+		result.Products = append(result.Products, productFromProto(p))
+	}
+	if result.Cursors == nil {
+		result.Cursors = []string{}
+	}
+	for _, b := range r.Facets.GetPrices() {
+		result.Facets.Prices = append(result.Facets.Prices, PriceBucket{
+			From:  moneyFromProto(b.From),
+			To:    moneyFromProto(b.To),
+			Count: b.Count,
+		})
+	}
+	for _, c := range r.Facets.GetCategories() {
+		result.Facets.Categories = append(result.Facets.Categories, CategoryCount{Category: c.Category, Count: c.Count})
+	}
+	return result, nil
+}
+
+// SuggestProducts returns type-ahead suggestions for prefix. A zero limit
+// uses the server's default.
+func (c *Client) SuggestProducts(ctx context.Context, prefix string, limit int) (*Suggestions, error) {
+	r, err := c.service.SuggestProducts(ctx, &pb.SuggestProductsRequest{Prefix: prefix, Limit: uint32(limit)})
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := &Suggestions{
+		Products:    []ProductSuggestion{},
+		Categories:  []CategorySuggestion{},
+		Corrections: r.Corrections,
+	}
+	if suggestions.Corrections == nil {
+		suggestions.Corrections = []string{}
+	}
+	for _, p := range r.Products {
+		suggestions.Products = append(suggestions.Products, ProductSuggestion{ProductID: p.ProductId, Name: p.Name})
+	}
+	for _, cs := range r.Categories {
+		suggestions.Categories = append(suggestions.Categories, CategorySuggestion{
+			CategoryID: cs.CategoryId,
+			Name:       cs.Name,
+			Count:      cs.Count,
+		})
+	}
+	return suggestions, nil
+}
+
+// AddVariant adds a variant to a product. An empty version skips the
+// concurrency check.
+// ImportProducts streams the rows returned by next to the catalog until next
+// returns io.EOF.
+func (c *Client) ImportProducts(ctx context.Context, next func() (*ImportRow, error)) (*ImportSummary, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// The response holds a result for every row.
+	stream, err := c.service.ImportProducts(ctx, grpc.MaxCallRecvMsgSize(64<<20))
+	if err != nil {
+		return nil, err
+	}
+	for {
+		row, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		err = stream.Send(&pb.ImportProductsRequest{
+			ExternalSku: row.ExternalSKU,
+			Name:        row.Name,
+			Description: row.Description,
+			Price:       moneyToProto(row.Price),
+			Categories:  row.Categories,
+		})
+		if err == io.EOF {
+			// The server ended the stream; CloseAndRecv returns why.
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	r, err := stream.CloseAndRecv()
+	if err != nil {
+		return nil, err
+	}
+	summary := &ImportSummary{
+		Created: int(r.Created),
+		Updated: int(r.Updated),
+		Failed:  int(r.Failed),
+	}
+	for _, res := range r.Results {
+		summary.Results = append(summary.Results, ImportResult{
+			Row:       int(res.Row),
+			ProductID: res.ProductId,
+			Created:   res.Created,
+			Error:     res.Error,
+		})
+	}
+	return summary, nil
+}
+
+// ExportProducts calls fn for every product matching the query, price range
+// and categories of q.
+func (c *Client) ExportProducts(ctx context.Context, q SearchQuery, fn func(*Product) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := c.service.ExportProducts(ctx, &pb.ExportProductsRequest{
+		Query:      q.Query,
+		MinPrice:   optionalMoneyToProto(q.MinPrice),
+		MaxPrice:   optionalMoneyToProto(q.MaxPrice),
+		Categories: q.Categories,
+	})
+	if err != nil {
+		return err
+	}
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for _, p := range res.Products {
+			if err := fn(productFromProto(p)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (c *Client) AddVariant(ctx context.Context, productID string, v Variant, version string) (*Product, error) {
+	r, err := c.service.AddVariant(ctx, &pb.AddVariantRequest{
+		ProductId: productID,
+		Variant:   variantToProto(v),
+		Version:   version,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return productFromProto(r.Product), nil
+}
+
+// UpdateVariant replaces the SKU, option values and price of the variant
+// with v's ID.
+func (c *Client) UpdateVariant(ctx context.Context, productID string, v Variant, version string) (*Product, error) {
+	r, err := c.service.UpdateVariant(ctx, &pb.UpdateVariantRequest{
+		ProductId: productID,
+		Variant:   variantToProto(v),
+		Version:   version,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return productFromProto(r.Product), nil
+}
+
+func (c *Client) RemoveVariant(ctx context.Context, productID, variantID, version string) (*Product, error) {
+	r, err := c.service.RemoveVariant(ctx, &pb.RemoveVariantRequest{
+		ProductId: productID,
+		VariantId: variantID,
+		Version:   version,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return productFromProto(r.Product), nil
+}
+
+// AdjustStock changes the stock of a product, or of one of its variants if
+// variantID is set.
+func (c *Client) AdjustStock(ctx context.Context, productID, variantID string, delta int64) (*Product, error) {
+	r, err := c.service.AdjustStock(ctx, &pb.AdjustStockRequest{
+		ProductId: productID,
+		VariantId: variantID,
+		Delta:     delta,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return productFromProto(r.Product), nil
+}
+
+// ReserveStock holds stock for lines until ttl elapses. A zero ttl uses the
+// server's default.
+func (c *Client) ReserveStock(ctx context.Context, lines []ReservationLine, ttl time.Duration) (*Reservation, error) {
+	protoLines := []*pb.Reservation_Line{}
+	for _, l := range lines {
+		protoLines = append(protoLines, &pb.Reservation_Line{ProductId: l.ProductID, VariantId: l.VariantID, Quantity: l.Quantity})
+	}
+	r, err := c.service.ReserveStock(ctx, &pb.ReserveStockRequest{
+		Lines:      protoLines,
+		TtlSeconds: uint32(ttl / time.Second),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reservationFromProto(r.Reservation), nil
+}
+
+func (c *Client) CommitReservation(ctx context.Context, id string) (*Reservation, error) {
+	r, err := c.service.CommitReservation(ctx, &pb.CommitReservationRequest{ReservationId: id})
+	if err != nil {
+		return nil, err
+	}
+	return reservationFromProto(r.Reservation), nil
+}
+
+func (c *Client) ReleaseReservation(ctx context.Context, id string) (*Reservation, error) {
+	r, err := c.service.ReleaseReservation(ctx, &pb.ReleaseReservationRequest{ReservationId: id})
+	if err != nil {
+		return nil, err
+	}
+	return reservationFromProto(r.Reservation), nil
+}
+
+func (c *Client) CreateCategory(ctx context.Context, name, parentID string) (*Category, error) {
+	r, err := c.service.CreateCategory(ctx, &pb.CreateCategoryRequest{Name: name, ParentId: parentID})
+	if err != nil {
+		return nil, err
+	}
+	return categoryFromProto(r.Category), nil
+}
+
+func (c *Client) RenameCategory(ctx context.Context, id, name string) (*Category, error) {
+	r, err := c.service.RenameCategory(ctx, &pb.RenameCategoryRequest{Id: id, Name: name})
+	if err != nil {
+		return nil, err
+	}
+	return categoryFromProto(r.Category), nil
+}
+
+// MoveCategory moves a category below parentID, or to the top of the tree if
+// parentID is empty.
+func (c *Client) MoveCategory(ctx context.Context, id, parentID string) (*Category, error) {
+	r, err := c.service.MoveCategory(ctx, &pb.MoveCategoryRequest{Id: id, ParentId: parentID})
+	if err != nil {
+		return nil, err
+	}
+	return categoryFromProto(r.Category), nil
+}
+
+func (c *Client) GetCategory(ctx context.Context, id string) (*Category, error) {
+	r, err := c.service.GetCategory(ctx, &pb.GetCategoryRequest{Id: id})
+	if err != nil {
+		return nil, err
+	}
+	return categoryFromProto(r.Category), nil
+}
+
+// GetCategories returns the children of parentID, or the root categories if
+// parentID is empty.
+func (c *Client) GetCategories(ctx context.Context, parentID string) ([]*Category, error) {
+	r, err := c.service.GetCategories(ctx, &pb.GetCategoriesRequest{ParentId: parentID})
+	if err != nil {
+		return nil, err
+	}
+	categories := make([]*Category, 0, len(r.Categories))
+	for _, cp := range r.Categories {
+		categories = append(categories, categoryFromProto(cp))
+	}
+	return categories, nil
+}
+
+// SetExchangeRate stores the rate at which one unit of from converts to to,
+// a decimal such as "0.92", replacing the previous one.
+func (c *Client) SetExchangeRate(ctx context.Context, from, to, rate string) (*ExchangeRate, error) {
+	r, err := c.service.SetExchangeRate(ctx, &pb.SetExchangeRateRequest{From: from, To: to, Rate: rate})
+	if err != nil {
+		return nil, err
+	}
+	return exchangeRateFromProto(r.ExchangeRate), nil
+}
+
+func (c *Client) GetExchangeRates(ctx context.Context) ([]*ExchangeRate, error) {
+	r, err := c.service.GetExchangeRates(ctx, &pb.GetExchangeRatesRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	rates := make([]*ExchangeRate, 0, len(r.ExchangeRates))
+	for _, rp := range r.ExchangeRates {
+		rates = append(rates, exchangeRateFromProto(rp))
+	}
+	return rates, nil
+}
+
+func productFromProto(p *pb.Product) *Product {
+	product := &Product{
+		ID:          p.Id,
+		Name:        p.Name,
+		Description: p.Description,
+		Price:       moneyFromProto(p.Price),
+		Stock:       p.Stock,
+		Reserved:    p.Stock - p.Available,
+		Categories:  p.Categories,
+		Options:     optionsFromProto(p.Options),
+		Variants:    []Variant{},
+		ExternalSKU: p.ExternalSku,
+		Version:     p.Version,
+	}
+	if product.Categories == nil {
+		product.Categories = []string{}
+	}
+	for _, v := range p.Variants {
+		product.Variants = append(product.Variants, variantFromProto(v))
+	}
+	if len(p.CreatedAt) > 0 {
+		product.CreatedAt.UnmarshalBinary(p.CreatedAt)
+	}
+	if len(p.DeletedAt) > 0 {
+		deletedAt := time.Time{}
+		deletedAt.UnmarshalBinary(p.DeletedAt)
+		product.DeletedAt = &deletedAt
+	}
+	if p.ExchangeRate != nil {
+		product.ExchangeRate = exchangeRateFromProto(p.ExchangeRate)
+	}
+	return product
+}
+
+func exchangeRateFromProto(rp *pb.ExchangeRate) *ExchangeRate {
+	r := &ExchangeRate{Rate: money.Rate{From: rp.From, To: rp.To, Value: rp.Rate}}
+	r.UpdatedAt.UnmarshalBinary(rp.UpdatedAt)
+	return r
+}
+
+func reservationFromProto(rp *pb.Reservation) *Reservation {
+	r := &Reservation{
+		ID:        rp.Id,
+		AccountID: rp.AccountId,
+		Status:    ReservationStatus(rp.Status),
+	}
+	for _, l := range rp.Lines {
+		r.Lines = append(r.Lines, ReservationLine{ProductID: l.ProductId, VariantID: l.VariantId, Quantity: l.Quantity})
+	}
+	r.CreatedAt.UnmarshalBinary(rp.CreatedAt)
+	r.ExpiresAt.UnmarshalBinary(rp.ExpiresAt)
+	return r
+}
+
+func categoryFromProto(cp *pb.Category) *Category {
+	c := &Category{
+		ID:       cp.Id,
+		Name:     cp.Name,
+		ParentID: cp.ParentId,
+		Path:     cp.Path,
+	}
+	c.CreatedAt.UnmarshalBinary(cp.CreatedAt)
+	return c
+}
+
+func optionsFromProto(po []*pb.ProductOption) []ProductOption {
+	options := []ProductOption{}
+	for _, o := range po {
+		options = append(options, ProductOption{Name: o.Name, Values: o.Values})
+	}
+	return options
+}
+
+func moneyFromProto(pm *pb.Money) money.Money {
+	if pm == nil {
+		return money.Money{}
+	}
+	return money.New(pm.Amount, pm.Currency)
+}
+
+func optionalMoneyFromProto(pm *pb.Money) *money.Money {
+	if pm == nil {
+		return nil
+	}
+	m := moneyFromProto(pm)
+	return &m
+}
+
+func variantFromProto(pv *pb.Variant) Variant {
+	v := Variant{
+		ID:       pv.Id,
+		SKU:      pv.Sku,
+		Options:  []OptionValue{},
+		Price:    optionalMoneyFromProto(pv.Price),
+		Stock:    pv.Stock,
+		Reserved: pv.Stock - pv.Available,
+	}
+	for _, o := range pv.Options {
+		v.Options = append(v.Options, OptionValue{Name: o.Name, Value: o.Value})
+	}
+	return v
+}
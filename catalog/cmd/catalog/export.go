@@ -0,0 +1,277 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/suryanshp1/go-microservice/catalog"
+	"github.com/suryanshp1/go-microservice/money"
+	"google.golang.org/grpc/metadata"
+)
+
+// exportProducts writes the products of a running catalog service to a file,
+// or to standard output, as JSON Lines, CSV or a Google Shopping XML feed.
+// The format follows the file extension unless --format is given. CSV
+// exports can be imported again with `catalog import`.
+func exportProducts(cfg Config, args []string) {
+	var q catalog.SearchQuery
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	output := flags.String("output", "-", "file to write to, - for standard output")
+	format := flags.String("format", "", "jsonl, csv or xml")
+	flags.StringVar(&q.Query, "query", "", "only export products matching the text")
+	flags.Func("min-price", "only export products costing at least this much, e.g. \"10.00 USD\"", priceFlag(&q.MinPrice))
+	flags.Func("max-price", "only export products costing at most this much, e.g. \"99.99 USD\"", priceFlag(&q.MaxPrice))
+	categories := flags.String("categories", "", "only export products in these comma-separated categories or below")
+	link := flags.String("link", "", "product page URL for the XML feed, {id} is replaced by the product ID")
+	title := flags.String("title", "Catalog", "title of the XML feed")
+	flags.Parse(args)
+
+	for _, c := range strings.Split(*categories, ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			q.Categories = append(q.Categories, c)
+		}
+	}
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(*output)), ".")
+		if *format == "" {
+			*format = "jsonl"
+		}
+	}
+
+	out := os.Stdout
+	if *output != "-" {
+		f, err := os.Create(*output)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		out = f
+	}
+	buf := bufio.NewWriter(out)
+
+	var w productWriter
+	switch *format {
+	case "jsonl":
+		w = &jsonlWriter{encoder: json.NewEncoder(buf)}
+	case "csv":
+		w = newCSVWriter(buf)
+	case "xml":
+		w = newFeedWriter(buf, *title, *link)
+	default:
+		log.Fatalf("unsupported format %q, expected jsonl, csv or xml", *format)
+	}
+
+	client, err := catalog.NewClient(cfg.catalogURL())
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	if cfg.AccessToken != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+cfg.AccessToken)
+	}
+	n := 0
+	err = client.ExportProducts(ctx, q, func(p *catalog.Product) error {
+		n++
+		return w.Write(p)
+	})
+	if err == nil {
+		err = w.Close()
+	}
+	if err == nil {
+		err = buf.Flush()
+	}
+	if err != nil {
+		log.Fatalf("Error exporting products: %v", err)
+	}
+	log.Printf("Exported %d products", n)
+}
+
+func priceFlag(price **money.Money) func(string) error {
+	return func(s string) error {
+		m, err := money.Parse(s)
+		if err != nil {
+			return err
+		}
+		*price = &m
+		return nil
+	}
+}
+
+type productWriter interface {
+	Write(p *catalog.Product) error
+	// Close writes whatever has to follow the last product.
+	Close() error
+}
+
+type jsonlWriter struct {
+	encoder *json.Encoder
+}
+
+func (w *jsonlWriter) Write(p *catalog.Product) error {
+	return w.encoder.Encode(p)
+}
+
+func (w *jsonlWriter) Close() error {
+	return nil
+}
+
+// csvWriter writes a product per row. Its columns include those read by
+// `catalog import`.
+type csvWriter struct {
+	writer *csv.Writer
+	header bool
+}
+
+func newCSVWriter(out io.Writer) *csvWriter {
+	return &csvWriter{writer: csv.NewWriter(out)}
+}
+
+func (w *csvWriter) writeHeader() error {
+	w.header = true
+	return w.writer.Write([]string{
+		"id", "external_sku", "name", "description", "price", "currency", "categories", "stock", "available", "created_at",
+	})
+}
+
+func (w *csvWriter) Write(p *catalog.Product) error {
+	if !w.header {
+		if err := w.writeHeader(); err != nil {
+			return err
+		}
+	}
+	createdAt := ""
+	if !p.CreatedAt.IsZero() {
+		createdAt = p.CreatedAt.Format(time.RFC3339)
+	}
+	return w.writer.Write([]string{
+		p.ID,
+		p.ExternalSKU,
+		p.Name,
+		p.Description,
+		p.Price.Decimal(),
+		p.Price.Currency,
+		strings.Join(p.Categories, "|"),
+		strconv.FormatInt(p.Stock, 10),
+		strconv.FormatInt(p.Available(), 10),
+		createdAt,
+	})
+}
+
+func (w *csvWriter) Close() error {
+	if !w.header {
+		if err := w.writeHeader(); err != nil {
+			return err
+		}
+	}
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// feedWriter writes an RSS feed in the format of Google Merchant Center
+// product feeds. Products with variants become one item per variant,
+// grouped by the product ID.
+type feedWriter struct {
+	out     io.Writer
+	encoder *xml.Encoder
+	title   string
+	link    string
+	started bool
+}
+
+type feedItem struct {
+	XMLName      xml.Name `xml:"item"`
+	ID           string   `xml:"g:id"`
+	ItemGroupID  string   `xml:"g:item_group_id,omitempty"`
+	Title        string   `xml:"title"`
+	Description  string   `xml:"description"`
+	Link         string   `xml:"link,omitempty"`
+	Price        string   `xml:"g:price"`
+	Availability string   `xml:"g:availability"`
+}
+
+func newFeedWriter(out io.Writer, title, link string) *feedWriter {
+	encoder := xml.NewEncoder(out)
+	encoder.Indent("    ", "  ")
+	return &feedWriter{out: out, encoder: encoder, title: title, link: link}
+}
+
+func (w *feedWriter) start() error {
+	w.started = true
+	_, err := io.WriteString(w.out, xml.Header+"<rss version=\"2.0\" xmlns:g=\"http://base.google.com/ns/1.0\">\n  <channel>\n    <title>")
+	if err == nil {
+		err = xml.EscapeText(w.out, []byte(w.title))
+	}
+	if err == nil {
+		_, err = io.WriteString(w.out, "</title>\n")
+	}
+	return err
+}
+
+func (w *feedWriter) Write(p *catalog.Product) error {
+	if !w.started {
+		if err := w.start(); err != nil {
+			return err
+		}
+	}
+
+	item := feedItem{
+		ID:          p.ID,
+		Title:       p.Name,
+		Description: p.Description,
+		Link:        strings.ReplaceAll(w.link, "{id}", p.ID),
+	}
+	if len(p.Variants) == 0 {
+		item.Price = p.Price.String()
+		item.Availability = availability(p.Available())
+		return w.encoder.Encode(item)
+	}
+	for _, v := range p.Variants {
+		values := []string{}
+		for _, o := range v.Options {
+			values = append(values, o.Value)
+		}
+		item.ID = v.SKU
+		item.ItemGroupID = p.ID
+		item.Title = fmt.Sprintf("%s (%s)", p.Name, strings.Join(values, ", "))
+		item.Price = p.PriceOf(v.ID).String()
+		item.Availability = availability(v.Available())
+		if err := w.encoder.Encode(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func availability(available int64) string {
+	if available > 0 {
+		return "in_stock"
+	}
+	return "out_of_stock"
+}
+
+func (w *feedWriter) Close() error {
+	if !w.started {
+		if err := w.start(); err != nil {
+			return err
+		}
+	}
+	if err := w.encoder.Flush(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w.out, "\n  </channel>\n</rss>\n")
+	return err
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/suryanshp1/go-microservice/catalog"
+	"github.com/suryanshp1/go-microservice/money"
+	"google.golang.org/grpc/metadata"
+)
+
+// importProducts streams the products of a CSV or JSON Lines file to a
+// running catalog service. CSV files need a header naming their columns:
+// external_sku, name, description, price, currency and categories, the
+// latter separated by "|". Prices are decimals such as 12.34 and are in
+// money.DefaultCurrency if there is no currency. JSON Lines files hold one
+// object per line with the same fields, categories being an array. Products
+// are matched by external_sku, so a file can be imported again to update
+// them.
+func importProducts(cfg Config, args []string) {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	file := flags.String("file", "", "products to import, a .csv or .jsonl file")
+	flags.Parse(args)
+	if *file == "" {
+		log.Fatal("usage: catalog import --file products.csv|products.jsonl")
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	var next func() (*catalog.ImportRow, error)
+	switch strings.ToLower(filepath.Ext(*file)) {
+	case ".csv":
+		next, err = csvRows(f)
+	case ".jsonl":
+		next = jsonlRows(f)
+	default:
+		err = fmt.Errorf("unsupported file type %q, expected .csv or .jsonl", filepath.Ext(*file))
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	client, err := catalog.NewClient(cfg.catalogURL())
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	if cfg.AccessToken != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+cfg.AccessToken)
+	}
+	summary, err := client.ImportProducts(ctx, next)
+	if err != nil {
+		log.Fatalf("Error importing products: %v", err)
+	}
+	for _, r := range summary.Results {
+		if r.Error != "" {
+			log.Printf("Row %d: %s", r.Row, r.Error)
+		}
+	}
+	log.Printf("Imported %s: %d created, %d updated, %d failed", *file, summary.Created, summary.Updated, summary.Failed)
+}
+
+func csvRows(r io.Reader) (func() (*catalog.ImportRow, error), error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading CSV header: %w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.TrimSpace(strings.ToLower(name))] = i
+	}
+	for _, name := range []string{"external_sku", "name", "price"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("CSV header has no %s column", name)
+		}
+	}
+
+	line := 1
+	return func() (*catalog.ImportRow, error) {
+		record, err := reader.Read()
+		if err != nil {
+			return nil, err
+		}
+		line++
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		row := &catalog.ImportRow{
+			ExternalSKU: field("external_sku"),
+			Name:        field("name"),
+			Description: field("description"),
+			Categories:  []string{},
+		}
+		if row.Price, err = parsePrice(field("price"), field("currency")); err != nil {
+			return nil, fmt.Errorf("line %d: invalid price %q", line, field("price"))
+		}
+		for _, c := range strings.Split(field("categories"), "|") {
+			if c = strings.TrimSpace(c); c != "" {
+				row.Categories = append(row.Categories, c)
+			}
+		}
+		return row, nil
+	}, nil
+}
+
+func jsonlRows(r io.Reader) func() (*catalog.ImportRow, error) {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	n := 0
+	return func() (*catalog.ImportRow, error) {
+		n++
+		var row struct {
+			ExternalSKU string      `json:"external_sku"`
+			Name        string      `json:"name"`
+			Description string      `json:"description"`
+			Price       json.Number `json:"price"`
+			Currency    string      `json:"currency"`
+			Categories  []string    `json:"categories"`
+		}
+		if err := decoder.Decode(&row); err != nil {
+			if err == io.EOF {
+				return nil, err
+			}
+			return nil, fmt.Errorf("row %d: %w", n, err)
+		}
+		price, err := parsePrice(row.Price.String(), row.Currency)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: invalid price %q", n, row.Price)
+		}
+		return &catalog.ImportRow{
+			ExternalSKU: row.ExternalSKU,
+			Name:        row.Name,
+			Description: row.Description,
+			Price:       price,
+			Categories:  row.Categories,
+		}, nil
+	}
+}
+
+// parsePrice reads a decimal price exactly, rather than through a float.
+func parsePrice(amount, currency string) (money.Money, error) {
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+	return money.ParseDecimal(amount, strings.ToUpper(currency))
+}
@@ -1,16 +1,32 @@
 package main
 
 import (
+	"context"
 	"log"
+	"os"
 	"time"
 
 	"github.com/kelseyhightower/envconfig"
+	"github.com/suryanshp1/go-microservice/account"
 	"github.com/suryanshp1/go-microservice/catalog"
 	"github.com/tinrab/retry"
 )
 
 type Config struct {
-	DatabaseURL string `envconfig:"DATABASE_URL"`
+	DatabaseURL    string `envconfig:"DATABASE_URL"`
+	AccountURL     string `envconfig:"ACCOUNT_SERVICE_URL"`
+	TokenPublicKey string `envconfig:"TOKEN_PUBLIC_KEY"`
+
+	// Used by the commands that talk to a running catalog service.
+	CatalogURL  string `envconfig:"CATALOG_SERVICE_URL"`
+	AccessToken string `envconfig:"ACCESS_TOKEN"`
+}
+
+func (cfg Config) catalogURL() string {
+	if cfg.CatalogURL == "" {
+		return "localhost:8080"
+	}
+	return cfg.CatalogURL
 }
 
 func main() {
@@ -21,6 +37,25 @@ func main() {
 		log.Fatal(err)
 	}
 
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "reindex":
+			reindex(cfg)
+		case "import":
+			importProducts(cfg, os.Args[2:])
+		case "export":
+			exportProducts(cfg, os.Args[2:])
+		default:
+			log.Fatalf("unknown command %q, expected reindex, import or export", os.Args[1])
+		}
+		return
+	}
+
+	publicKey, err := account.ParsePublicKey(cfg.TokenPublicKey)
+	if err != nil {
+		log.Fatalf("invalid TOKEN_PUBLIC_KEY: %v", err)
+	}
+
 	var r catalog.Repository
 
 	retry.ForeverSleep(2*time.Second, func(_ int) (err error) {
@@ -37,6 +72,18 @@ func main() {
 
 	s := catalog.NewService(r)
 
-	log.Fatal(catalog.ListenGRPC(s, 8080))
+	// Give stock held by abandoned reservations back to the inventory.
+	go func() {
+		for range time.Tick(time.Minute) {
+			n, err := s.ReleaseExpiredReservations(context.Background())
+			if err != nil {
+				log.Println("Error releasing expired reservations:", err)
+			} else if n > 0 {
+				log.Printf("Released %d expired reservations", n)
+			}
+		}
+	}()
+
+	log.Fatal(catalog.ListenGRPC(s, cfg.AccountURL, account.NewTokenVerifier(publicKey), 8080))
 
 }
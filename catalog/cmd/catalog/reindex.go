@@ -0,0 +1,18 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/suryanshp1/go-microservice/catalog"
+)
+
+// reindex rebuilds the catalog index with the current mapping, for example
+// after analyzers changed. The service keeps serving reads meanwhile.
+func reindex(cfg Config) {
+	from, to, err := catalog.Reindex(context.Background(), cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("Error reindexing catalog: %v", err)
+	}
+	log.Printf("Reindexed catalog from %s to %s", from, to)
+}
@@ -0,0 +1,44 @@
+package catalog
+
+import (
+	"errors"
+	"time"
+
+	"github.com/suryanshp1/go-microservice/money"
+)
+
+var (
+	ErrInvalidExchangeRate  = errors.New("exchange rate must convert between two different ISO 4217 currencies at a positive decimal rate")
+	ErrExchangeRateNotFound = errors.New("no exchange rate to the requested currency")
+)
+
+// ExchangeRate is a stored exchange rate. Products are priced in a base
+// currency each and converted with these rates when they are requested in
+// another currency. There is at most one rate per pair of currencies, and
+// rates are not inverted: converting both ways takes two rates.
+type ExchangeRate struct {
+	money.Rate
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// convertPrices converts the price of p and of its variants with r, which
+// must convert from the currency p is priced in.
+func (p *Product) convertPrices(r *ExchangeRate) error {
+	price, err := p.Price.Convert(r.Rate)
+	if err != nil {
+		return err
+	}
+	p.Price = price
+	for i, v := range p.Variants {
+		if v.Price == nil {
+			continue
+		}
+		price, err := v.Price.Convert(r.Rate)
+		if err != nil {
+			return err
+		}
+		p.Variants[i].Price = &price
+	}
+	p.ExchangeRate = r
+	return nil
+}
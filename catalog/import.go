@@ -0,0 +1,68 @@
+package catalog
+
+import (
+	"errors"
+
+	"github.com/suryanshp1/go-microservice/money"
+)
+
+// ImportBatchSize is how many imported products are written per bulk
+// request.
+const ImportBatchSize = 500
+
+var ErrInvalidImportRow = errors.New("imported product must have an external SKU, a name and a non-negative price")
+
+// ImportRow is a product of a supplier catalog. Rows are matched to existing
+// products by ExternalSKU: a product with the same external SKU is updated,
+// otherwise a new one is created. Updates replace the name, description,
+// price and categories and leave stock, options and variants alone.
+type ImportRow struct {
+	ExternalSKU string
+	Name        string
+	Description string
+	Price       money.Money
+	Categories  []string
+}
+
+func (r *ImportRow) validate() error {
+	if r.ExternalSKU == "" || r.Name == "" || !r.Price.Valid() || r.Price.IsNegative() {
+		return ErrInvalidImportRow
+	}
+	return nil
+}
+
+// ImportResult reports what became of one imported row. Row counts from 1 in
+// the order the rows were sent. Rows that failed have Error set.
+type ImportResult struct {
+	Row       int
+	ProductID string
+	Created   bool
+	Error     string
+}
+
+type ImportSummary struct {
+	Results []ImportResult
+	Created int
+	Updated int
+	Failed  int
+}
+
+func (s *ImportSummary) add(r ImportResult) {
+	switch {
+	case r.Error != "":
+		s.Failed++
+	case r.Created:
+		s.Created++
+	default:
+		s.Updated++
+	}
+	s.Results = append(s.Results, r)
+}
+
+// ProductWrite is a product to write in bulk. Create writes a new product
+// document; otherwise the imported fields of the existing one are replaced,
+// provided it is still at the version of Product, if one is set.
+type ProductWrite struct {
+	Product *Product
+	Create  bool
+}
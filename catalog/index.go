@@ -0,0 +1,298 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	elastic "github.com/olivere/elastic/v7"
+	"github.com/suryanshp1/go-microservice/money"
+)
+
+// Products live in a physical index named after the mapping version and the
+// time it was built, e.g. catalog_v1_20240101120000. Reads and writes go
+// through the catalog alias, which points at exactly one of them, so a new
+// index can be built and swapped in without downtime.
+const (
+	catalogAlias          = "catalog"
+	catalogMappingVersion = 2
+)
+
+// catalogMapping defines how products are indexed. Names are searchable as
+// full words and, through name.autocomplete, by prefix. Keyword subfields
+// allow exact matches, sorting and aggregations, and prices are stored as
+// an amount in the minor unit of their currency.
+const catalogMapping = `{
+  "settings": {
+    "analysis": {
+      "filter": {
+        "autocomplete_filter": {
+          "type": "edge_ngram",
+          "min_gram": 2,
+          "max_gram": 20
+        }
+      },
+      "analyzer": {
+        "autocomplete": {
+          "type": "custom",
+          "tokenizer": "standard",
+          "filter": ["lowercase", "asciifolding", "autocomplete_filter"]
+        },
+        "autocomplete_search": {
+          "type": "custom",
+          "tokenizer": "standard",
+          "filter": ["lowercase", "asciifolding"]
+        }
+      },
+      "normalizer": {
+        "lowercase": {
+          "type": "custom",
+          "filter": ["lowercase", "asciifolding"]
+        }
+      }
+    }
+  },
+  "mappings": {
+    "dynamic": "strict",
+    "properties": {
+      "name": {
+        "type": "text",
+        "fields": {
+          "keyword": { "type": "keyword", "ignore_above": 256, "normalizer": "lowercase" },
+          "autocomplete": { "type": "text", "analyzer": "autocomplete", "search_analyzer": "autocomplete_search" }
+        }
+      },
+      "description": {
+        "type": "text",
+        "fields": {
+          "keyword": { "type": "keyword", "ignore_above": 256 }
+        }
+      },
+      "price": {
+        "properties": {
+          "amount": { "type": "long" },
+          "currency": { "type": "keyword" }
+        }
+      },
+      "stock": { "type": "long" },
+      "reserved": { "type": "long" },
+      "categories": { "type": "keyword" },
+      "options": {
+        "properties": {
+          "name": { "type": "keyword" },
+          "values": { "type": "keyword" }
+        }
+      },
+      "variants": {
+        "type": "nested",
+        "properties": {
+          "id": { "type": "keyword" },
+          "sku": { "type": "keyword" },
+          "options": {
+            "properties": {
+              "name": { "type": "keyword" },
+              "value": { "type": "keyword" }
+            }
+          },
+          "price": {
+            "properties": {
+              "amount": { "type": "long" },
+              "currency": { "type": "keyword" }
+            }
+          },
+          "stock": { "type": "long" },
+          "reserved": { "type": "long" }
+        }
+      },
+      "external_sku": { "type": "keyword" },
+      "created_at": { "type": "date" },
+      "deleted_at": { "type": "date" },
+      "updated_at": { "type": "date" }
+    }
+  }
+}`
+
+func newElasticClient(url string) (*elastic.Client, error) {
+	return elastic.NewClient(
+		elastic.SetURL(url),
+		elastic.SetSniff(false),
+		elastic.SetHealthcheck(true),
+	)
+}
+
+func newCatalogIndexName(now time.Time) string {
+	return fmt.Sprintf("%s_v%d_%s", catalogAlias, catalogMappingVersion, now.UTC().Format("20060102150405"))
+}
+
+// catalogIndices returns the indices behind the catalog alias. legacy is true
+// if catalog is a plain index created before the alias existed.
+func catalogIndices(ctx context.Context, client *elastic.Client) (indices []string, legacy bool, err error) {
+	res, err := client.IndexGet(catalogAlias).Do(ctx)
+	if elastic.IsNotFound(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	for name := range res {
+		if name == catalogAlias {
+			return []string{name}, true, nil
+		}
+		indices = append(indices, name)
+	}
+	return indices, false, nil
+}
+
+// ensureCatalogIndex makes sure there is a catalog to write to. On a fresh
+// cluster it creates the first index version and its alias. An existing
+// index gets any fields added to the mapping since it was built; changes
+// that cannot be applied in place require a reindex.
+func ensureCatalogIndex(ctx context.Context, client *elastic.Client) error {
+	indices, legacy, err := catalogIndices(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	if legacy {
+		log.Println("Warning: catalog is a plain index without a mapping, run `catalog reindex` to migrate it")
+		return nil
+	}
+
+	if len(indices) == 0 {
+		index := newCatalogIndexName(time.Now())
+		if _, err := client.CreateIndex(index).BodyString(catalogMapping).Do(ctx); err != nil {
+			return err
+		}
+		_, err := client.Alias().Add(index, catalogAlias).Do(ctx)
+		return err
+	}
+
+	_, err = client.PutMapping().
+		Index(indices...).
+		BodyString(mappingProperties()).
+		Do(ctx)
+	if err != nil {
+		log.Printf("Warning: could not update catalog mapping, run `catalog reindex`: %v", err)
+	}
+	return nil
+}
+
+// mappingProperties returns the mappings part of catalogMapping, as accepted
+// by the put mapping API.
+func mappingProperties() string {
+	var body struct {
+		Mappings json.RawMessage `json:"mappings"`
+	}
+	json.Unmarshal([]byte(catalogMapping), &body)
+	return string(body.Mappings)
+}
+
+// migrateProductScript brings documents written by older versions up to
+// date while they are copied: mapping version 1 stored prices as a number of
+// DefaultCurrency units, which become an amount in cents.
+const migrateProductScript = `
+if (ctx._source.price instanceof Number) {
+  ctx._source.price = ['amount': Math.round(ctx._source.price * 100), 'currency': params.currency];
+}
+if (ctx._source.variants != null) {
+  for (def v : ctx._source.variants) {
+    if (v.price instanceof Number) {
+      v.price = ['amount': Math.round(v.price * 100), 'currency': params.currency];
+    }
+  }
+}`
+
+// reindexClockSkew is how far the clocks of catalog services may be behind
+// the clock of the one running Reindex, plus how long a write may take.
+const reindexClockSkew = time.Minute
+
+// Reindex copies the catalog into a new index built with the current
+// mapping and atomically points the catalog alias at it. Products written
+// while documents are copied are copied again afterwards, by their
+// updated_at; only the last of these catch-up passes, which copies the
+// products written during the one before, runs with writes to the old index
+// blocked. Reads keep working throughout. The old index is kept, write
+// blocked, so it can be inspected or swapped back, unless it is a plain
+// catalog index that the alias has to replace. Reindex returns the names of
+// both indices.
+func Reindex(ctx context.Context, url string) (from string, to string, err error) {
+	client, err := newElasticClient(url)
+	if err != nil {
+		return "", "", err
+	}
+
+	indices, legacy, err := catalogIndices(ctx, client)
+	if err != nil {
+		return "", "", err
+	}
+	if len(indices) != 1 {
+		return "", "", fmt.Errorf("expected the catalog alias to point at one index, found %v", indices)
+	}
+	from = indices[0]
+	to = newCatalogIndexName(time.Now())
+
+	if _, err := client.CreateIndex(to).BodyString(catalogMapping).Do(ctx); err != nil {
+		return "", "", err
+	}
+	defer func() {
+		if err != nil {
+			client.IndexPutSettings(from).BodyString(`{"index.blocks.write": false}`).Do(context.Background())
+			client.DeleteIndex(to).Do(context.Background())
+		}
+	}()
+
+	since := time.Now().UTC().Add(-reindexClockSkew)
+	if err := copyProducts(ctx, client, from, to, time.Time{}); err != nil {
+		return "", "", err
+	}
+	next := time.Now().UTC().Add(-reindexClockSkew)
+	if err := copyProducts(ctx, client, from, to, since); err != nil {
+		return "", "", err
+	}
+
+	_, err = client.IndexPutSettings(from).BodyString(`{"index.blocks.write": true}`).Do(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	// Make the last writes visible to the final pass.
+	if _, err := client.Refresh(from).Do(ctx); err != nil {
+		return "", "", err
+	}
+	if err := copyProducts(ctx, client, from, to, next); err != nil {
+		return "", "", err
+	}
+
+	// A plain catalog index has to make way for the alias of the same name;
+	// both happen in a single atomic alias update.
+	var remove elastic.AliasAction = elastic.NewAliasRemoveAction(catalogAlias).Index(from)
+	if legacy {
+		remove = elastic.NewAliasRemoveIndexAction(from)
+	}
+	_, err = client.Alias().
+		Action(remove, elastic.NewAliasAddAction(catalogAlias).Index(to)).
+		Do(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	return from, to, nil
+}
+
+// copyProducts copies the products of index from that were written since
+// the given time, or all of them if it is zero, into index to, overwriting
+// older copies.
+func copyProducts(ctx context.Context, client *elastic.Client, from, to string, since time.Time) error {
+	source := elastic.NewReindexSource().Index(from)
+	if !since.IsZero() {
+		source.Query(elastic.NewRangeQuery("updated_at").Gte(since))
+	}
+	_, err := client.Reindex().
+		Source(source).
+		DestinationIndex(to).
+		Script(elastic.NewScript(migrateProductScript).Param("currency", money.DefaultCurrency)).
+		WaitForCompletion(true).
+		Refresh("true").
+		Do(ctx)
+	return err
+}
@@ -0,0 +1,63 @@
+package catalog
+
+import (
+	"errors"
+	"time"
+)
+
+type ReservationStatus string
+
+const (
+	ReservationActive    ReservationStatus = "active"
+	ReservationCommitted ReservationStatus = "committed"
+	ReservationReleased  ReservationStatus = "released"
+	ReservationExpired   ReservationStatus = "expired"
+)
+
+// DefaultReservationTTL is how long reserved stock is held when the caller
+// does not ask for a specific duration.
+const DefaultReservationTTL = 15 * time.Minute
+
+const MaxReservationTTL = 24 * time.Hour
+
+var (
+	ErrInsufficientStock    = errors.New("insufficient stock")
+	ErrInvalidStockLevel    = errors.New("stock cannot drop below the reserved quantity")
+	ErrReservationNotActive = errors.New("reservation is no longer active")
+	ErrReservationExpired   = errors.New("reservation has expired")
+	ErrInvalidReservation   = errors.New("reservation must contain at least one line with a positive quantity")
+)
+
+// Reservation holds stock of one or more products for a while. Active
+// reservations either get committed, which takes the stock out of the
+// inventory for good, or released, which makes it available again.
+// Reservations that are neither before ExpiresAt are released automatically.
+type Reservation struct {
+	ID        string            `json:"-"`
+	AccountID string            `json:"account_id"`
+	Lines     []ReservationLine `json:"lines"`
+	// HeldLines counts the leading Lines whose stock is held. Reservations
+	// stored before it was recorded leave it nil and hold all of their lines.
+	HeldLines *int              `json:"held_lines,omitempty"`
+	Status    ReservationStatus `json:"status"`
+	CreatedAt time.Time         `json:"created_at"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+type ReservationLine struct {
+	ProductID string `json:"product_id"`
+	VariantID string `json:"variant_id,omitempty"`
+	Quantity  uint32 `json:"quantity"`
+}
+
+func (r *Reservation) IsExpired(now time.Time) bool {
+	return r.Status == ReservationActive && !now.Before(r.ExpiresAt)
+}
+
+// heldLines returns the lines whose stock the reservation holds.
+func (r *Reservation) heldLines() []ReservationLine {
+	if r.HeldLines == nil {
+		return r.Lines
+	}
+	return r.Lines[:min(max(*r.HeldLines, 0), len(r.Lines))]
+}
@@ -4,22 +4,54 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	elastic "github.com/olivere/elastic/v7"
+	"github.com/suryanshp1/go-microservice/money"
 )
 
 var (
-	ErrNotFound = errors.New("Entity not found")
+	ErrNotFound        = errors.New("Entity not found")
+	ErrInvalidProduct  = errors.New("product must have a name and a non-negative price")
+	ErrInvalidVersion  = errors.New("invalid product version")
+	ErrVersionConflict = errors.New("product was changed concurrently")
 )
 
 type Repository interface {
 	Close()
 	PutProduct(ctx context.Context, p *Product) error
 	GetProductByID(ctx context.Context, id string) (*Product, error)
-	ListProducts(ctx context.Context, skip uint64, take uint64) ([]*Product, error)
+	UpdateProduct(ctx context.Context, id string, fields map[string]interface{}, version string) (*Product, error)
 	ListProductsWithIDs(ctx context.Context, ids []string) ([]*Product, error)
-	SearchProducts(ctx context.Context, query string, skip uint64, take uint64) ([]*Product, error)
+	SearchProducts(ctx context.Context, q SearchQuery) (*SearchResult, error)
+	ExportProducts(ctx context.Context, q SearchQuery, fn func([]*Product) error) error
+	SuggestProducts(ctx context.Context, prefix string, limit int) (*Suggestions, error)
+	FindVariantBySKU(ctx context.Context, sku string) (productID string, err error)
+	FindProductIDsByExternalSKU(ctx context.Context, skus []string) (map[string]string, error)
+	BulkPutProducts(ctx context.Context, writes []ProductWrite) ([]error, error)
+	AdjustStock(ctx context.Context, productID, variantID string, delta int64) (*Product, error)
+	ReserveStock(ctx context.Context, productID, variantID string, quantity uint32) error
+	UnreserveStock(ctx context.Context, productID, variantID string, quantity uint32) error
+	CommitStock(ctx context.Context, productID, variantID string, quantity uint32) error
+	PutReservation(ctx context.Context, r *Reservation) error
+	GetReservation(ctx context.Context, id string) (*Reservation, error)
+	UpdateReservationStatus(ctx context.Context, id string, from, to ReservationStatus) error
+	UpdateReservationHeld(ctx context.Context, id string, heldLines int) error
+	ListExpiredReservations(ctx context.Context, now time.Time, limit int) ([]*Reservation, error)
+	PutCategory(ctx context.Context, c *Category) error
+	GetCategory(ctx context.Context, id string) (*Category, error)
+	RenameCategory(ctx context.Context, id, name string) (*Category, error)
+	MoveCategory(ctx context.Context, c *Category, parentID, path string) error
+	ListCategoriesWithIDs(ctx context.Context, ids []string) ([]*Category, error)
+	ListChildCategories(ctx context.Context, parentID string) ([]*Category, error)
+	ListCategoriesUnder(ctx context.Context, path string) ([]*Category, error)
+	PutExchangeRate(ctx context.Context, r *ExchangeRate) error
+	ListExchangeRates(ctx context.Context, to string) ([]*ExchangeRate, error)
 }
 
 type elasticRepository struct {
@@ -27,35 +59,140 @@ type elasticRepository struct {
 }
 
 type productDocument struct {
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Price       storedPrice     `json:"price"`
+	Stock       int64           `json:"stock"`
+	Reserved    int64           `json:"reserved"`
+	Categories  []string        `json:"categories"`
+	Options     []ProductOption `json:"options"`
+	Variants    []storedVariant `json:"variants"`
+	ExternalSKU string          `json:"external_sku,omitempty"`
+	CreatedAt   *time.Time      `json:"created_at,omitempty"`
+	DeletedAt   *time.Time      `json:"deleted_at,omitempty"`
+	// UpdatedAt is set by every write, so that Reindex can catch up with
+	// the products written while it copied the catalog.
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// storedPrice is a price as found in the catalog index. Prices used to be
+// stored as plain numbers of DefaultCurrency units; until `catalog reindex`
+// converts them they are read as such.
+type storedPrice money.Money
+
+func (p *storedPrice) UnmarshalJSON(b []byte) error {
+	var units float64
+	if err := json.Unmarshal(b, &units); err == nil {
+		*p = storedPrice(money.FromFloat(units, money.DefaultCurrency))
+		return nil
+	}
+	return json.Unmarshal(b, (*money.Money)(p))
+}
+
+type storedVariant struct {
+	Variant
+	Price *storedPrice `json:"price,omitempty"`
+}
+
+func productFromDocument(id string, doc productDocument) *Product {
+	p := &Product{
+		ID:          id,
+		Name:        doc.Name,
+		Description: doc.Description,
+		Price:       money.Money(doc.Price),
+		Stock:       doc.Stock,
+		Reserved:    doc.Reserved,
+		Categories:  doc.Categories,
+		Options:     doc.Options,
+		Variants:    []Variant{},
+		ExternalSKU: doc.ExternalSKU,
+		DeletedAt:   doc.DeletedAt,
+	}
+	if p.Categories == nil {
+		p.Categories = []string{}
+	}
+	if p.Options == nil {
+		p.Options = []ProductOption{}
+	}
+	for _, sv := range doc.Variants {
+		v := sv.Variant
+		if sv.Price != nil {
+			price := money.Money(*sv.Price)
+			v.Price = &price
+		}
+		p.Variants = append(p.Variants, v)
+	}
+	// Products indexed before creation times were recorded have none.
+	if doc.CreatedAt != nil {
+		p.CreatedAt = *doc.CreatedAt
+	}
+	return p
+}
+
+// A product version is the sequence number and primary term Elasticsearch
+// assigned to the last write of its document.
+func formatVersion(seqNo, primaryTerm *int64) string {
+	if seqNo == nil || primaryTerm == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d.%d", *primaryTerm, *seqNo)
+}
+
+func parseVersion(version string) (seqNo, primaryTerm int64, err error) {
+	term, seq, ok := strings.Cut(version, ".")
+	if !ok {
+		return 0, 0, ErrInvalidVersion
+	}
+	if primaryTerm, err = strconv.ParseInt(term, 10, 64); err != nil {
+		return 0, 0, ErrInvalidVersion
+	}
+	if seqNo, err = strconv.ParseInt(seq, 10, 64); err != nil {
+		return 0, 0, ErrInvalidVersion
+	}
+	return seqNo, primaryTerm, nil
+}
+
+// notDeleted filters out soft deleted products.
+func notDeleted() elastic.Query {
+	return elastic.NewBoolQuery().MustNot(elastic.NewExistsQuery("deleted_at"))
 }
 
 func NewElasticRepository(url string) (Repository, error) {
-	client, err := elastic.NewClient(
-		elastic.SetURL(url),
-		elastic.SetSniff(false),
-		elastic.SetHealthcheck(true),
-	)
+	client, err := newElasticClient(url)
 	if err != nil {
 		return nil, err
 	}
-	
-	// Create index if it doesn't exist
+
 	ctx := context.Background()
-	exists, err := client.IndexExists("catalog").Do(ctx)
-	if err != nil {
+	if err := ensureCatalogIndex(ctx, client); err != nil {
+		return nil, err
+	}
+
+	if err := ensureIndex(ctx, client, reservationsIndex, reservationsMapping); err != nil {
+		return nil, err
+	}
+	if err := ensureIndex(ctx, client, categoriesIndex, categoriesMapping); err != nil {
 		return nil, err
 	}
+	if err := ensureIndex(ctx, client, exchangeRatesIndex, exchangeRatesMapping); err != nil {
+		return nil, err
+	}
+
+	return &elasticRepository{client: client}, nil
+}
+
+func ensureIndex(ctx context.Context, client *elastic.Client, index, mapping string) error {
+	exists, err := client.IndexExists(index).Do(ctx)
+	if err != nil {
+		return err
+	}
 	if !exists {
-		_, err = client.CreateIndex("catalog").Do(ctx)
+		_, err = client.CreateIndex(index).BodyString(mapping).Do(ctx)
 		if err != nil {
-			log.Printf("Warning: could not create index: %v", err)
+			log.Printf("Warning: could not create index %s: %v", index, err)
 		}
 	}
-	
-	return &elasticRepository{client: client}, nil
+	return nil
 }
 
 func (r *elasticRepository) Close() {
@@ -64,20 +201,37 @@ func (r *elasticRepository) Close() {
 
 func (r *elasticRepository) PutProduct(ctx context.Context, p *Product) error {
 	_, err := r.client.Index().
-		Index("catalog").
+		Index(catalogAlias).
 		Id(p.ID).
-		BodyJson(productDocument{
-			Name:        p.Name,
-			Description: p.Description,
-			Price:       p.Price,
-		}).
+		BodyJson(newProductDocument(p)).
 		Do(ctx)
 	return err
 }
 
+func newProductDocument(p *Product) productDocument {
+	doc := productDocument{
+		Name:        p.Name,
+		Description: p.Description,
+		Price:       storedPrice(p.Price),
+		Stock:       p.Stock,
+		Reserved:    p.Reserved,
+		Categories:  p.Categories,
+		Options:     p.Options,
+		Variants:    []storedVariant{},
+		ExternalSKU: p.ExternalSKU,
+		CreatedAt:   &p.CreatedAt,
+	}
+	now := time.Now().UTC()
+	doc.UpdatedAt = &now
+	for _, v := range p.Variants {
+		doc.Variants = append(doc.Variants, storedVariant{Variant: v, Price: (*storedPrice)(v.Price)})
+	}
+	return doc
+}
+
 func (r *elasticRepository) GetProductByID(ctx context.Context, id string) (*Product, error) {
 	res, err := r.client.Get().
-		Index("catalog").
+		Index(catalogAlias).
 		Id(id).
 		Do(ctx)
 
@@ -98,48 +252,58 @@ func (r *elasticRepository) GetProductByID(ctx context.Context, id string) (*Pro
 		return nil, err
 	}
 
-	return &Product{
-		ID:          id,
-		Name:        doc.Name,
-		Description: doc.Description,
-		Price:       doc.Price,
-	}, err
+	p := productFromDocument(id, doc)
+	p.Version = formatVersion(res.SeqNo, res.PrimaryTerm)
+	return p, err
 
 }
 
-func (r *elasticRepository) ListProducts(ctx context.Context, skip uint64, take uint64) ([]*Product, error) {
-	res, err := r.client.Search().
-		Index("catalog").
-		Query(elastic.NewMatchAllQuery()).
-		From(int(skip)).
-		Size(int(take)).
-		Do(ctx)
-
+// UpdateProduct sets fields of a product document, provided it is still at
+// version.
+func (r *elasticRepository) UpdateProduct(
+	ctx context.Context,
+	id string,
+	fields map[string]interface{},
+	version string,
+) (*Product, error) {
+	seqNo, primaryTerm, err := parseVersion(version)
 	if err != nil {
 		return nil, err
 	}
+	fields["updated_at"] = time.Now().UTC()
 
-	products := make([]*Product, 0, len(res.Hits.Hits))
-	for _, hit := range res.Hits.Hits {
-		var doc productDocument
-		if err := json.Unmarshal(hit.Source, &doc); err != nil {
-			return nil, err
-		}
-		products = append(products, &Product{
-			ID:          hit.Id,
-			Name:        doc.Name,
-			Description: doc.Description,
-			Price:       doc.Price,
-		})
+	res, err := r.client.Update().
+		Index(catalogAlias).
+		Id(id).
+		Doc(fields).
+		IfSeqNo(seqNo).
+		IfPrimaryTerm(primaryTerm).
+		FetchSource(true).
+		Refresh("wait_for").
+		Do(ctx)
+	if elastic.IsNotFound(err) {
+		return nil, ErrNotFound
+	}
+	if elastic.IsConflict(err) {
+		return nil, ErrVersionConflict
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	return products, nil
+	var doc productDocument
+	if err := json.Unmarshal(res.GetResult.Source, &doc); err != nil {
+		return nil, err
+	}
+	p := productFromDocument(id, doc)
+	p.Version = formatVersion(&res.SeqNo, &res.PrimaryTerm)
+	return p, nil
 }
 
 func (r *elasticRepository) ListProductsWithIDs(ctx context.Context, ids []string) ([]*Product, error) {
 	items := []*elastic.MultiGetItem{}
 	for _, id := range ids {
-		items = append(items, elastic.NewMultiGetItem().Index("catalog").Id(id))
+		items = append(items, elastic.NewMultiGetItem().Index(catalogAlias).Id(id))
 	}
 
 	res, err := r.client.MultiGet().
@@ -159,43 +323,934 @@ func (r *elasticRepository) ListProductsWithIDs(ctx context.Context, ids []strin
 		if err := json.Unmarshal(doc.Source, &pd); err != nil {
 			return nil, err
 		}
-		products = append(products, &Product{
-			ID:          doc.Id,
-			Name:        pd.Name,
-			Description: pd.Description,
-			Price:       pd.Price,
-		})
+		if pd.DeletedAt != nil {
+			continue
+		}
+		p := productFromDocument(doc.Id, pd)
+		p.Version = formatVersion(doc.SeqNo, doc.PrimaryTerm)
+		products = append(products, p)
 	}
 
 	return products, nil
 }
 
-func (r *elasticRepository) SearchProducts(ctx context.Context, query string, skip uint64, take uint64) ([]*Product, error) {
+// pitKeepAlive is how long a point in time is kept after each page read
+// from it, i.e. how long a client may take before asking for the next page.
+const pitKeepAlive = "5m"
+
+func (r *elasticRepository) SearchProducts(ctx context.Context, q SearchQuery) (*SearchResult, error) {
+	query := elastic.NewBoolQuery().MustNot(elastic.NewExistsQuery("deleted_at"))
+	if q.Query != "" {
+		query.Must(elastic.NewMultiMatchQuery(q.Query, "name^3", "name.autocomplete", "description"))
+	}
+
+	// Filters go into the post filter rather than the query so that each
+	// facet can be computed without its own filter.
+	priceFilter, categoryFilter := searchFilters(q)
+	filterOf := func(filters ...elastic.Query) *elastic.BoolQuery {
+		b := elastic.NewBoolQuery()
+		for _, f := range filters {
+			if f != nil {
+				b.Filter(f)
+			}
+		}
+		return b
+	}
+
+	// Pages after the first are read from a point in time, so that they
+	// stay consistent while products change, and continue after the sort
+	// values of the previous page's last product. Most searches never get
+	// past the first page, so the point in time is only opened once a
+	// client continues from it, at the offset its cursor holds.
+	var after *productCursor
+	if q.After != "" {
+		var err error
+		if after, err = decodeProductCursor(q.After, q.Sort); err != nil {
+			return nil, err
+		}
+		if after.PIT == "" {
+			pit, err := r.client.OpenPointInTime(catalogAlias).KeepAlive(pitKeepAlive).Do(ctx)
+			if err != nil {
+				return nil, err
+			}
+			after.PIT = pit.Id
+		}
+	}
+
+	search := r.client.Search()
+	if after != nil {
+		search.PointInTime(elastic.NewPointInTimeWithKeepAlive(after.PIT, pitKeepAlive))
+	} else {
+		search.Index(catalogAlias)
+	}
+	search.
+		Query(query).
+		PostFilter(filterOf(priceFilter, categoryFilter)).
+		Aggregation("prices", elastic.NewFilterAggregation().
+			Filter(filterOf(categoryFilter, elastic.NewTermQuery("price.currency", q.PriceInterval.Currency))).
+			SubAggregation("histogram", elastic.NewHistogramAggregation().
+				Field("price.amount").
+				Interval(float64(q.PriceInterval.Amount)))).
+		Aggregation("categories", elastic.NewFilterAggregation().
+			Filter(filterOf(priceFilter)).
+			SubAggregation("terms", elastic.NewTermsAggregation().
+				Field("categories").
+				Size(50))).
+		Size(int(q.Take) + 1).
+		TrackTotalHits(true).
+		SeqNoAndPrimaryTerm(true)
+	switch {
+	case after == nil:
+		search.From(int(q.Skip))
+	case len(after.Values) > 0:
+		search.SearchAfter(after.Values...)
+	default:
+		search.From(after.Offset)
+	}
+
+	switch q.Sort {
+	case SortPriceAsc:
+		search.Sort("price.amount", true)
+	case SortPriceDesc:
+		search.Sort("price.amount", false)
+	case SortNewest:
+		search.SortBy(elastic.NewFieldSort("created_at").Desc().Missing("_last"))
+	default:
+		search.SortBy(elastic.NewScoreSort())
+	}
+	if after != nil {
+		search.SortBy(elastic.NewFieldSort("_shard_doc"))
+	}
+
+	res, err := search.Do(ctx)
+	if after != nil && elastic.IsNotFound(err) {
+		// The point in time expired.
+		return nil, ErrInvalidCursor
+	}
+	if err != nil {
+		log.Println("SearchProducts error:", err)
+		return nil, err
+	}
+
+	hits := res.Hits.Hits
+	result := &SearchResult{
+		Products:    make([]*Product, 0, len(hits)),
+		Cursors:     make([]string, 0, len(hits)),
+		HasNextPage: len(hits) > int(q.Take),
+		Facets: Facets{
+			Prices:     []PriceBucket{},
+			Categories: []CategoryCount{},
+		},
+	}
+	if res.Hits.TotalHits != nil {
+		result.Total = res.Hits.TotalHits.Value
+	}
+	if result.HasNextPage {
+		hits = hits[:q.Take]
+	}
+	// Elasticsearch may hand out a new point in time ID with every response.
+	pit := res.PitId
+	if pit == "" && after != nil {
+		pit = after.PIT
+	}
+	for i, hit := range hits {
+		var doc productDocument
+		if err := json.Unmarshal(hit.Source, &doc); err != nil {
+			return nil, err
+		}
+		p := productFromDocument(hit.Id, doc)
+		p.Version = formatVersion(hit.SeqNo, hit.PrimaryTerm)
+		result.Products = append(result.Products, p)
+		cursor := productCursor{PIT: pit, Sort: q.Sort, Values: hit.Sort}
+		if pit == "" {
+			cursor = productCursor{Sort: q.Sort, Offset: int(q.Skip) + i + 1}
+		}
+		result.Cursors = append(result.Cursors, cursor.encode())
+	}
+	if n := len(result.Cursors); n > 0 {
+		result.EndCursor = result.Cursors[n-1]
+	}
+	if pit != "" && !result.HasNextPage {
+		// Nobody is going to ask for another page.
+		if _, err := r.client.ClosePointInTime(pit).Do(ctx); err != nil {
+			log.Println("failed to close point in time:", err)
+		}
+	}
+
+	if prices, ok := res.Aggregations.Filter("prices"); ok {
+		if histogram, ok := prices.Histogram("histogram"); ok {
+			for _, b := range histogram.Buckets {
+				from := money.New(int64(b.Key), q.PriceInterval.Currency)
+				result.Facets.Prices = append(result.Facets.Prices, PriceBucket{
+					From:  from,
+					To:    money.New(from.Amount+q.PriceInterval.Amount, from.Currency),
+					Count: b.DocCount,
+				})
+			}
+		}
+	}
+	if categories, ok := res.Aggregations.Filter("categories"); ok {
+		if terms, ok := categories.Terms("terms"); ok {
+			for _, b := range terms.Buckets {
+				result.Facets.Categories = append(result.Facets.Categories, CategoryCount{
+					Category: fmt.Sprint(b.Key),
+					Count:    b.DocCount,
+				})
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// searchFilters returns the price range and category filters of q, nil for
+// those it does not set. The price range only matches products priced in
+// its currency.
+func searchFilters(q SearchQuery) (price, categories elastic.Query) {
+	if q.MinPrice != nil || q.MaxPrice != nil {
+		priceRange := elastic.NewRangeQuery("price.amount")
+		currency := ""
+		if q.MinPrice != nil {
+			priceRange.Gte(q.MinPrice.Amount)
+			currency = q.MinPrice.Currency
+		}
+		if q.MaxPrice != nil {
+			priceRange.Lte(q.MaxPrice.Amount)
+			currency = q.MaxPrice.Currency
+		}
+		price = elastic.NewBoolQuery().Filter(priceRange, elastic.NewTermQuery("price.currency", currency))
+	}
+	if len(q.Categories) > 0 {
+		ids := []interface{}{}
+		for _, c := range q.Categories {
+			ids = append(ids, c)
+		}
+		categories = elastic.NewTermsQuery("categories", ids...)
+	}
+	return price, categories
+}
+
+// exportPageSize is how many products ExportProducts reads per request.
+const exportPageSize = 1000
+
+// ExportProducts walks all matching products in index order, reading them
+// from a point in time that is closed when it returns.
+func (r *elasticRepository) ExportProducts(ctx context.Context, q SearchQuery, fn func([]*Product) error) error {
+	query := elastic.NewBoolQuery().MustNot(elastic.NewExistsQuery("deleted_at"))
+	if q.Query != "" {
+		query.Must(elastic.NewMultiMatchQuery(q.Query, "name^3", "name.autocomplete", "description"))
+	}
+	priceFilter, categoryFilter := searchFilters(q)
+	for _, f := range []elastic.Query{priceFilter, categoryFilter} {
+		if f != nil {
+			query.Filter(f)
+		}
+	}
+
+	pit, err := r.client.OpenPointInTime(catalogAlias).KeepAlive(pitKeepAlive).Do(ctx)
+	if err != nil {
+		return err
+	}
+	pitID := pit.Id
+	defer func() {
+		if _, err := r.client.ClosePointInTime(pitID).Do(context.Background()); err != nil {
+			log.Println("failed to close point in time:", err)
+		}
+	}()
+
+	var after []interface{}
+	for {
+		search := r.client.Search().
+			PointInTime(elastic.NewPointInTimeWithKeepAlive(pitID, pitKeepAlive)).
+			Query(query).
+			SortBy(elastic.NewFieldSort("_shard_doc")).
+			Size(exportPageSize).
+			TrackTotalHits(false).
+			SeqNoAndPrimaryTerm(true)
+		if after != nil {
+			search.SearchAfter(after...)
+		}
+		res, err := search.Do(ctx)
+		if err != nil {
+			return err
+		}
+		if res.PitId != "" {
+			pitID = res.PitId
+		}
+
+		hits := res.Hits.Hits
+		if len(hits) == 0 {
+			return nil
+		}
+		products := make([]*Product, 0, len(hits))
+		for _, hit := range hits {
+			var doc productDocument
+			if err := json.Unmarshal(hit.Source, &doc); err != nil {
+				return err
+			}
+			p := productFromDocument(hit.Id, doc)
+			p.Version = formatVersion(hit.SeqNo, hit.PrimaryTerm)
+			products = append(products, p)
+		}
+		if err := fn(products); err != nil {
+			return err
+		}
+		if len(hits) < exportPageSize {
+			return nil
+		}
+		after = hits[len(hits)-1].Sort
+	}
+}
+
+// SuggestProducts matches prefix against the edge n-grams of product names,
+// so that the last word can be incomplete. Categories are those of the
+// matching products, most frequent first, and only carry IDs. Corrections
+// come from a phrase suggester on the full product names.
+func (r *elasticRepository) SuggestProducts(ctx context.Context, prefix string, limit int) (*Suggestions, error) {
+	query := elastic.NewBoolQuery().
+		Must(elastic.NewMatchQuery("name.autocomplete", prefix).Operator("and")).
+		MustNot(elastic.NewExistsQuery("deleted_at"))
+
+	res, err := r.client.Search().
+		Index(catalogAlias).
+		Query(query).
+		FetchSourceContext(elastic.NewFetchSourceContext(true).Include("name")).
+		Size(limit).
+		Aggregation("categories", elastic.NewTermsAggregation().Field("categories").Size(limit)).
+		Suggester(elastic.NewPhraseSuggester("corrections").
+			Text(prefix).
+			Field("name").
+			Size(3).
+			Confidence(1).
+			CandidateGenerator(elastic.NewDirectCandidateGenerator("name").SuggestMode("always"))).
+		Do(ctx)
+	if err != nil {
+		log.Println("SuggestProducts error:", err)
+		return nil, err
+	}
+
+	suggestions := &Suggestions{
+		Products:    []ProductSuggestion{},
+		Categories:  []CategorySuggestion{},
+		Corrections: []string{},
+	}
+	for _, hit := range res.Hits.Hits {
+		var doc productDocument
+		if err := json.Unmarshal(hit.Source, &doc); err != nil {
+			return nil, err
+		}
+		suggestions.Products = append(suggestions.Products, ProductSuggestion{ProductID: hit.Id, Name: doc.Name})
+	}
+	if terms, ok := res.Aggregations.Terms("categories"); ok {
+		for _, b := range terms.Buckets {
+			suggestions.Categories = append(suggestions.Categories, CategorySuggestion{
+				CategoryID: fmt.Sprint(b.Key),
+				Count:      b.DocCount,
+			})
+		}
+	}
+	for _, s := range res.Suggest["corrections"] {
+		for _, o := range s.Options {
+			suggestions.Corrections = append(suggestions.Corrections, o.Text)
+		}
+	}
+	return suggestions, nil
+}
+
+const reservationsIndex = "catalog_reservations"
+
+const reservationsMapping = `{
+  "mappings": {
+    "properties": {
+      "account_id": { "type": "keyword" },
+      "status": { "type": "keyword" },
+      "lines": {
+        "properties": {
+          "product_id": { "type": "keyword" },
+          "variant_id": { "type": "keyword" },
+          "quantity": { "type": "integer" }
+        }
+      },
+      "held_lines": { "type": "integer" },
+      "created_at": { "type": "date" },
+      "expires_at": { "type": "date" }
+    }
+  }
+}`
+
+// Stock levels are changed with scripted updates so that the check and the
+// change happen atomically on the product document. A script that refuses
+// the change sets ctx.op to noop. Stock of a variant is changed together
+// with the totals on its product; the scripts refuse to touch a variant that
+// does not exist.
+const (
+	findVariantScript = `
+def v = null;
+if (params.variant != null && ctx._source.variants != null) {
+  for (def candidate : ctx._source.variants) {
+    if (candidate.id == params.variant) {
+      v = candidate;
+    }
+  }
+}
+def level = v == null ? ctx._source : v;
+long stock = level.stock == null ? 0 : level.stock;
+long reserved = level.reserved == null ? 0 : level.reserved;
+long totalStock = ctx._source.stock == null ? 0 : ctx._source.stock;
+long totalReserved = ctx._source.reserved == null ? 0 : ctx._source.reserved;
+boolean missing = params.variant != null && v == null;`
+
+	adjustStockScript = findVariantScript + `
+if (missing || stock + params.delta < reserved) {
+  ctx.op = 'noop';
+} else {
+  if (v != null) {
+    v.stock = stock + params.delta;
+  }
+  ctx._source.stock = totalStock + params.delta;
+}`
+
+	reserveStockScript = findVariantScript + `
+if (missing || ctx._source.deleted_at != null || stock - reserved < params.quantity) {
+  ctx.op = 'noop';
+} else {
+  if (v != null) {
+    v.reserved = reserved + params.quantity;
+  }
+  ctx._source.reserved = totalReserved + params.quantity;
+}`
+
+	unreserveStockScript = findVariantScript + `
+if (v != null) {
+  v.reserved = Math.max(0, reserved - params.quantity);
+}
+ctx._source.reserved = Math.max(0, totalReserved - params.quantity);`
+
+	commitStockScript = findVariantScript + `
+if (v != null) {
+  v.stock = Math.max(0, stock - params.quantity);
+  v.reserved = Math.max(0, reserved - params.quantity);
+}
+ctx._source.stock = Math.max(0, totalStock - params.quantity);
+ctx._source.reserved = Math.max(0, totalReserved - params.quantity);`
+
+	reservationStatusScript = `
+if (ctx._source.status != params.from) {
+  ctx.op = 'noop';
+} else {
+  ctx._source.status = params.to;
+}`
+
+	reservationHeldScript = `
+if (ctx._source.status != params.active) {
+  ctx.op = 'noop';
+} else {
+  ctx._source.held_lines = params.held_lines;
+}`
+)
+
+func (r *elasticRepository) updateProduct(
+	ctx context.Context,
+	productID string,
+	script string,
+	params map[string]interface{},
+) (*elastic.UpdateResponse, error) {
+	// Changes of scripts that end in a noop are dropped, updated_at with
+	// them.
+	params["updated_at"] = time.Now().UTC()
+	res, err := r.client.Update().
+		Index(catalogAlias).
+		Id(productID).
+		Script(elastic.NewScript(script + "\nctx._source.updated_at = params.updated_at;").Params(params)).
+		RetryOnConflict(5).
+		FetchSource(true).
+		Do(ctx)
+	if elastic.IsNotFound(err) {
+		return nil, ErrNotFound
+	}
+	return res, err
+}
+
+// FindVariantBySKU returns the ID of the product that has a variant with the
+// given SKU.
+func (r *elasticRepository) FindVariantBySKU(ctx context.Context, sku string) (string, error) {
 	res, err := r.client.Search().
-		Index("catalog").
-		Query(elastic.NewMultiMatchQuery(query, "name", "description")).
-		From(int(skip)).
-		Size(int(take)).
+		Index(catalogAlias).
+		Query(elastic.NewNestedQuery("variants", elastic.NewTermQuery("variants.sku", sku))).
+		Size(1).
+		FetchSource(false).
 		Do(ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(res.Hits.Hits) == 0 {
+		return "", ErrNotFound
+	}
+	return res.Hits.Hits[0].Id, nil
+}
 
+// FindProductIDsByExternalSKU maps those of skus that belong to a product
+// that is not deleted to the product's ID.
+func (r *elasticRepository) FindProductIDsByExternalSKU(ctx context.Context, skus []string) (map[string]string, error) {
+	terms := make([]interface{}, 0, len(skus))
+	for _, sku := range skus {
+		terms = append(terms, sku)
+	}
+	res, err := r.client.Search().
+		Index(catalogAlias).
+		Query(elastic.NewBoolQuery().
+			Filter(elastic.NewTermsQuery("external_sku", terms...)).
+			MustNot(elastic.NewExistsQuery("deleted_at"))).
+		FetchSourceContext(elastic.NewFetchSourceContext(true).Include("external_sku")).
+		Size(len(skus)).
+		Do(ctx)
 	if err != nil {
-		log.Println("SearchProducts error:", err)
 		return nil, err
 	}
 
-	products := make([]*Product, 0, len(res.Hits.Hits))
+	ids := map[string]string{}
 	for _, hit := range res.Hits.Hits {
 		var doc productDocument
 		if err := json.Unmarshal(hit.Source, &doc); err != nil {
 			return nil, err
 		}
-		products = append(products, &Product{
-			ID:          hit.Id,
-			Name:        doc.Name,
-			Description: doc.Description,
-			Price:       doc.Price,
+		ids[doc.ExternalSKU] = hit.Id
+	}
+	return ids, nil
+}
+
+// BulkPutProducts writes products with a single bulk request and returns the
+// error of each write, nil for those that succeeded. The writes are visible
+// to searches when it returns, so that the next batch of an import finds the
+// products created by this one.
+func (r *elasticRepository) BulkPutProducts(ctx context.Context, writes []ProductWrite) ([]error, error) {
+	bulk := r.client.Bulk().Index(catalogAlias).Refresh("wait_for")
+	for _, w := range writes {
+		p := w.Product
+		if w.Create {
+			bulk.Add(elastic.NewBulkCreateRequest().Id(p.ID).Doc(newProductDocument(p)))
+			continue
+		}
+		update := elastic.NewBulkUpdateRequest().Id(p.ID).Doc(map[string]interface{}{
+			"name":         p.Name,
+			"description":  p.Description,
+			"price":        p.Price,
+			"categories":   p.Categories,
+			"external_sku": p.ExternalSKU,
+			"updated_at":   time.Now().UTC(),
 		})
+		if p.Version != "" {
+			seqNo, primaryTerm, err := parseVersion(p.Version)
+			if err != nil {
+				return nil, err
+			}
+			update.IfSeqNo(seqNo).IfPrimaryTerm(primaryTerm)
+		}
+		bulk.Add(update)
 	}
 
-	return products, nil
+	res, err := bulk.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	errs := make([]error, len(writes))
+	for i, item := range res.Items {
+		for _, result := range item {
+			if result.Status == http.StatusConflict {
+				errs[i] = ErrVersionConflict
+			} else if result.Error != nil {
+				errs[i] = errors.New(result.Error.Reason)
+			}
+		}
+	}
+	return errs, nil
+}
+
+// stockParams builds the parameters of the stock scripts. An empty variantID
+// changes the stock of the product itself.
+func stockParams(variantID, name string, value interface{}) map[string]interface{} {
+	params := map[string]interface{}{name: value, "variant": nil}
+	if variantID != "" {
+		params["variant"] = variantID
+	}
+	return params
+}
+
+func (r *elasticRepository) AdjustStock(ctx context.Context, productID, variantID string, delta int64) (*Product, error) {
+	res, err := r.updateProduct(ctx, productID, adjustStockScript, stockParams(variantID, "delta", delta))
+	if err != nil {
+		return nil, err
+	}
+	if res.Result == "noop" {
+		return nil, ErrInvalidStockLevel
+	}
+
+	var doc productDocument
+	if err := json.Unmarshal(res.GetResult.Source, &doc); err != nil {
+		return nil, err
+	}
+	return productFromDocument(productID, doc), nil
+}
+
+func (r *elasticRepository) ReserveStock(ctx context.Context, productID, variantID string, quantity uint32) error {
+	res, err := r.updateProduct(ctx, productID, reserveStockScript, stockParams(variantID, "quantity", quantity))
+	if err != nil {
+		return err
+	}
+	if res.Result == "noop" {
+		return ErrInsufficientStock
+	}
+	return nil
+}
+
+func (r *elasticRepository) UnreserveStock(ctx context.Context, productID, variantID string, quantity uint32) error {
+	_, err := r.updateProduct(ctx, productID, unreserveStockScript, stockParams(variantID, "quantity", quantity))
+	return err
+}
+
+func (r *elasticRepository) CommitStock(ctx context.Context, productID, variantID string, quantity uint32) error {
+	_, err := r.updateProduct(ctx, productID, commitStockScript, stockParams(variantID, "quantity", quantity))
+	return err
+}
+
+func (r *elasticRepository) PutReservation(ctx context.Context, res *Reservation) error {
+	_, err := r.client.Index().
+		Index(reservationsIndex).
+		Id(res.ID).
+		BodyJson(res).
+		Refresh("wait_for").
+		Do(ctx)
+	return err
+}
+
+func (r *elasticRepository) GetReservation(ctx context.Context, id string) (*Reservation, error) {
+	res, err := r.client.Get().
+		Index(reservationsIndex).
+		Id(id).
+		Do(ctx)
+	if err != nil {
+		if elastic.IsNotFound(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if !res.Found {
+		return nil, ErrNotFound
+	}
+
+	reservation := &Reservation{}
+	if err := json.Unmarshal(res.Source, reservation); err != nil {
+		return nil, err
+	}
+	reservation.ID = id
+	return reservation, nil
+}
+
+// UpdateReservationStatus moves a reservation from one status to another. It
+// fails with ErrReservationNotActive if the reservation is no longer in from.
+func (r *elasticRepository) UpdateReservationStatus(ctx context.Context, id string, from, to ReservationStatus) error {
+	res, err := r.client.Update().
+		Index(reservationsIndex).
+		Id(id).
+		Script(elastic.NewScript(reservationStatusScript).Params(map[string]interface{}{
+			"from": from,
+			"to":   to,
+		})).
+		RetryOnConflict(5).
+		Refresh("wait_for").
+		Do(ctx)
+	if elastic.IsNotFound(err) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if res.Result == "noop" {
+		return ErrReservationNotActive
+	}
+	return nil
+}
+
+// UpdateReservationHeld records how many lines of an active reservation hold
+// their stock. It fails with ErrReservationNotActive once the reservation
+// was finished.
+func (r *elasticRepository) UpdateReservationHeld(ctx context.Context, id string, heldLines int) error {
+	res, err := r.client.Update().
+		Index(reservationsIndex).
+		Id(id).
+		Script(elastic.NewScript(reservationHeldScript).Params(map[string]interface{}{
+			"active":     ReservationActive,
+			"held_lines": heldLines,
+		})).
+		RetryOnConflict(5).
+		Refresh("wait_for").
+		Do(ctx)
+	if elastic.IsNotFound(err) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if res.Result == "noop" {
+		return ErrReservationNotActive
+	}
+	return nil
+}
+
+func (r *elasticRepository) ListExpiredReservations(ctx context.Context, now time.Time, limit int) ([]*Reservation, error) {
+	res, err := r.client.Search().
+		Index(reservationsIndex).
+		Query(elastic.NewBoolQuery().Filter(
+			elastic.NewTermQuery("status", ReservationActive),
+			elastic.NewRangeQuery("expires_at").Lte(now),
+		)).
+		Sort("expires_at", true).
+		Size(limit).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reservations := make([]*Reservation, 0, len(res.Hits.Hits))
+	for _, hit := range res.Hits.Hits {
+		reservation := &Reservation{}
+		if err := json.Unmarshal(hit.Source, reservation); err != nil {
+			return nil, err
+		}
+		reservation.ID = hit.Id
+		reservations = append(reservations, reservation)
+	}
+	return reservations, nil
+}
+
+const categoriesIndex = "catalog_categories"
+
+const categoriesMapping = `{
+  "mappings": {
+    "properties": {
+      "name": { "type": "keyword" },
+      "parent_id": { "type": "keyword" },
+      "path": { "type": "keyword" },
+      "created_at": { "type": "date" }
+    }
+  }
+}`
+
+// maxCategories bounds category listings; the tree is expected to stay far
+// smaller.
+const maxCategories = 10000
+
+func (r *elasticRepository) PutCategory(ctx context.Context, c *Category) error {
+	_, err := r.client.Index().
+		Index(categoriesIndex).
+		Id(c.ID).
+		BodyJson(c).
+		Refresh("wait_for").
+		Do(ctx)
+	return err
+}
+
+func (r *elasticRepository) GetCategory(ctx context.Context, id string) (*Category, error) {
+	res, err := r.client.Get().
+		Index(categoriesIndex).
+		Id(id).
+		Do(ctx)
+	if err != nil {
+		if elastic.IsNotFound(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if !res.Found {
+		return nil, ErrNotFound
+	}
+	return categoryFromSource(id, res.Source)
+}
+
+func (r *elasticRepository) RenameCategory(ctx context.Context, id, name string) (*Category, error) {
+	res, err := r.client.Update().
+		Index(categoriesIndex).
+		Id(id).
+		Doc(map[string]interface{}{"name": name}).
+		FetchSource(true).
+		Refresh("wait_for").
+		Do(ctx)
+	if elastic.IsNotFound(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return categoryFromSource(id, res.GetResult.Source)
+}
+
+// MoveCategory gives c a new parent and path and updates the paths of its
+// descendants to match. The descendants are updated first, with one bulk
+// request, and c last: until c is updated it keeps its old path, so a move
+// that failed halfway is finished by moving c again.
+func (r *elasticRepository) MoveCategory(ctx context.Context, c *Category, parentID, path string) error {
+	descendants, err := r.ListCategoriesUnder(ctx, c.Path)
+	if err != nil {
+		return err
+	}
+	bulk := r.client.Bulk().Index(categoriesIndex).Refresh("true")
+	for _, d := range descendants {
+		if d.ID == c.ID {
+			continue
+		}
+		bulk.Add(elastic.NewBulkUpdateRequest().Id(d.ID).Doc(map[string]interface{}{
+			"path": path + strings.TrimPrefix(d.Path, c.Path),
+		}))
+	}
+	if bulk.NumberOfActions() > 0 {
+		res, err := bulk.Do(ctx)
+		if err != nil {
+			return err
+		}
+		if failed := res.Failed(); len(failed) > 0 {
+			return fmt.Errorf("failed to move %d descendants of category %s: %s", len(failed), c.ID, failed[0].Error.Reason)
+		}
+	}
+
+	doc := map[string]interface{}{"parent_id": parentID, "path": path}
+	if parentID == "" {
+		doc["parent_id"] = nil
+	}
+	_, err = r.client.Update().
+		Index(categoriesIndex).
+		Id(c.ID).
+		Doc(doc).
+		Refresh("true").
+		Do(ctx)
+	if elastic.IsNotFound(err) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (r *elasticRepository) ListCategoriesWithIDs(ctx context.Context, ids []string) ([]*Category, error) {
+	items := []*elastic.MultiGetItem{}
+	for _, id := range ids {
+		items = append(items, elastic.NewMultiGetItem().Index(categoriesIndex).Id(id))
+	}
+	res, err := r.client.MultiGet().Add(items...).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	categories := []*Category{}
+	for _, doc := range res.Docs {
+		if !doc.Found {
+			continue
+		}
+		c, err := categoryFromSource(doc.Id, doc.Source)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, c)
+	}
+	return categories, nil
+}
+
+func (r *elasticRepository) ListChildCategories(ctx context.Context, parentID string) ([]*Category, error) {
+	var query elastic.Query = elastic.NewTermQuery("parent_id", parentID)
+	if parentID == "" {
+		query = elastic.NewBoolQuery().MustNot(elastic.NewExistsQuery("parent_id"))
+	}
+	return r.searchCategories(ctx, query)
+}
+
+// ListCategoriesUnder returns the category with the given path and all of
+// its descendants.
+func (r *elasticRepository) ListCategoriesUnder(ctx context.Context, path string) ([]*Category, error) {
+	return r.searchCategories(ctx, elastic.NewPrefixQuery("path", path))
+}
+
+func (r *elasticRepository) searchCategories(ctx context.Context, query elastic.Query) ([]*Category, error) {
+	res, err := r.client.Search().
+		Index(categoriesIndex).
+		Query(query).
+		Sort("name", true).
+		Size(maxCategories).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	categories := make([]*Category, 0, len(res.Hits.Hits))
+	for _, hit := range res.Hits.Hits {
+		c, err := categoryFromSource(hit.Id, hit.Source)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, c)
+	}
+	return categories, nil
+}
+
+func categoryFromSource(id string, source json.RawMessage) (*Category, error) {
+	c := &Category{}
+	if err := json.Unmarshal(source, c); err != nil {
+		return nil, err
+	}
+	c.ID = id
+	return c, nil
+}
+
+const exchangeRatesIndex = "catalog_exchange_rates"
+
+const exchangeRatesMapping = `{
+  "mappings": {
+    "properties": {
+      "from": { "type": "keyword" },
+      "to": { "type": "keyword" },
+      "value": { "type": "keyword" },
+      "updated_at": { "type": "date" }
+    }
+  }
+}`
+
+// maxExchangeRates bounds rate listings; there are fewer than 200 ISO 4217
+// currencies to convert to.
+const maxExchangeRates = 10000
+
+// PutExchangeRate stores r, replacing the rate between the same currencies.
+func (r *elasticRepository) PutExchangeRate(ctx context.Context, er *ExchangeRate) error {
+	_, err := r.client.Index().
+		Index(exchangeRatesIndex).
+		Id(er.From + "-" + er.To).
+		BodyJson(er).
+		Refresh("wait_for").
+		Do(ctx)
+	return err
+}
+
+// ListExchangeRates returns the rates converting to the given currency, or
+// all rates if to is empty.
+func (r *elasticRepository) ListExchangeRates(ctx context.Context, to string) ([]*ExchangeRate, error) {
+	var query elastic.Query = elastic.NewMatchAllQuery()
+	if to != "" {
+		query = elastic.NewTermQuery("to", to)
+	}
+	res, err := r.client.Search().
+		Index(exchangeRatesIndex).
+		Query(query).
+		Sort("from", true).
+		Sort("to", true).
+		Size(maxExchangeRates).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rates := make([]*ExchangeRate, 0, len(res.Hits.Hits))
+	for _, hit := range res.Hits.Hits {
+		er := &ExchangeRate{}
+		if err := json.Unmarshal(hit.Source, er); err != nil {
+			return nil, err
+		}
+		rates = append(rates, er)
+	}
+	return rates, nil
 }
@@ -0,0 +1,164 @@
+package catalog
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"github.com/suryanshp1/go-microservice/money"
+)
+
+type ProductSort string
+
+const (
+	SortRelevance ProductSort = "relevance"
+	SortPriceAsc  ProductSort = "price_asc"
+	SortPriceDesc ProductSort = "price_desc"
+	SortNewest    ProductSort = "newest"
+)
+
+// DefaultPriceInterval is the width of the price histogram buckets when the
+// caller does not ask for one.
+var DefaultPriceInterval = money.New(1000, money.DefaultCurrency)
+
+var (
+	ErrInvalidSort   = errors.New("invalid product sort")
+	ErrInvalidFilter = errors.New("invalid product filter")
+	ErrInvalidCursor = errors.New("invalid or expired cursor")
+)
+
+func (s ProductSort) IsValid() bool {
+	switch s {
+	case SortRelevance, SortPriceAsc, SortPriceDesc, SortNewest:
+		return true
+	}
+	return false
+}
+
+// SearchQuery selects products by free text, price range and categories.
+// Empty fields do not filter. Pages are selected either by Skip, which only
+// reaches the first 10,000 results, or by After, the cursor of the last
+// product of the previous page. Cursors are tied to the sort they were
+// issued for. Currency, if set, converts the prices of the products found;
+// filters, sorting and facets still apply to the prices as stored.
+type SearchQuery struct {
+	Query         string
+	MinPrice      *money.Money
+	MaxPrice      *money.Money
+	Categories    []string
+	Sort          ProductSort
+	PriceInterval money.Money
+	Skip          uint64
+	Take          uint64
+	After         string
+	Currency      string
+}
+
+// validatePriceRange checks that the price range of q is in a single
+// currency and not empty. Only products priced in that currency match it.
+func (q *SearchQuery) validatePriceRange() error {
+	for _, m := range []*money.Money{q.MinPrice, q.MaxPrice} {
+		if m != nil && !m.Valid() {
+			return ErrInvalidFilter
+		}
+	}
+	if q.MinPrice != nil && q.MaxPrice != nil {
+		if c, err := q.MinPrice.Cmp(*q.MaxPrice); err != nil || c > 0 {
+			return ErrInvalidFilter
+		}
+	}
+	return nil
+}
+
+// SearchResult is one page of matching products, the total number of
+// matches and the facets of the whole result set. Cursors holds the cursor
+// of each product; EndCursor, the cursor of the last one, continues with
+// the next page.
+type SearchResult struct {
+	Products    []*Product
+	Cursors     []string
+	Total       int64
+	Facets      Facets
+	EndCursor   string
+	HasNextPage bool
+}
+
+// Facets summarize a search result. Each facet ignores its own filter, so
+// that the counts show what selecting another value would yield: category
+// counts take the price range into account but not the selected categories,
+// and the other way round.
+type Facets struct {
+	Prices     []PriceBucket
+	Categories []CategoryCount
+}
+
+// PriceBucket counts the products priced in [From, To).
+type PriceBucket struct {
+	From  money.Money
+	To    money.Money
+	Count int64
+}
+
+type CategoryCount struct {
+	Category string
+	Count    int64
+}
+
+const (
+	DefaultSuggestionLimit = 5
+	MaxSuggestionLimit     = 20
+)
+
+// Suggestions help completing a search while it is being typed: products
+// whose name starts with the typed words, the categories those products are
+// in, and spelling corrections of the typed text.
+type Suggestions struct {
+	Products    []ProductSuggestion
+	Categories  []CategorySuggestion
+	Corrections []string
+}
+
+type ProductSuggestion struct {
+	ProductID string
+	Name      string
+}
+
+// CategorySuggestion is a category with the number of suggested products
+// found in it.
+type CategorySuggestion struct {
+	CategoryID string
+	Name       string
+	Count      int64
+}
+
+// productCursor is the position of a product in a result list: the point in
+// time the list was read from and the sort values of the product, which end
+// with a tiebreaker so that positions are unique. Products on the first page
+// are read without a point in time, so their cursors hold their offset in
+// the list instead.
+type productCursor struct {
+	PIT    string        `json:"p,omitempty"`
+	Sort   ProductSort   `json:"s"`
+	Values []interface{} `json:"v,omitempty"`
+	Offset int           `json:"o,omitempty"`
+}
+
+func (c productCursor) encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeProductCursor(s string, sort ProductSort) (*productCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	c := &productCursor{}
+	if err := json.Unmarshal(b, c); err != nil || c.Sort != sort {
+		return nil, ErrInvalidCursor
+	}
+	if (c.PIT == "" || len(c.Values) == 0) && (c.PIT != "" || c.Offset <= 0) {
+		return nil, ErrInvalidCursor
+	}
+	return c, nil
+}
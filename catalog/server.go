@@ -4,68 +4,645 @@ package catalog
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
 	"net"
+	"time"
 
+	"github.com/suryanshp1/go-microservice/account"
 	"github.com/suryanshp1/go-microservice/catalog/pb"
+	"github.com/suryanshp1/go-microservice/money"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 )
 
 type grpcServer struct {
 	pb.UnimplementedCatalogServiceServer
-	service Service
+	service       Service
+	accountClient *account.Client
 }
 
-func ListenGRPC(s Service, port int) error {
+func ListenGRPC(s Service, accountURL string, verifier *account.TokenVerifier, port int) error {
+	accountClient, err := account.NewClient(accountURL)
+	if err != nil {
+		return err
+	}
+
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
 	if err != nil {
+		accountClient.Close()
 		panic(err)
 	}
-	serv := grpc.NewServer()
-	pb.RegisterCatalogServiceServer(serv, &grpcServer{service: s})
+	serv := grpc.NewServer(
+		grpc.UnaryInterceptor(account.UnaryServerInterceptor(verifier)),
+		grpc.StreamInterceptor(account.StreamServerInterceptor(verifier)),
+	)
+	pb.RegisterCatalogServiceServer(serv, &grpcServer{service: s, accountClient: accountClient})
 	reflection.Register(serv)
 	return serv.Serve(lis)
 }
 
 func (s *grpcServer) PostProduct(ctx context.Context, req *pb.PostProductRequest) (*pb.PostProductResponse, error) {
-	p, err := s.service.PostProduct(ctx, req.Name, req.Description, req.Price)
+	if err := s.accountClient.Authorize(ctx, account.PermissionProductWrite); err != nil {
+		return nil, err
+	}
+
+	variants := []Variant{}
+	for _, v := range req.Variants {
+		variants = append(variants, variantFromProto(v))
+	}
+	p, err := s.service.PostProduct(
+		ctx,
+		req.Name,
+		req.Description,
+		moneyFromProto(req.Price),
+		req.Categories,
+		optionsFromProto(req.Options),
+		variants,
+	)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return &pb.PostProductResponse{Product: productToProto(p)}, nil
+}
+
+func (s *grpcServer) UpdateProduct(ctx context.Context, req *pb.UpdateProductRequest) (*pb.UpdateProductResponse, error) {
+	if err := s.accountClient.Authorize(ctx, account.PermissionProductWrite); err != nil {
+		return nil, err
+	}
+
+	// Without a mask every field is replaced.
+	paths := []string{"name", "description", "price", "categories", "options"}
+	if req.UpdateMask != nil {
+		paths = req.UpdateMask.Paths
+	}
+	update := ProductUpdate{}
+	for _, path := range paths {
+		switch path {
+		case "name":
+			update.Name = &req.Name
+		case "description":
+			update.Description = &req.Description
+		case "price":
+			price := moneyFromProto(req.Price)
+			update.Price = &price
+		case "categories":
+			update.Categories = &req.Categories
+		case "options":
+			options := optionsFromProto(req.Options)
+			update.Options = &options
+		default:
+			return nil, status.Errorf(codes.InvalidArgument, "field %q cannot be updated", path)
+		}
+	}
+
+	p, err := s.service.UpdateProduct(ctx, req.Id, update, req.Version)
 	if err != nil {
 		log.Println(err)
+		return nil, toStatus(err)
+	}
+	return &pb.UpdateProductResponse{Product: productToProto(p)}, nil
+}
+
+func (s *grpcServer) DeleteProduct(ctx context.Context, req *pb.DeleteProductRequest) (*pb.DeleteProductResponse, error) {
+	if err := s.accountClient.Authorize(ctx, account.PermissionProductWrite); err != nil {
 		return nil, err
 	}
 
-	return &pb.PostProductResponse{Product: &pb.Product{Id: p.ID, Name: p.Name, Description: p.Description, Price: p.Price}}, nil
+	p, err := s.service.DeleteProduct(ctx, req.Id, req.Version)
+	if err != nil {
+		log.Println(err)
+		return nil, toStatus(err)
+	}
+	return &pb.DeleteProductResponse{Product: productToProto(p)}, nil
 }
 
 func (s *grpcServer) GetProduct(ctx context.Context, req *pb.GetProductRequest) (*pb.GetProductResponse, error) {
 	p, err := s.service.GetProduct(ctx, req.Id)
+	if err == nil && req.Currency != "" {
+		err = s.service.ConvertPrices(ctx, []*Product{p}, req.Currency)
+	}
 	if err != nil {
 		log.Println(err)
-		return nil, err
+		return nil, toStatus(err)
 	}
-	return &pb.GetProductResponse{Product: &pb.Product{Id: p.ID, Name: p.Name, Description: p.Description, Price: p.Price}}, nil
+	return &pb.GetProductResponse{Product: productToProto(p)}, nil
 }
 
 func (s *grpcServer) GetProducts(ctx context.Context, req *pb.GetProductsRequest) (*pb.GetProductsResponse, error) {
-	var res []*Product
-	var err error
-	if req.Query != "" {
-		res, err = s.service.SearchProducts(ctx, req.Query, req.Skip, req.Take)
-	} else if len(req.Ids) != 0 {
-		res, err = s.service.GetProductsByIDs(ctx, req.Ids)
-	} else {
-		res, err = s.service.GetProducts(ctx, req.Skip, req.Take)
+	if len(req.Ids) != 0 {
+		res, err := s.service.GetProductsByIDs(ctx, req.Ids)
+		if err == nil && req.Currency != "" {
+			err = s.service.ConvertPrices(ctx, res, req.Currency)
+		}
+		if err != nil {
+			log.Println(err)
+			return nil, toStatus(err)
+		}
+		products := make([]*pb.Product, 0, len(res))
+		for _, p := range res {
+			products = append(products, productToProto(p))
+		}
+		return &pb.GetProductsResponse{Products: products, Total: int64(len(products))}, nil
+	}
+
+	res, err := s.service.SearchProducts(ctx, SearchQuery{
+		Query:         req.Query,
+		MinPrice:      optionalMoneyFromProto(req.MinPrice),
+		MaxPrice:      optionalMoneyFromProto(req.MaxPrice),
+		Categories:    req.Categories,
+		Sort:          ProductSort(req.Sort),
+		PriceInterval: moneyFromProto(req.PriceInterval),
+		Skip:          req.Skip,
+		Take:          req.Take,
+		After:         req.After,
+		Currency:      req.Currency,
+	})
+	if err != nil {
+		log.Println(err)
+		return nil, toStatus(err)
+	}
+
+	products := make([]*pb.Product, 0, len(res.Products))
+	for _, p := range res.Products {
+		products = append(products, productToProto(p))
+	}
+	facets := &pb.Facets{}
+	for _, b := range res.Facets.Prices {
+		facets.Prices = append(facets.Prices, &pb.PriceBucket{
+			From:  moneyToProto(b.From),
+			To:    moneyToProto(b.To),
+			Count: b.Count,
+		})
+	}
+	for _, c := range res.Facets.Categories {
+		facets.Categories = append(facets.Categories, &pb.CategoryCount{Category: c.Category, Count: c.Count})
+	}
+	return &pb.GetProductsResponse{
+		Products:    products,
+		Total:       res.Total,
+		Facets:      facets,
+		Cursors:     res.Cursors,
+		EndCursor:   res.EndCursor,
+		HasNextPage: res.HasNextPage,
+	}, nil
+}
+
+func (s *grpcServer) SuggestProducts(ctx context.Context, req *pb.SuggestProductsRequest) (*pb.SuggestProductsResponse, error) {
+	suggestions, err := s.service.SuggestProducts(ctx, req.Prefix, int(req.Limit))
+	if err != nil {
+		log.Println(err)
+		return nil, toStatus(err)
+	}
+
+	res := &pb.SuggestProductsResponse{Corrections: suggestions.Corrections}
+	for _, p := range suggestions.Products {
+		res.Products = append(res.Products, &pb.SuggestProductsResponse_Product{ProductId: p.ProductID, Name: p.Name})
+	}
+	for _, c := range suggestions.Categories {
+		res.Categories = append(res.Categories, &pb.SuggestProductsResponse_Category{
+			CategoryId: c.CategoryID,
+			Name:       c.Name,
+			Count:      c.Count,
+		})
+	}
+	return res, nil
+}
+
+// ImportProducts writes the streamed products in batches of
+// ImportBatchSize. Rows that cannot be imported are reported in the response
+// without stopping the import. If a batch cannot be written the import stops
+// with an error; the batches before it stay imported, and importing the
+// same rows again updates them rather than creating duplicates.
+func (s *grpcServer) ImportProducts(stream pb.CatalogService_ImportProductsServer) error {
+	ctx := stream.Context()
+	if err := s.accountClient.Authorize(ctx, account.PermissionProductWrite); err != nil {
+		return err
+	}
+
+	summary := &ImportSummary{}
+	batch := []ImportRow{}
+	flush := func() error {
+		results, err := s.service.ImportProducts(ctx, batch)
+		if err != nil {
+			log.Println(err)
+			return toStatus(err)
+		}
+		for _, r := range results {
+			r.Row = len(summary.Results) + 1
+			summary.add(r)
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		batch = append(batch, ImportRow{
+			ExternalSKU: req.ExternalSku,
+			Name:        req.Name,
+			Description: req.Description,
+			Price:       moneyFromProto(req.Price),
+			Categories:  req.Categories,
+		})
+		if len(batch) == ImportBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if len(batch) > 0 {
+		if err := flush(); err != nil {
+			return err
+		}
+	}
+
+	res := &pb.ImportProductsResponse{
+		Created: uint32(summary.Created),
+		Updated: uint32(summary.Updated),
+		Failed:  uint32(summary.Failed),
+	}
+	for _, r := range summary.Results {
+		res.Results = append(res.Results, &pb.ImportProductsResponse_Result{
+			Row:       uint32(r.Row),
+			ProductId: r.ProductID,
+			Created:   r.Created,
+			Error:     r.Error,
+		})
+	}
+	return stream.SendAndClose(res)
+}
+
+// ExportProducts streams the matching products, a page per message.
+func (s *grpcServer) ExportProducts(req *pb.ExportProductsRequest, stream pb.CatalogService_ExportProductsServer) error {
+	ctx := stream.Context()
+	if err := s.accountClient.Authorize(ctx, account.PermissionProductWrite); err != nil {
+		return err
+	}
+
+	q := SearchQuery{
+		Query:      req.Query,
+		MinPrice:   optionalMoneyFromProto(req.MinPrice),
+		MaxPrice:   optionalMoneyFromProto(req.MaxPrice),
+		Categories: req.Categories,
+	}
+	err := s.service.ExportProducts(ctx, q, func(products []*Product) error {
+		res := &pb.ExportProductsResponse{}
+		for _, p := range products {
+			res.Products = append(res.Products, productToProto(p))
+		}
+		return stream.Send(res)
+	})
+	if err != nil {
+		log.Println(err)
+		return toStatus(err)
+	}
+	return nil
+}
+
+func (s *grpcServer) AddVariant(ctx context.Context, req *pb.AddVariantRequest) (*pb.AddVariantResponse, error) {
+	if err := s.accountClient.Authorize(ctx, account.PermissionProductWrite); err != nil {
+		return nil, err
+	}
+	if req.Variant == nil {
+		return nil, status.Error(codes.InvalidArgument, ErrInvalidVariant.Error())
+	}
+
+	p, err := s.service.AddVariant(ctx, req.ProductId, variantFromProto(req.Variant), req.Version)
+	if err != nil {
+		log.Println(err)
+		return nil, toStatus(err)
+	}
+	return &pb.AddVariantResponse{Product: productToProto(p)}, nil
+}
+
+func (s *grpcServer) UpdateVariant(ctx context.Context, req *pb.UpdateVariantRequest) (*pb.UpdateVariantResponse, error) {
+	if err := s.accountClient.Authorize(ctx, account.PermissionProductWrite); err != nil {
+		return nil, err
+	}
+	if req.Variant == nil {
+		return nil, status.Error(codes.InvalidArgument, ErrInvalidVariant.Error())
+	}
+
+	p, err := s.service.UpdateVariant(ctx, req.ProductId, variantFromProto(req.Variant), req.Version)
+	if err != nil {
+		log.Println(err)
+		return nil, toStatus(err)
+	}
+	return &pb.UpdateVariantResponse{Product: productToProto(p)}, nil
+}
+
+func (s *grpcServer) RemoveVariant(ctx context.Context, req *pb.RemoveVariantRequest) (*pb.RemoveVariantResponse, error) {
+	if err := s.accountClient.Authorize(ctx, account.PermissionProductWrite); err != nil {
+		return nil, err
+	}
+
+	p, err := s.service.RemoveVariant(ctx, req.ProductId, req.VariantId, req.Version)
+	if err != nil {
+		log.Println(err)
+		return nil, toStatus(err)
+	}
+	return &pb.RemoveVariantResponse{Product: productToProto(p)}, nil
+}
+
+func (s *grpcServer) AdjustStock(ctx context.Context, req *pb.AdjustStockRequest) (*pb.AdjustStockResponse, error) {
+	if err := s.accountClient.Authorize(ctx, account.PermissionProductWrite); err != nil {
+		return nil, err
+	}
+
+	p, err := s.service.AdjustStock(ctx, req.ProductId, req.VariantId, req.Delta)
+	if err != nil {
+		log.Println(err)
+		return nil, toStatus(err)
+	}
+	return &pb.AdjustStockResponse{Product: productToProto(p)}, nil
+}
+
+// ReserveStock is left to services placing orders; customers holding stock
+// themselves could keep it from everyone else.
+func (s *grpcServer) ReserveStock(ctx context.Context, req *pb.ReserveStockRequest) (*pb.ReserveStockResponse, error) {
+	if err := s.accountClient.Authorize(ctx, account.PermissionStockReserve); err != nil {
+		return nil, err
+	}
+
+	lines := []ReservationLine{}
+	for _, l := range req.Lines {
+		lines = append(lines, ReservationLine{ProductID: l.ProductId, VariantID: l.VariantId, Quantity: l.Quantity})
+	}
+	ttl := time.Duration(req.TtlSeconds) * time.Second
+	r, err := s.service.ReserveStock(ctx, account.ClaimsFromContext(ctx).AccountID(), lines, ttl)
+	if err != nil {
+		log.Println(err)
+		return nil, toStatus(err)
+	}
+	return &pb.ReserveStockResponse{Reservation: reservationToProto(r)}, nil
+}
+
+func (s *grpcServer) CommitReservation(ctx context.Context, req *pb.CommitReservationRequest) (*pb.CommitReservationResponse, error) {
+	if err := s.authorizeReservation(ctx, req.ReservationId); err != nil {
+		return nil, err
+	}
+
+	r, err := s.service.CommitReservation(ctx, req.ReservationId)
+	if err != nil {
+		log.Println(err)
+		return nil, toStatus(err)
+	}
+	return &pb.CommitReservationResponse{Reservation: reservationToProto(r)}, nil
+}
+
+func (s *grpcServer) ReleaseReservation(ctx context.Context, req *pb.ReleaseReservationRequest) (*pb.ReleaseReservationResponse, error) {
+	if err := s.authorizeReservation(ctx, req.ReservationId); err != nil {
+		return nil, err
+	}
+
+	r, err := s.service.ReleaseReservation(ctx, req.ReservationId)
+	if err != nil {
+		log.Println(err)
+		return nil, toStatus(err)
+	}
+	return &pb.ReleaseReservationResponse{Reservation: reservationToProto(r)}, nil
+}
+
+// authorizeReservation lets the account that made a reservation, and catalog
+// managers, settle it. Anyone else is told it does not exist.
+func (s *grpcServer) authorizeReservation(ctx context.Context, id string) error {
+	claims := account.ClaimsFromContext(ctx)
+	if claims == nil {
+		return status.Error(codes.Unauthenticated, "authentication required")
+	}
+
+	r, err := s.service.GetReservation(ctx, id)
+	if err != nil {
+		return toStatus(err)
+	}
+	if r.AccountID == claims.AccountID() {
+		return nil
+	}
+	allowed, err := s.accountClient.CheckPermission(ctx, claims.AccountID(), account.PermissionProductWrite)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return toStatus(ErrNotFound)
+	}
+	return nil
+}
+
+func (s *grpcServer) CreateCategory(ctx context.Context, req *pb.CreateCategoryRequest) (*pb.CreateCategoryResponse, error) {
+	if err := s.accountClient.Authorize(ctx, account.PermissionProductWrite); err != nil {
+		return nil, err
+	}
+
+	c, err := s.service.CreateCategory(ctx, req.Name, req.ParentId)
+	if err != nil {
+		log.Println(err)
+		return nil, toStatus(err)
+	}
+	return &pb.CreateCategoryResponse{Category: categoryToProto(c)}, nil
+}
+
+func (s *grpcServer) RenameCategory(ctx context.Context, req *pb.RenameCategoryRequest) (*pb.RenameCategoryResponse, error) {
+	if err := s.accountClient.Authorize(ctx, account.PermissionProductWrite); err != nil {
+		return nil, err
+	}
+
+	c, err := s.service.RenameCategory(ctx, req.Id, req.Name)
+	if err != nil {
+		log.Println(err)
+		return nil, toStatus(err)
+	}
+	return &pb.RenameCategoryResponse{Category: categoryToProto(c)}, nil
+}
+
+func (s *grpcServer) MoveCategory(ctx context.Context, req *pb.MoveCategoryRequest) (*pb.MoveCategoryResponse, error) {
+	if err := s.accountClient.Authorize(ctx, account.PermissionProductWrite); err != nil {
+		return nil, err
+	}
+
+	c, err := s.service.MoveCategory(ctx, req.Id, req.ParentId)
+	if err != nil {
+		log.Println(err)
+		return nil, toStatus(err)
 	}
+	return &pb.MoveCategoryResponse{Category: categoryToProto(c)}, nil
+}
 
+func (s *grpcServer) GetCategory(ctx context.Context, req *pb.GetCategoryRequest) (*pb.GetCategoryResponse, error) {
+	c, err := s.service.GetCategory(ctx, req.Id)
 	if err != nil {
 		log.Println(err)
+		return nil, toStatus(err)
+	}
+	return &pb.GetCategoryResponse{Category: categoryToProto(c)}, nil
+}
+
+func (s *grpcServer) GetCategories(ctx context.Context, req *pb.GetCategoriesRequest) (*pb.GetCategoriesResponse, error) {
+	res, err := s.service.GetCategories(ctx, req.ParentId)
+	if err != nil {
+		log.Println(err)
+		return nil, toStatus(err)
+	}
+
+	categories := make([]*pb.Category, 0, len(res))
+	for _, c := range res {
+		categories = append(categories, categoryToProto(c))
+	}
+	return &pb.GetCategoriesResponse{Categories: categories}, nil
+}
+
+func (s *grpcServer) SetExchangeRate(ctx context.Context, req *pb.SetExchangeRateRequest) (*pb.SetExchangeRateResponse, error) {
+	if err := s.accountClient.Authorize(ctx, account.PermissionProductWrite); err != nil {
 		return nil, err
 	}
 
-	products := make([]*pb.Product, 0, len(res))
-	for _, p := range res {
-		products = append(products, &pb.Product{Id: p.ID, Name: p.Name, Description: p.Description, Price: p.Price})
+	r, err := s.service.SetExchangeRate(ctx, req.From, req.To, req.Rate)
+	if err != nil {
+		log.Println(err)
+		return nil, toStatus(err)
+	}
+	return &pb.SetExchangeRateResponse{ExchangeRate: exchangeRateToProto(r)}, nil
+}
+
+func (s *grpcServer) GetExchangeRates(ctx context.Context, req *pb.GetExchangeRatesRequest) (*pb.GetExchangeRatesResponse, error) {
+	res, err := s.service.GetExchangeRates(ctx)
+	if err != nil {
+		log.Println(err)
+		return nil, toStatus(err)
+	}
+
+	rates := make([]*pb.ExchangeRate, 0, len(res))
+	for _, r := range res {
+		rates = append(rates, exchangeRateToProto(r))
+	}
+	return &pb.GetExchangeRatesResponse{ExchangeRates: rates}, nil
+}
+
+func productToProto(p *Product) *pb.Product {
+	pp := &pb.Product{
+		Id:          p.ID,
+		Name:        p.Name,
+		Description: p.Description,
+		Price:       moneyToProto(p.Price),
+		Stock:       p.Stock,
+		Available:   p.Available(),
+		Version:     p.Version,
+		Categories:  p.Categories,
+		Options:     optionsToProto(p.Options),
+		ExternalSku: p.ExternalSKU,
+	}
+	for _, v := range p.Variants {
+		pp.Variants = append(pp.Variants, variantToProto(v))
+	}
+	if !p.CreatedAt.IsZero() {
+		pp.CreatedAt, _ = p.CreatedAt.MarshalBinary()
+	}
+	if p.DeletedAt != nil {
+		pp.DeletedAt, _ = p.DeletedAt.MarshalBinary()
+	}
+	if p.ExchangeRate != nil {
+		pp.ExchangeRate = exchangeRateToProto(p.ExchangeRate)
+	}
+	return pp
+}
+
+func optionsToProto(options []ProductOption) []*pb.ProductOption {
+	po := []*pb.ProductOption{}
+	for _, o := range options {
+		po = append(po, &pb.ProductOption{Name: o.Name, Values: o.Values})
+	}
+	return po
+}
+
+func exchangeRateToProto(r *ExchangeRate) *pb.ExchangeRate {
+	updatedAt, _ := r.UpdatedAt.MarshalBinary()
+	return &pb.ExchangeRate{
+		From:      r.From,
+		To:        r.To,
+		Rate:      r.Value,
+		UpdatedAt: updatedAt,
+	}
+}
+
+func moneyToProto(m money.Money) *pb.Money {
+	return &pb.Money{Amount: m.Amount, Currency: m.Currency}
+}
+
+func optionalMoneyToProto(m *money.Money) *pb.Money {
+	if m == nil {
+		return nil
+	}
+	return moneyToProto(*m)
+}
+
+func variantToProto(v Variant) *pb.Variant {
+	pv := &pb.Variant{
+		Id:        v.ID,
+		Sku:       v.SKU,
+		Price:     optionalMoneyToProto(v.Price),
+		Stock:     v.Stock,
+		Available: v.Available(),
+	}
+	for _, o := range v.Options {
+		pv.Options = append(pv.Options, &pb.OptionValue{Name: o.Name, Value: o.Value})
+	}
+	return pv
+}
+
+func reservationToProto(r *Reservation) *pb.Reservation {
+	lines := []*pb.Reservation_Line{}
+	for _, l := range r.Lines {
+		lines = append(lines, &pb.Reservation_Line{ProductId: l.ProductID, VariantId: l.VariantID, Quantity: l.Quantity})
+	}
+	createdAt, _ := r.CreatedAt.MarshalBinary()
+	expiresAt, _ := r.ExpiresAt.MarshalBinary()
+	return &pb.Reservation{
+		Id:        r.ID,
+		AccountId: r.AccountID,
+		Lines:     lines,
+		Status:    string(r.Status),
+		CreatedAt: createdAt,
+		ExpiresAt: expiresAt,
+	}
+}
+
+func toStatus(err error) error {
+	switch err {
+	case ErrNotFound:
+		return status.Error(codes.NotFound, err.Error())
+	case ErrInvalidReservation, ErrInvalidProduct, ErrInvalidVersion, ErrInvalidSort, ErrInvalidFilter, ErrInvalidCursor,
+		ErrInvalidCategory, ErrUnknownCategory, ErrCategoryCycle,
+		ErrInvalidVariant, ErrInvalidOptions, ErrVariantRequired, ErrInvalidExchangeRate:
+		return status.Error(codes.InvalidArgument, err.Error())
+	case ErrDuplicateVariant:
+		return status.Error(codes.AlreadyExists, err.Error())
+	case ErrVariantReserved, ErrStockNotPerVariant, ErrExchangeRateNotFound:
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case ErrVersionConflict:
+		return status.Error(codes.Aborted, err.Error())
+	case ErrInsufficientStock, ErrInvalidStockLevel, ErrReservationNotActive, ErrReservationExpired:
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return err
+}
+
+func categoryToProto(c *Category) *pb.Category {
+	createdAt, _ := c.CreatedAt.MarshalBinary()
+	return &pb.Category{
+		Id:        c.ID,
+		Name:      c.Name,
+		ParentId:  c.ParentID,
+		Path:      c.Path,
+		CreatedAt: createdAt,
 	}
-	return &pb.GetProductsResponse{Products: products}, nil
 }
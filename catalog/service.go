@@ -2,23 +2,90 @@ package catalog
 
 import (
 	"context"
+	"log"
+	"strings"
+	"time"
 
 	"github.com/segmentio/ksuid"
+	"github.com/suryanshp1/go-microservice/money"
 )
 
 type Service interface {
-	PostProduct(ctx context.Context, name, description string, price float64) (*Product, error)
+	PostProduct(
+		ctx context.Context,
+		name, description string,
+		price money.Money,
+		categories []string,
+		options []ProductOption,
+		variants []Variant,
+	) (*Product, error)
+	UpdateProduct(ctx context.Context, id string, update ProductUpdate, version string) (*Product, error)
+	DeleteProduct(ctx context.Context, id string, version string) (*Product, error)
 	GetProduct(ctx context.Context, id string) (*Product, error)
-	GetProducts(ctx context.Context, skip uint64, take uint64) ([]*Product, error)
 	GetProductsByIDs(ctx context.Context, ids []string) ([]*Product, error)
-	SearchProducts(ctx context.Context, query string, skip uint64, take uint64) ([]*Product, error)
+	SearchProducts(ctx context.Context, q SearchQuery) (*SearchResult, error)
+	ExportProducts(ctx context.Context, q SearchQuery, fn func([]*Product) error) error
+	SuggestProducts(ctx context.Context, prefix string, limit int) (*Suggestions, error)
+	ImportProducts(ctx context.Context, rows []ImportRow) ([]ImportResult, error)
+	AddVariant(ctx context.Context, productID string, v Variant, version string) (*Product, error)
+	UpdateVariant(ctx context.Context, productID string, v Variant, version string) (*Product, error)
+	RemoveVariant(ctx context.Context, productID, variantID, version string) (*Product, error)
+	AdjustStock(ctx context.Context, productID, variantID string, delta int64) (*Product, error)
+	ReserveStock(ctx context.Context, accountID string, lines []ReservationLine, ttl time.Duration) (*Reservation, error)
+	GetReservation(ctx context.Context, id string) (*Reservation, error)
+	CommitReservation(ctx context.Context, id string) (*Reservation, error)
+	ReleaseReservation(ctx context.Context, id string) (*Reservation, error)
+	ReleaseExpiredReservations(ctx context.Context) (int, error)
+	CreateCategory(ctx context.Context, name, parentID string) (*Category, error)
+	RenameCategory(ctx context.Context, id, name string) (*Category, error)
+	MoveCategory(ctx context.Context, id, parentID string) (*Category, error)
+	GetCategory(ctx context.Context, id string) (*Category, error)
+	GetCategories(ctx context.Context, parentID string) ([]*Category, error)
+	SetExchangeRate(ctx context.Context, from, to, rate string) (*ExchangeRate, error)
+	GetExchangeRates(ctx context.Context) ([]*ExchangeRate, error)
+	ConvertPrices(ctx context.Context, products []*Product, currency string) error
 }
 
+// Product is an item of the catalog. Version changes whenever the product
+// does, stock changes included, and is used to detect conflicting edits.
+// ExternalSKU identifies products imported from a supplier catalog.
+// Deleted products have DeletedAt set; they are kept so that orders can still
+// refer to them but no longer show up in listings. ExchangeRate is set on
+// products whose prices were converted from the currency they are priced in.
 type Product struct {
-	ID          string  `json:"id"`
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
+	ID          string          `json:"id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Price       money.Money     `json:"price"`
+	Stock       int64           `json:"stock"`
+	Reserved    int64           `json:"reserved"`
+	Categories  []string        `json:"categories"`
+	Options     []ProductOption `json:"options"`
+	Variants    []Variant       `json:"variants"`
+	ExternalSKU string          `json:"external_sku,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	Version     string          `json:"-"`
+	DeletedAt   *time.Time      `json:"deleted_at,omitempty"`
+
+	ExchangeRate *ExchangeRate `json:"exchange_rate,omitempty"`
+}
+
+// ProductUpdate lists the fields to change in an update. Nil fields are left
+// as they are.
+type ProductUpdate struct {
+	Name        *string
+	Description *string
+	Price       *money.Money
+	Categories  *[]string
+	Options     *[]ProductOption
+}
+
+// Available is the stock that is neither reserved nor sold.
+func (p *Product) Available() int64 {
+	if p.Reserved >= p.Stock {
+		return 0
+	}
+	return p.Stock - p.Reserved
 }
 
 type catalogService struct {
@@ -29,11 +96,52 @@ func NewService(repository Repository) Service {
 	return &catalogService{repository: repository}
 }
 
-func (s *catalogService) PostProduct(ctx context.Context, name, description string, price float64) (*Product, error) {
+// PostProduct creates a product. Its variants start without stock.
+func (s *catalogService) PostProduct(
+	ctx context.Context,
+	name, description string,
+	price money.Money,
+	categories []string,
+	options []ProductOption,
+	variants []Variant,
+) (*Product, error) {
+	if categories == nil {
+		categories = []string{}
+	}
+	if options == nil {
+		options = []ProductOption{}
+	}
+	if variants == nil {
+		variants = []Variant{}
+	}
+	if !price.Valid() || price.IsNegative() {
+		return nil, ErrInvalidProduct
+	}
+	if err := s.checkCategories(ctx, categories); err != nil {
+		return nil, err
+	}
+	if err := validateOptions(options); err != nil {
+		return nil, err
+	}
+	if err := validateVariants(options, price, variants); err != nil {
+		return nil, err
+	}
+	for i := range variants {
+		if err := s.checkSKU(ctx, "", variants[i].SKU); err != nil {
+			return nil, err
+		}
+		variants[i].ID = ksuid.New().String()
+		variants[i].Stock = 0
+		variants[i].Reserved = 0
+	}
 	p := &Product{
 		Name:        name,
 		Description: description,
 		Price:       price,
+		Categories:  categories,
+		Options:     options,
+		Variants:    variants,
+		CreatedAt:   time.Now().UTC(),
 		ID:          ksuid.New().String(),
 	}
 	if err := s.repository.PutProduct(ctx, p); err != nil {
@@ -42,24 +150,840 @@ func (s *catalogService) PostProduct(ctx context.Context, name, description stri
 	return p, nil
 }
 
-func (s *catalogService) GetProduct(ctx context.Context, id string) (*Product, error) {
-	return s.repository.GetProductByID(ctx, id)
+// UpdateProduct changes the fields set in update. If version is not empty the
+// update fails with ErrVersionConflict unless the product is still at that
+// version.
+func (s *catalogService) UpdateProduct(ctx context.Context, id string, update ProductUpdate, version string) (*Product, error) {
+	if update.Name != nil && *update.Name == "" {
+		return nil, ErrInvalidProduct
+	}
+	if update.Price != nil && (!update.Price.Valid() || update.Price.IsNegative()) {
+		return nil, ErrInvalidProduct
+	}
+
+	p, version, err := s.getProductForUpdate(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]interface{}{}
+	if update.Name != nil {
+		fields["name"] = *update.Name
+	}
+	if update.Description != nil {
+		fields["description"] = *update.Description
+	}
+	price := p.Price
+	if update.Price != nil {
+		price = *update.Price
+		fields["price"] = price
+	}
+	if update.Categories != nil {
+		categories := *update.Categories
+		if categories == nil {
+			categories = []string{}
+		}
+		if err := s.checkCategories(ctx, categories); err != nil {
+			return nil, err
+		}
+		fields["categories"] = categories
+	}
+	options := p.Options
+	if update.Options != nil {
+		options = *update.Options
+		if options == nil {
+			options = []ProductOption{}
+		}
+		if err := validateOptions(options); err != nil {
+			return nil, err
+		}
+		fields["options"] = options
+	}
+	// Existing variants have to fit the new options and price.
+	if err := validateVariants(options, price, p.Variants); err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return p, nil
+	}
+	return s.repository.UpdateProduct(ctx, id, fields, version)
 }
 
-func (s *catalogService) GetProducts(ctx context.Context, skip uint64, take uint64) ([]*Product, error) {
-	if take > 100 || (take == 0 && skip == 0) {
-		take = 100
+// DeleteProduct soft deletes a product. Like UpdateProduct it checks version
+// if one is given.
+func (s *catalogService) DeleteProduct(ctx context.Context, id string, version string) (*Product, error) {
+	_, version, err := s.getProductForUpdate(ctx, id, version)
+	if err != nil {
+		return nil, err
 	}
-	return s.repository.ListProducts(ctx, skip, take)
+	return s.repository.UpdateProduct(ctx, id, map[string]interface{}{
+		"deleted_at": time.Now().UTC(),
+	}, version)
+}
+
+func (s *catalogService) GetProduct(ctx context.Context, id string) (*Product, error) {
+	return s.repository.GetProductByID(ctx, id)
 }
 
 func (s *catalogService) GetProductsByIDs(ctx context.Context, ids []string) ([]*Product, error) {
 	return s.repository.ListProductsWithIDs(ctx, ids)
 }
 
-func (s *catalogService) SearchProducts(ctx context.Context, query string, skip uint64, take uint64) ([]*Product, error) {
-	if take > 100 || (take == 0 && skip == 0) {
-		take = 100
+func (s *catalogService) SearchProducts(ctx context.Context, q SearchQuery) (*SearchResult, error) {
+	if q.Take > 100 || (q.Take == 0 && q.Skip == 0) {
+		q.Take = 100
+	}
+	if q.Sort == "" {
+		q.Sort = SortRelevance
+	}
+	if !q.Sort.IsValid() {
+		return nil, ErrInvalidSort
+	}
+	if q.PriceInterval.Amount <= 0 {
+		q.PriceInterval = DefaultPriceInterval
+	}
+	if !q.PriceInterval.Valid() {
+		return nil, ErrInvalidFilter
+	}
+	if err := q.validatePriceRange(); err != nil {
+		return nil, err
+	}
+	if q.After != "" && q.Skip > 0 {
+		return nil, ErrInvalidCursor
+	}
+
+	if len(q.Categories) > 0 {
+		var err error
+		if q.Categories, err = s.expandCategories(ctx, q.Categories); err != nil {
+			return nil, err
+		}
+		if len(q.Categories) == 0 {
+			return &SearchResult{
+				Products: []*Product{},
+				Cursors:  []string{},
+				Facets:   Facets{Prices: []PriceBucket{}, Categories: []CategoryCount{}},
+			}, nil
+		}
+	}
+	res, err := s.repository.SearchProducts(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	if q.Currency != "" {
+		if err := s.ConvertPrices(ctx, res.Products, q.Currency); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// ExportProducts passes all products matching the query, price range and
+// categories of q to fn, a page at a time. The other fields of q are
+// ignored. Products are read from a point in time, so changes made during
+// the export do not show up in it.
+func (s *catalogService) ExportProducts(ctx context.Context, q SearchQuery, fn func([]*Product) error) error {
+	if err := q.validatePriceRange(); err != nil {
+		return err
+	}
+	if len(q.Categories) > 0 {
+		var err error
+		if q.Categories, err = s.expandCategories(ctx, q.Categories); err != nil {
+			return err
+		}
+		if len(q.Categories) == 0 {
+			return nil
+		}
+	}
+	return s.repository.ExportProducts(ctx, q, fn)
+}
+
+// expandCategories returns the IDs of the given categories and all of their
+// descendants, as products filed under a subcategory belong to its
+// ancestors too. Unknown categories are left out.
+func (s *catalogService) expandCategories(ctx context.Context, ids []string) ([]string, error) {
+	categories, err := s.repository.ListCategoriesWithIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	expanded := []string{}
+	for _, c := range categories {
+		descendants, err := s.repository.ListCategoriesUnder(ctx, c.Path)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range descendants {
+			expanded = append(expanded, d.ID)
+		}
+	}
+	return expanded, nil
+}
+
+// SuggestProducts returns up to limit product and category suggestions for
+// the text typed so far, and spelling corrections that differ from it.
+func (s *catalogService) SuggestProducts(ctx context.Context, prefix string, limit int) (*Suggestions, error) {
+	prefix = strings.TrimSpace(prefix)
+	if prefix == "" {
+		return &Suggestions{
+			Products:    []ProductSuggestion{},
+			Categories:  []CategorySuggestion{},
+			Corrections: []string{},
+		}, nil
+	}
+	if limit <= 0 {
+		limit = DefaultSuggestionLimit
+	}
+	if limit > MaxSuggestionLimit {
+		limit = MaxSuggestionLimit
+	}
+
+	suggestions, err := s.repository.SuggestProducts(ctx, prefix, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Several products can share a name; suggest it once.
+	seen := map[string]bool{}
+	products := []ProductSuggestion{}
+	for _, p := range suggestions.Products {
+		key := strings.ToLower(p.Name)
+		if !seen[key] {
+			seen[key] = true
+			products = append(products, p)
+		}
+	}
+	suggestions.Products = products
+
+	corrections := []string{}
+	for _, c := range suggestions.Corrections {
+		if !strings.EqualFold(c, prefix) {
+			corrections = append(corrections, c)
+		}
+	}
+	suggestions.Corrections = corrections
+
+	if len(suggestions.Categories) > 0 {
+		ids := []string{}
+		for _, c := range suggestions.Categories {
+			ids = append(ids, c.CategoryID)
+		}
+		categories, err := s.repository.ListCategoriesWithIDs(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		names := map[string]string{}
+		for _, c := range categories {
+			names[c.ID] = c.Name
+		}
+		named := []CategorySuggestion{}
+		for _, c := range suggestions.Categories {
+			if name, ok := names[c.CategoryID]; ok {
+				c.Name = name
+				named = append(named, c)
+			}
+		}
+		suggestions.Categories = named
+	}
+	return suggestions, nil
+}
+
+// ImportProducts creates or updates a batch of products, see ImportRow. The
+// results are in the order of rows, with Row left for the caller to number.
+// Rows that are invalid or refer to unknown categories fail on their own;
+// an error is only returned if the batch could not be written at all.
+func (s *catalogService) ImportProducts(ctx context.Context, rows []ImportRow) ([]ImportResult, error) {
+	results := make([]ImportResult, len(rows))
+	skus := []string{}
+	categoryIDs := []string{}
+	for i := range rows {
+		if err := rows[i].validate(); err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		skus = append(skus, rows[i].ExternalSKU)
+		categoryIDs = append(categoryIDs, rows[i].Categories...)
+	}
+
+	known := map[string]bool{}
+	if len(categoryIDs) > 0 {
+		categories, err := s.repository.ListCategoriesWithIDs(ctx, categoryIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range categories {
+			known[c.ID] = true
+		}
+	}
+	existing := map[string]string{}
+	if len(skus) > 0 {
+		var err error
+		if existing, err = s.repository.FindProductIDsByExternalSKU(ctx, skus); err != nil {
+			return nil, err
+		}
+	}
+	// Variants of updated products have to fit the new price.
+	current := map[string]*Product{}
+	if len(existing) > 0 {
+		ids := make([]string, 0, len(existing))
+		for _, id := range existing {
+			ids = append(ids, id)
+		}
+		products, err := s.repository.ListProductsWithIDs(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range products {
+			current[p.ID] = p
+		}
+	}
+
+	writes := []ProductWrite{}
+	// written lists the rows of each write, and merged the write of each
+	// product written to.
+	written := [][]int{}
+	merged := map[string]int{}
+	now := time.Now().UTC()
+	for i, row := range rows {
+		if results[i].Error != "" {
+			continue
+		}
+		for _, id := range row.Categories {
+			if !known[id] {
+				results[i].Error = ErrUnknownCategory.Error()
+				break
+			}
+		}
+		if results[i].Error != "" {
+			continue
+		}
+
+		categories := row.Categories
+		if categories == nil {
+			categories = []string{}
+		}
+		p := &Product{
+			Name:        row.Name,
+			Description: row.Description,
+			Price:       row.Price,
+			Categories:  categories,
+			Options:     []ProductOption{},
+			Variants:    []Variant{},
+			ExternalSKU: row.ExternalSKU,
+			CreatedAt:   now,
+		}
+		id, ok := existing[row.ExternalSKU]
+		if !ok {
+			id = ksuid.New().String()
+			existing[row.ExternalSKU] = id
+		}
+		if c := current[id]; c != nil {
+			if err := validateVariants(c.Options, row.Price, c.Variants); err != nil {
+				results[i].Error = err.Error()
+				continue
+			}
+			// The product must not change between the check and the write.
+			p.Version = c.Version
+		}
+		p.ID = id
+		// A SKU repeated within the batch is written once, with the fields
+		// of its last row, as each write of a product changes its version.
+		if j, ok := merged[id]; ok {
+			p.CreatedAt = writes[j].Product.CreatedAt
+			writes[j].Product = p
+			written[j] = append(written[j], i)
+			continue
+		}
+		merged[id] = len(writes)
+		writes = append(writes, ProductWrite{Product: p, Create: !ok})
+		written = append(written, []int{i})
+	}
+	if len(writes) == 0 {
+		return results, nil
+	}
+
+	errs, err := s.repository.BulkPutProducts(ctx, writes)
+	if err != nil {
+		return nil, err
+	}
+	for j, rows := range written {
+		for k, i := range rows {
+			if errs[j] != nil {
+				results[i].Error = errs[j].Error()
+				continue
+			}
+			results[i].ProductID = writes[j].Product.ID
+			// Later rows of a SKU update the product created by its first.
+			results[i].Created = writes[j].Create && k == 0
+		}
+	}
+	return results, nil
+}
+
+// AddVariant adds a variant to a product. The variant gets a new ID and no
+// stock.
+func (s *catalogService) AddVariant(ctx context.Context, productID string, v Variant, version string) (*Product, error) {
+	p, version, err := s.getProductForUpdate(ctx, productID, version)
+	if err != nil {
+		return nil, err
+	}
+	if len(p.Variants) == 0 && p.Stock > 0 {
+		return nil, ErrStockNotPerVariant
+	}
+
+	v.ID = ksuid.New().String()
+	v.Stock = 0
+	v.Reserved = 0
+	variants := append(append([]Variant{}, p.Variants...), v)
+	if err := validateVariants(p.Options, p.Price, variants); err != nil {
+		return nil, err
+	}
+	if err := s.checkSKU(ctx, p.ID, v.SKU); err != nil {
+		return nil, err
+	}
+	return s.repository.UpdateProduct(ctx, productID, map[string]interface{}{"variants": variants}, version)
+}
+
+// UpdateVariant replaces the SKU, option values and price of the variant
+// with v's ID. Its stock is left alone.
+func (s *catalogService) UpdateVariant(ctx context.Context, productID string, v Variant, version string) (*Product, error) {
+	p, version, err := s.getProductForUpdate(ctx, productID, version)
+	if err != nil {
+		return nil, err
+	}
+	existing := p.Variant(v.ID)
+	if existing == nil {
+		return nil, ErrNotFound
+	}
+
+	v.Stock = existing.Stock
+	v.Reserved = existing.Reserved
+	variants := []Variant{}
+	for _, other := range p.Variants {
+		if other.ID == v.ID {
+			other = v
+		}
+		variants = append(variants, other)
+	}
+	if err := validateVariants(p.Options, p.Price, variants); err != nil {
+		return nil, err
+	}
+	if v.SKU != existing.SKU {
+		if err := s.checkSKU(ctx, p.ID, v.SKU); err != nil {
+			return nil, err
+		}
+	}
+	return s.repository.UpdateProduct(ctx, productID, map[string]interface{}{"variants": variants}, version)
+}
+
+// RemoveVariant removes a variant and its stock from a product. Variants
+// with reserved stock cannot be removed.
+func (s *catalogService) RemoveVariant(ctx context.Context, productID, variantID, version string) (*Product, error) {
+	p, version, err := s.getProductForUpdate(ctx, productID, version)
+	if err != nil {
+		return nil, err
+	}
+	v := p.Variant(variantID)
+	if v == nil {
+		return nil, ErrNotFound
+	}
+	if v.Reserved > 0 {
+		return nil, ErrVariantReserved
+	}
+
+	variants := []Variant{}
+	for _, other := range p.Variants {
+		if other.ID != variantID {
+			variants = append(variants, other)
+		}
+	}
+	return s.repository.UpdateProduct(ctx, productID, map[string]interface{}{
+		"variants": variants,
+		"stock":    p.Stock - v.Stock,
+	}, version)
+}
+
+// getProductForUpdate returns a product that is not deleted and the version
+// to update it at: the given one, or the current one if none is given.
+func (s *catalogService) getProductForUpdate(ctx context.Context, id, version string) (*Product, string, error) {
+	p, err := s.repository.GetProductByID(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+	if p.DeletedAt != nil {
+		return nil, "", ErrNotFound
+	}
+	if version == "" {
+		version = p.Version
+	}
+	return p, version, nil
+}
+
+// checkSKU makes sure no product other than productID has a variant with
+// the given SKU.
+func (s *catalogService) checkSKU(ctx context.Context, productID, sku string) error {
+	owner, err := s.repository.FindVariantBySKU(ctx, sku)
+	if err == ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if owner != productID {
+		return ErrDuplicateVariant
+	}
+	return nil
+}
+
+// checkVariant makes sure stock of a product with variants is only changed
+// through one of its variants.
+func checkVariant(p *Product, variantID string) error {
+	if variantID == "" && len(p.Variants) > 0 {
+		return ErrVariantRequired
+	}
+	if variantID != "" && p.Variant(variantID) == nil {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// AdjustStock changes the stock of a product, or of one of its variants if
+// variantID is set.
+func (s *catalogService) AdjustStock(ctx context.Context, productID, variantID string, delta int64) (*Product, error) {
+	p, err := s.repository.GetProductByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVariant(p, variantID); err != nil {
+		return nil, err
+	}
+	return s.repository.AdjustStock(ctx, productID, variantID, delta)
+}
+
+// ReserveStock reserves every line or none of them.
+func (s *catalogService) ReserveStock(
+	ctx context.Context,
+	accountID string,
+	lines []ReservationLine,
+	ttl time.Duration,
+) (*Reservation, error) {
+	if len(lines) == 0 {
+		return nil, ErrInvalidReservation
+	}
+	for _, l := range lines {
+		if l.ProductID == "" || l.Quantity == 0 {
+			return nil, ErrInvalidReservation
+		}
+	}
+	ids := []string{}
+	for _, l := range lines {
+		ids = append(ids, l.ProductID)
+	}
+	products, err := s.repository.ListProductsWithIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range products {
+		for _, l := range lines {
+			if l.ProductID != p.ID {
+				continue
+			}
+			if err := checkVariant(p, l.VariantID); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if ttl <= 0 {
+		ttl = DefaultReservationTTL
+	}
+	if ttl > MaxReservationTTL {
+		ttl = MaxReservationTTL
+	}
+
+	now := time.Now().UTC()
+	held := 0
+	r := &Reservation{
+		ID:        ksuid.New().String(),
+		AccountID: accountID,
+		Lines:     lines,
+		HeldLines: &held,
+		Status:    ReservationActive,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+	// The reservation is stored before any stock is held and records every
+	// line once its stock is held. A reservation that was never finished,
+	// e.g. because the service crashed, gives back exactly the stock it
+	// holds when it expires.
+	if err := s.repository.PutReservation(ctx, r); err != nil {
+		return nil, err
+	}
+	for i, l := range lines {
+		if err := s.repository.ReserveStock(ctx, l.ProductID, l.VariantID, l.Quantity); err != nil {
+			s.abandonReservation(ctx, r)
+			return nil, err
+		}
+		held = i + 1
+		if err := s.repository.UpdateReservationHeld(ctx, r.ID, held); err != nil {
+			// The line is held but maybe not recorded. It is given back only
+			// if the reservation can be released right away, as stock held
+			// for too long is better than stock released twice.
+			s.abandonReservation(ctx, r)
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// abandonReservation releases a reservation that could not hold all of its
+// stock and gives back the lines that it did hold.
+func (s *catalogService) abandonReservation(ctx context.Context, r *Reservation) {
+	if err := s.repository.UpdateReservationStatus(ctx, r.ID, ReservationActive, ReservationReleased); err != nil {
+		// Left to expire, which gives back the lines it recorded as held.
+		log.Printf("failed to release reservation %s: %v", r.ID, err)
+		return
+	}
+	r.Status = ReservationReleased
+	for _, l := range r.heldLines() {
+		if err := s.repository.UnreserveStock(ctx, l.ProductID, l.VariantID, l.Quantity); err != nil {
+			log.Printf("failed to unreserve %d of product %s: %v", l.Quantity, l.ProductID, err)
+		}
+	}
+}
+
+func (s *catalogService) GetReservation(ctx context.Context, id string) (*Reservation, error) {
+	return s.repository.GetReservation(ctx, id)
+}
+
+// CommitReservation removes the reserved stock from the inventory.
+// Committing a reservation again returns it unchanged, so callers can retry.
+func (s *catalogService) CommitReservation(ctx context.Context, id string) (*Reservation, error) {
+	r, err := s.repository.GetReservation(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if r.Status == ReservationCommitted {
+		return r, nil
+	}
+	if r.IsExpired(time.Now()) {
+		if _, err := s.finishReservation(ctx, r, ReservationExpired); err != nil {
+			return nil, err
+		}
+		return nil, ErrReservationExpired
+	}
+	return s.finishReservation(ctx, r, ReservationCommitted)
+}
+
+// ReleaseReservation makes the reserved stock available again. Releasing a
+// reservation that was already released or expired returns it unchanged.
+func (s *catalogService) ReleaseReservation(ctx context.Context, id string) (*Reservation, error) {
+	r, err := s.repository.GetReservation(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if r.Status == ReservationReleased || r.Status == ReservationExpired {
+		return r, nil
+	}
+	return s.finishReservation(ctx, r, ReservationReleased)
+}
+
+// ReleaseExpiredReservations releases the stock held by reservations that
+// were neither committed nor released in time. It returns how many
+// reservations expired.
+func (s *catalogService) ReleaseExpiredReservations(ctx context.Context) (int, error) {
+	expired, err := s.repository.ListExpiredReservations(ctx, time.Now().UTC(), 100)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, r := range expired {
+		_, err := s.finishReservation(ctx, r, ReservationExpired)
+		if err == ErrReservationNotActive {
+			// Committed or released in the meantime.
+			continue
+		}
+		if err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// finishReservation moves an active reservation to its final status and
+// settles the reserved stock: committed stock is removed from the inventory,
+// anything else is made available again.
+func (s *catalogService) finishReservation(
+	ctx context.Context,
+	r *Reservation,
+	status ReservationStatus,
+) (*Reservation, error) {
+	// Flipping the status first guarantees the stock is settled only once,
+	// even if the same reservation is finished concurrently.
+	if err := s.repository.UpdateReservationStatus(ctx, r.ID, ReservationActive, status); err != nil {
+		return nil, err
+	}
+	r.Status = status
+
+	for _, l := range r.heldLines() {
+		var err error
+		if status == ReservationCommitted {
+			err = s.repository.CommitStock(ctx, l.ProductID, l.VariantID, l.Quantity)
+		} else {
+			err = s.repository.UnreserveStock(ctx, l.ProductID, l.VariantID, l.Quantity)
+		}
+		if err != nil {
+			log.Printf("failed to settle %d of product %s for reservation %s: %v", l.Quantity, l.ProductID, r.ID, err)
+		}
+	}
+	return r, nil
+}
+
+func (s *catalogService) CreateCategory(ctx context.Context, name, parentID string) (*Category, error) {
+	if name == "" {
+		return nil, ErrInvalidCategory
+	}
+
+	var parent *Category
+	if parentID != "" {
+		var err error
+		if parent, err = s.getParentCategory(ctx, parentID); err != nil {
+			return nil, err
+		}
+	}
+
+	c := &Category{
+		ID:        ksuid.New().String(),
+		Name:      name,
+		ParentID:  parentID,
+		CreatedAt: time.Now().UTC(),
+	}
+	c.Path = categoryPath(parent, c.ID)
+	if err := s.repository.PutCategory(ctx, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (s *catalogService) RenameCategory(ctx context.Context, id, name string) (*Category, error) {
+	if name == "" {
+		return nil, ErrInvalidCategory
+	}
+	return s.repository.RenameCategory(ctx, id, name)
+}
+
+// MoveCategory makes parentID the new parent of a category, or makes it a
+// root category if parentID is empty. Its descendants move along with it.
+func (s *catalogService) MoveCategory(ctx context.Context, id, parentID string) (*Category, error) {
+	c, err := s.repository.GetCategory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if c.ParentID == parentID {
+		return c, nil
+	}
+
+	var parent *Category
+	if parentID != "" {
+		if parent, err = s.getParentCategory(ctx, parentID); err != nil {
+			return nil, err
+		}
+		if c.Contains(parent) {
+			return nil, ErrCategoryCycle
+		}
+	}
+
+	if err := s.repository.MoveCategory(ctx, c, parentID, categoryPath(parent, c.ID)); err != nil {
+		return nil, err
+	}
+	return s.repository.GetCategory(ctx, id)
+}
+
+func (s *catalogService) GetCategory(ctx context.Context, id string) (*Category, error) {
+	return s.repository.GetCategory(ctx, id)
+}
+
+// GetCategories returns the children of a category, or the root categories
+// if parentID is empty.
+func (s *catalogService) GetCategories(ctx context.Context, parentID string) ([]*Category, error) {
+	return s.repository.ListChildCategories(ctx, parentID)
+}
+
+func (s *catalogService) getParentCategory(ctx context.Context, id string) (*Category, error) {
+	parent, err := s.repository.GetCategory(ctx, id)
+	if err == ErrNotFound {
+		return nil, ErrUnknownCategory
+	}
+	return parent, err
+}
+
+// checkCategories makes sure products are only filed under existing
+// categories.
+func (s *catalogService) checkCategories(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	categories, err := s.repository.ListCategoriesWithIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	found := map[string]bool{}
+	for _, c := range categories {
+		found[c.ID] = true
+	}
+	for _, id := range ids {
+		if !found[id] {
+			return ErrUnknownCategory
+		}
+	}
+	return nil
+}
+
+func (s *catalogService) SetExchangeRate(ctx context.Context, from, to, rate string) (*ExchangeRate, error) {
+	r, err := money.NewRate(strings.ToUpper(from), strings.ToUpper(to), rate)
+	if err != nil || r.From == r.To {
+		return nil, ErrInvalidExchangeRate
+	}
+	er := &ExchangeRate{Rate: r, UpdatedAt: time.Now().UTC()}
+	if err := s.repository.PutExchangeRate(ctx, er); err != nil {
+		return nil, err
+	}
+	return er, nil
+}
+
+func (s *catalogService) GetExchangeRates(ctx context.Context) ([]*ExchangeRate, error) {
+	return s.repository.ListExchangeRates(ctx, "")
+}
+
+// ConvertPrices converts the prices of products to currency, using the
+// stored rate from the currency each of them is priced in. Products already
+// priced in currency are left as they are. It fails with
+// ErrExchangeRateNotFound if a rate is missing.
+func (s *catalogService) ConvertPrices(ctx context.Context, products []*Product, currency string) error {
+	currency = strings.ToUpper(currency)
+	if !money.ValidCurrency(currency) {
+		return ErrInvalidFilter
+	}
+	var rates map[string]*ExchangeRate
+	for _, p := range products {
+		if p.Price.Currency == currency {
+			continue
+		}
+		if rates == nil {
+			list, err := s.repository.ListExchangeRates(ctx, currency)
+			if err != nil {
+				return err
+			}
+			rates = map[string]*ExchangeRate{}
+			for _, r := range list {
+				rates[r.From] = r
+			}
+		}
+		r, ok := rates[p.Price.Currency]
+		if !ok {
+			return ErrExchangeRateNotFound
+		}
+		if err := p.convertPrices(r); err != nil {
+			return err
+		}
 	}
-	return s.repository.SearchProducts(ctx, query, skip, take)
+	return nil
 }
@@ -0,0 +1,318 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/suryanshp1/go-microservice/money"
+)
+
+// fakeRepository keeps products and reservations in memory. Calls listed in
+// fail return the given error instead.
+type fakeRepository struct {
+	Repository
+	products     map[string]*Product
+	reserved     map[string]uint32
+	reservations map[string]Reservation
+	fail         map[string]error
+}
+
+func newFakeRepository(products ...*Product) *fakeRepository {
+	r := &fakeRepository{
+		products:     map[string]*Product{},
+		reserved:     map[string]uint32{},
+		reservations: map[string]Reservation{},
+		fail:         map[string]error{},
+	}
+	for _, p := range products {
+		r.products[p.ID] = p
+	}
+	return r
+}
+
+func (r *fakeRepository) ListProductsWithIDs(ctx context.Context, ids []string) ([]*Product, error) {
+	products := []*Product{}
+	for _, id := range ids {
+		if p, ok := r.products[id]; ok {
+			products = append(products, p)
+		}
+	}
+	return products, nil
+}
+
+func (r *fakeRepository) FindProductIDsByExternalSKU(ctx context.Context, skus []string) (map[string]string, error) {
+	ids := map[string]string{}
+	for _, p := range r.products {
+		for _, sku := range skus {
+			if p.ExternalSKU == sku {
+				ids[sku] = p.ID
+			}
+		}
+	}
+	return ids, nil
+}
+
+// BulkPutProducts writes like Elasticsearch does: every write bumps the
+// version and updates fail if the product is no longer at their version.
+func (r *fakeRepository) BulkPutProducts(ctx context.Context, writes []ProductWrite) ([]error, error) {
+	errs := make([]error, len(writes))
+	for i, w := range writes {
+		p := *w.Product
+		if c, ok := r.products[p.ID]; ok {
+			if w.Create || (p.Version != "" && p.Version != c.Version) {
+				errs[i] = ErrVersionConflict
+				continue
+			}
+			updated := *c
+			updated.Name, updated.Description, updated.Price, updated.Categories = p.Name, p.Description, p.Price, p.Categories
+			p = updated
+		}
+		version := 0
+		if c, ok := r.products[p.ID]; ok {
+			version, _ = strconv.Atoi(c.Version)
+		}
+		p.Version = strconv.Itoa(version + 1)
+		r.products[p.ID] = &p
+	}
+	return errs, nil
+}
+
+func (r *fakeRepository) ReserveStock(ctx context.Context, productID, variantID string, quantity uint32) error {
+	if err := r.fail["ReserveStock "+productID]; err != nil {
+		return err
+	}
+	r.reserved[productID] += quantity
+	return nil
+}
+
+func (r *fakeRepository) UnreserveStock(ctx context.Context, productID, variantID string, quantity uint32) error {
+	r.reserved[productID] -= min(quantity, r.reserved[productID])
+	return nil
+}
+
+func (r *fakeRepository) PutReservation(ctx context.Context, res *Reservation) error {
+	stored := *res
+	if res.HeldLines != nil {
+		held := *res.HeldLines
+		stored.HeldLines = &held
+	}
+	r.reservations[res.ID] = stored
+	return nil
+}
+
+func (r *fakeRepository) GetReservation(ctx context.Context, id string) (*Reservation, error) {
+	res, ok := r.reservations[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &res, nil
+}
+
+// held returns the stock reserved per product, leaving out products without
+// reserved stock.
+func (r *fakeRepository) held() map[string]uint32 {
+	held := map[string]uint32{}
+	for id, quantity := range r.reserved {
+		if quantity > 0 {
+			held[id] = quantity
+		}
+	}
+	return held
+}
+
+func (r *fakeRepository) UpdateReservationHeld(ctx context.Context, id string, heldLines int) error {
+	if err := r.fail["UpdateReservationHeld"]; err != nil {
+		return err
+	}
+	res := r.reservations[id]
+	if res.Status != ReservationActive {
+		return ErrReservationNotActive
+	}
+	res.HeldLines = &heldLines
+	r.reservations[id] = res
+	return nil
+}
+
+func (r *fakeRepository) UpdateReservationStatus(ctx context.Context, id string, from, to ReservationStatus) error {
+	if err := r.fail["UpdateReservationStatus"]; err != nil {
+		return err
+	}
+	res := r.reservations[id]
+	if res.Status != from {
+		return ErrReservationNotActive
+	}
+	res.Status = to
+	r.reservations[id] = res
+	return nil
+}
+
+func (r *fakeRepository) ListExpiredReservations(ctx context.Context, now time.Time, limit int) ([]*Reservation, error) {
+	expired := []*Reservation{}
+	for _, res := range r.reservations {
+		if res.IsExpired(now) {
+			res := res
+			expired = append(expired, &res)
+		}
+	}
+	return expired, nil
+}
+
+func TestReserveStockGivesBackOnlyHeldLines(t *testing.T) {
+	errDown := errors.New("elasticsearch is down")
+	lines := []ReservationLine{
+		{ProductID: "a", Quantity: 1},
+		{ProductID: "b", Quantity: 2},
+		{ProductID: "c", Quantity: 3},
+	}
+	tests := []struct {
+		name string
+		fail map[string]error
+		// reserved is what stays held after the reservation expired.
+		reserved map[string]uint32
+	}{
+		{
+			name:     "line out of stock",
+			fail:     map[string]error{"ReserveStock c": ErrInsufficientStock},
+			reserved: map[string]uint32{},
+		},
+		{
+			name:     "line out of stock and release fails",
+			fail:     map[string]error{"ReserveStock c": ErrInsufficientStock, "UpdateReservationStatus": errDown},
+			reserved: map[string]uint32{},
+		},
+		{
+			name:     "held line not recorded",
+			fail:     map[string]error{"UpdateReservationHeld": errDown},
+			reserved: map[string]uint32{},
+		},
+		{
+			name:     "held line not recorded and release fails",
+			fail:     map[string]error{"UpdateReservationHeld": errDown, "UpdateReservationStatus": errDown},
+			reserved: map[string]uint32{"a": 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := newFakeRepository(&Product{ID: "a"}, &Product{ID: "b"}, &Product{ID: "c"})
+			// Stock held by another reservation must survive.
+			repository.reserved["c"] = 5
+			repository.fail = tt.fail
+			s := NewService(repository)
+
+			if _, err := s.ReserveStock(context.Background(), "account", lines, time.Minute); err == nil {
+				t.Fatal("ReserveStock() succeeded, want an error")
+			}
+			delete(repository.fail, "UpdateReservationStatus")
+			for id, res := range repository.reservations {
+				res.ExpiresAt = time.Now().Add(-time.Second)
+				repository.reservations[id] = res
+			}
+			if _, err := s.ReleaseExpiredReservations(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			repository.reserved["c"] -= 5
+			if held := repository.held(); !reflect.DeepEqual(held, tt.reserved) {
+				t.Errorf("reserved = %v, want %v", held, tt.reserved)
+			}
+		})
+	}
+}
+
+func TestReleaseReservationStoredWithoutHeldLines(t *testing.T) {
+	repository := newFakeRepository()
+	repository.reserved = map[string]uint32{"a": 1, "b": 2}
+	repository.reservations["r"] = Reservation{
+		ID:        "r",
+		Lines:     []ReservationLine{{ProductID: "a", Quantity: 1}, {ProductID: "b", Quantity: 2}},
+		Status:    ReservationActive,
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+
+	if _, err := NewService(repository).ReleaseReservation(context.Background(), "r"); err != nil {
+		t.Fatal(err)
+	}
+	if held := repository.held(); len(held) > 0 {
+		t.Errorf("reserved = %v, want nothing", held)
+	}
+}
+
+func TestImportProductsRepeatedSKU(t *testing.T) {
+	repository := newFakeRepository(&Product{
+		ID:          "existing",
+		Name:        "Old",
+		Price:       money.New(100, "USD"),
+		ExternalSKU: "sku-1",
+		Version:     "7",
+	})
+	rows := []ImportRow{
+		{ExternalSKU: "sku-1", Name: "First", Price: money.New(200, "USD")},
+		{ExternalSKU: "sku-2", Name: "New", Price: money.New(300, "USD")},
+		{ExternalSKU: "sku-1", Name: "Second", Price: money.New(400, "USD")},
+		{ExternalSKU: "sku-2", Name: "Newer", Price: money.New(500, "USD")},
+		{ExternalSKU: "sku-1", Name: "Third", Price: money.New(600, "USD")},
+	}
+
+	results, err := NewService(repository).ImportProducts(context.Background(), rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	created := results[1].ProductID
+	want := []ImportResult{
+		{ProductID: "existing"},
+		{ProductID: created, Created: true},
+		{ProductID: "existing"},
+		{ProductID: created},
+		{ProductID: "existing"},
+	}
+	if created == "" || !reflect.DeepEqual(results, want) {
+		t.Fatalf("results = %+v, want %+v", results, want)
+	}
+	if p := repository.products["existing"]; p.Name != "Third" || p.Price != money.New(600, "USD") {
+		t.Errorf("existing product = %q at %v, want the last row", p.Name, p.Price)
+	}
+	if p := repository.products[created]; p.Name != "Newer" || p.Price != money.New(500, "USD") {
+		t.Errorf("created product = %q at %v, want the last row", p.Name, p.Price)
+	}
+}
+
+func TestImportProductsRepeatedSKUConflict(t *testing.T) {
+	repository := newFakeRepository(&Product{ID: "existing", Name: "Old", ExternalSKU: "sku-1", Version: "7"})
+	rows := []ImportRow{
+		{ExternalSKU: "sku-1", Name: "First", Price: money.New(200, "USD")},
+		{ExternalSKU: "sku-1", Name: "Second", Price: money.New(400, "USD")},
+	}
+	// Someone else changes the product while the batch is checked.
+	changed := *repository.products["existing"]
+	changed.Version = "8"
+
+	s := NewService(&changingRepository{fakeRepository: repository, changed: &changed})
+	results, err := s.ImportProducts(context.Background(), rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, r := range results {
+		if r.Error != ErrVersionConflict.Error() {
+			t.Errorf("row %d error = %q, want %q", i+1, r.Error, ErrVersionConflict)
+		}
+	}
+	if p := repository.products["existing"]; p.Name != "Old" {
+		t.Errorf("product name = %q, want it unchanged", p.Name)
+	}
+}
+
+// changingRepository replaces a product right after it was listed.
+type changingRepository struct {
+	*fakeRepository
+	changed *Product
+}
+
+func (r *changingRepository) ListProductsWithIDs(ctx context.Context, ids []string) ([]*Product, error) {
+	products, err := r.fakeRepository.ListProductsWithIDs(ctx, ids)
+	r.products[r.changed.ID] = r.changed
+	return products, err
+}
@@ -0,0 +1,124 @@
+package catalog
+
+import (
+	"errors"
+
+	"github.com/suryanshp1/go-microservice/money"
+)
+
+var (
+	ErrInvalidVariant     = errors.New("variant must have a SKU, one allowed value for each option of its product and a non-negative price in the product's currency")
+	ErrInvalidOptions     = errors.New("options must have distinct names and at least one value")
+	ErrDuplicateVariant   = errors.New("another variant has the same SKU or option values")
+	ErrVariantRequired    = errors.New("product has variants, a variant must be given")
+	ErrVariantReserved    = errors.New("variant has reserved stock")
+	ErrStockNotPerVariant = errors.New("product has stock of its own, remove it before adding variants")
+)
+
+// ProductOption is a dimension a product comes in, such as size or color,
+// with the values it can take.
+type ProductOption struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+// OptionValue picks one value of a product option.
+type OptionValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Variant is a purchasable version of a product, identified by its SKU and
+// its option values. Stock of products with variants is kept per variant;
+// the product's own stock and reserved counts are the sums over its
+// variants. Price overrides the product price if set.
+type Variant struct {
+	ID       string        `json:"id"`
+	SKU      string        `json:"sku"`
+	Options  []OptionValue `json:"options"`
+	Price    *money.Money  `json:"price,omitempty"`
+	Stock    int64         `json:"stock"`
+	Reserved int64         `json:"reserved"`
+}
+
+func (v *Variant) Available() int64 {
+	if v.Reserved >= v.Stock {
+		return 0
+	}
+	return v.Stock - v.Reserved
+}
+
+// Variant returns the variant of p with the given ID, or nil.
+func (p *Product) Variant(id string) *Variant {
+	for i := range p.Variants {
+		if p.Variants[i].ID == id {
+			return &p.Variants[i]
+		}
+	}
+	return nil
+}
+
+// PriceOf returns what the variant with the given ID costs, or the product
+// price if variantID is empty.
+func (p *Product) PriceOf(variantID string) money.Money {
+	if v := p.Variant(variantID); v != nil && v.Price != nil {
+		return *v.Price
+	}
+	return p.Price
+}
+
+func validateOptions(options []ProductOption) error {
+	names := map[string]bool{}
+	for _, o := range options {
+		if o.Name == "" || len(o.Values) == 0 || names[o.Name] {
+			return ErrInvalidOptions
+		}
+		names[o.Name] = true
+	}
+	return nil
+}
+
+// validateVariants checks that each variant picks exactly one allowed value
+// for every option, that its own price, if any, is in the currency of the
+// product price and that no two variants share a SKU or option values.
+func validateVariants(options []ProductOption, price money.Money, variants []Variant) error {
+	allowed := map[string]map[string]bool{}
+	for _, o := range options {
+		allowed[o.Name] = map[string]bool{}
+		for _, value := range o.Values {
+			allowed[o.Name][value] = true
+		}
+	}
+
+	skus := map[string]bool{}
+	combinations := map[string]bool{}
+	for _, v := range variants {
+		if v.SKU == "" || len(v.Options) != len(options) {
+			return ErrInvalidVariant
+		}
+		if v.Price != nil && (v.Price.Currency != price.Currency || v.Price.IsNegative()) {
+			return ErrInvalidVariant
+		}
+		picked := map[string]string{}
+		for _, ov := range v.Options {
+			if !allowed[ov.Name][ov.Value] {
+				return ErrInvalidVariant
+			}
+			if _, ok := picked[ov.Name]; ok {
+				return ErrInvalidVariant
+			}
+			picked[ov.Name] = ov.Value
+		}
+
+		key := ""
+		for _, o := range options {
+			key += o.Name + "=" + picked[o.Name] + ";"
+		}
+		if skus[v.SKU] || combinations[key] {
+			return ErrDuplicateVariant
+		}
+		skus[v.SKU] = true
+		combinations[key] = true
+	}
+	return nil
+}
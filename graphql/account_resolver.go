@@ -4,42 +4,68 @@ import (
 	"context"
 	"log"
 	"time"
+
+	"github.com/suryanshp1/go-microservice/order"
 )
 
 type accountResolver struct {
 	server *Server
 }
 
-func (r *accountResolver) Orders(ctx context.Context, obj *Account) ([]*Order, error) {
+func (r *accountResolver) Orders(
+	ctx context.Context,
+	obj *Account,
+	first *int,
+	after *string,
+	filter *OrderFilter,
+	sort *OrderSort,
+) (*OrderConnection, error) {
 	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
-	orderList, err := r.server.orderClient.GetOrdersForAccount(ctx, obj.ID)
+	q := order.Query{}
+	if first != nil {
+		q.First = *first
+	}
+	if after != nil {
+		q.After = *after
+	}
+	if sort != nil {
+		q.Sort = sort.sort()
+	}
+	if filter != nil {
+		if filter.CreatedAfter != nil {
+			q.Filter.CreatedAfter = *filter.CreatedAfter
+		}
+		if filter.CreatedBefore != nil {
+			q.Filter.CreatedBefore = *filter.CreatedBefore
+		}
+		for _, s := range filter.Status {
+			q.Filter.Statuses = append(q.Filter.Statuses, s.status())
+		}
+		q.Filter.MinTotal = filter.MinTotal
+		q.Filter.MaxTotal = filter.MaxTotal
+	}
+
+	page, err := r.server.orderClient.GetOrdersForAccount(ctx, obj.ID, q)
 	if err != nil {
 		log.Println(err)
-		return nil, err
+		return nil, grpcError(ctx, err)
 	}
 
-	var orders []*Order
-
-	for _, o := range orderList {
-		var products []*OrderedProduct
-		for _, p := range o.Products {
-			products = append(products, &OrderedProduct{
-				ID:          p.ID,
-				Name:        p.Name,
-				Price:       p.Price,
-				Description: p.Description,
-				Quantity:    int(p.Quantity),
-			})
-		}
-		orders = append(orders, &Order{
-			ID:         o.ID,
-			Products:   products,
-			TotalPrice: o.TotalPrice,
-			CreatedAt:  o.CreatedAt,
+	edges := []*OrderEdge{}
+	for _, e := range page.Edges {
+		edges = append(edges, &OrderEdge{
+			Cursor: e.Cursor,
+			Node:   newOrder(&e.Order),
 		})
 	}
-
-	return orders, nil
+	conn := &OrderConnection{
+		Edges:    edges,
+		PageInfo: &PageInfo{HasNextPage: page.HasNextPage},
+	}
+	if page.EndCursor != "" {
+		conn.PageInfo.EndCursor = &page.EndCursor
+	}
+	return conn, nil
 }
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/suryanshp1/go-microservice/catalog"
+)
+
+type categoryResolver struct {
+	server *Server
+}
+
+func (r *categoryResolver) Children(ctx context.Context, obj *Category) ([]*Category, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	children, err := r.server.catalogClient.GetCategories(ctx, obj.ID)
+	if err != nil {
+		log.Println(err)
+		return nil, grpcError(ctx, err)
+	}
+
+	categories := []*Category{}
+	for _, c := range children {
+		categories = append(categories, newCategory(c))
+	}
+	return categories, nil
+}
+
+func (r *categoryResolver) Products(
+	ctx context.Context,
+	obj *Category,
+	first *int,
+	after *string,
+	sort *ProductSort,
+) (*ProductConnection, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	q := catalog.SearchQuery{Categories: []string{obj.ID}}
+	if first != nil {
+		if *first < 0 {
+			return nil, ErrInvalidParameter
+		}
+		q.Take = uint64(*first)
+	}
+	if after != nil {
+		q.After = *after
+	}
+	if sort != nil {
+		q.Sort = sort.sort()
+	}
+
+	res, err := r.server.catalogClient.SearchProducts(ctx, q)
+	if err != nil {
+		log.Println(err)
+		return nil, grpcError(ctx, err)
+	}
+	return newProductConnection(res), nil
+}
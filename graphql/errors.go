@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+)
+
+// grpcError converts a gRPC status error into a GraphQL error. Field
+// violations from google.rpc.BadRequest details are exposed under the
+// "fieldViolations" extension so clients can point at the offending input.
+func grpcError(ctx context.Context, err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	gqlErr := &gqlerror.Error{
+		Path:    graphql.GetPath(ctx),
+		Message: st.Message(),
+		Extensions: map[string]interface{}{
+			"code": st.Code().String(),
+		},
+	}
+
+	violations := []map[string]string{}
+	for _, detail := range st.Details() {
+		if br, ok := detail.(*errdetails.BadRequest); ok {
+			for _, v := range br.FieldViolations {
+				violations = append(violations, map[string]string{
+					"field":       v.Field,
+					"description": v.Description,
+				})
+			}
+		}
+	}
+	if len(violations) > 0 {
+		gqlErr.Extensions["fieldViolations"] = violations
+	}
+
+	return gqlErr
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"github.com/99designs/gqlgen/graphql"
 	"github.com/suryanshp1/go-microservice/account"
+	"github.com/suryanshp1/go-microservice/cart"
 	"github.com/suryanshp1/go-microservice/catalog"
 	"github.com/suryanshp1/go-microservice/order"
 )
@@ -11,9 +12,10 @@ type Server struct {
 	accountClient *account.Client
 	catalogClient *catalog.Client
 	orderClient   *order.Client
+	cartClient    *cart.Client
 }
 
-func NewGraphQLServer(accountUrl, catalogUrl, orderUrl string) (*Server, error) {
+func NewGraphQLServer(accountUrl, catalogUrl, orderUrl, cartUrl string) (*Server, error) {
 	accountClient, err := account.NewClient(accountUrl)
 
 	if err != nil {
@@ -33,10 +35,19 @@ func NewGraphQLServer(accountUrl, catalogUrl, orderUrl string) (*Server, error)
 		return nil, err
 	}
 
+	cartClient, err := cart.NewClient(cartUrl)
+	if err != nil {
+		accountClient.Close()
+		catalogClient.Close()
+		orderClient.Close()
+		return nil, err
+	}
+
 	return &Server{
 		accountClient: accountClient,
 		catalogClient: catalogClient,
 		orderClient:   orderClient,
+		cartClient:    cartClient,
 	}, nil
 
 }
@@ -59,8 +70,18 @@ func (s *Server) Account() AccountResolver {
 	}
 }
 
+func (s *Server) Category() CategoryResolver {
+	return &categoryResolver{
+		server: s,
+	}
+}
+
 func (s *Server) ToExecutableSchema() graphql.ExecutableSchema {
 	return NewExecutableSchema(Config{
 		Resolvers: s,
+		Directives: DirectiveRoot{
+			Auth:    authDirective,
+			HasRole: hasRoleDirective,
+		},
 	})
 }
@@ -7,12 +7,15 @@ import (
 	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/playground"
 	"github.com/kelseyhightower/envconfig"
+	"github.com/suryanshp1/go-microservice/account"
 )
 
 type AppConfig struct {
 	AccountURL string `envconfig:"ACCOUNT_SERVICE_URL"`
 	CatalogURL string `envconfig:"CATALOG_SERVICE_URL"`
 	OrderURL   string `envconfig:"ORDER_SERVICE_URL"`
+	CartURL    string `envconfig:"CART_SERVICE_URL"`
+	PublicKey  string `envconfig:"TOKEN_PUBLIC_KEY"`
 }
 
 func main() {
@@ -33,19 +36,29 @@ func main() {
 	if cfg.OrderURL == "" {
 		log.Fatal("ORDER_SERVICE_URL is required but not set")
 	}
+	if cfg.CartURL == "" {
+		log.Fatal("CART_SERVICE_URL is required but not set")
+	}
+
+	publicKey, err := account.ParsePublicKey(cfg.PublicKey)
+	if err != nil {
+		log.Fatalf("Invalid TOKEN_PUBLIC_KEY: %v", err)
+	}
 
 	log.Printf("Connecting to services:")
 	log.Printf("  Account: %s", cfg.AccountURL)
 	log.Printf("  Catalog: %s", cfg.CatalogURL)
 	log.Printf("  Order:   %s", cfg.OrderURL)
+	log.Printf("  Cart:    %s", cfg.CartURL)
 
-	s, err := NewGraphQLServer(cfg.AccountURL, cfg.CatalogURL, cfg.OrderURL)
+	s, err := NewGraphQLServer(cfg.AccountURL, cfg.CatalogURL, cfg.OrderURL, cfg.CartURL)
 
 	if err != nil {
 		log.Fatalf("Failed to create GraphQL server: %v", err)
 	}
 
-	http.Handle("/graphql", handler.NewDefaultServer(s.ToExecutableSchema()))
+	auth := authMiddleware(account.NewTokenVerifier(publicKey))
+	http.Handle("/graphql", auth(handler.NewDefaultServer(s.ToExecutableSchema())))
 	http.Handle("/playground", playground.Handler("GraphQL Playground", "/graphql"))
 
 	log.Fatal(http.ListenAndServe(":8080", nil))
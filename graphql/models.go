@@ -1,7 +1,250 @@
 package main
 
+import (
+	"strings"
+	"time"
+
+	"github.com/suryanshp1/go-microservice/account"
+	"github.com/suryanshp1/go-microservice/cart"
+	"github.com/suryanshp1/go-microservice/catalog"
+	"github.com/suryanshp1/go-microservice/order"
+)
+
 type Account struct {
-	ID     string  `json:"id"`
-	Name   string  `json:"name"`
-	Orders []Order `json:"orders"`
+	ID     string        `json:"id"`
+	Name   string        `json:"name"`
+	Roles  []Role        `json:"roles"`
+	Status AccountStatus `json:"status"`
+}
+
+func newAccount(a *account.Account) *Account {
+	status := AccountStatus(strings.ToUpper(a.Status))
+	if !status.IsValid() {
+		status = AccountStatusActive
+	}
+	return &Account{
+		ID:     a.ID,
+		Name:   a.Name,
+		Roles:  rolesFromNames(a.Roles),
+		Status: status,
+	}
+}
+
+func newProduct(p *catalog.Product) *Product {
+	product := &Product{
+		ID:          p.ID,
+		Name:        p.Name,
+		Description: p.Description,
+		Price:       p.Price,
+		Stock:       int(p.Stock),
+		Available:   int(p.Available()),
+		InStock:     p.Available() > 0,
+		Version:     p.Version,
+		Categories:  p.Categories,
+		Options:     []*ProductOption{},
+		Variants:    []*Variant{},
+		ExternalSku: optionalString(p.ExternalSKU),
+		DeletedAt:   p.DeletedAt,
+	}
+	if p.ExchangeRate != nil {
+		product.ExchangeRate = newExchangeRate(p.ExchangeRate)
+	}
+	if !p.CreatedAt.IsZero() {
+		product.CreatedAt = &p.CreatedAt
+	}
+	for _, o := range p.Options {
+		product.Options = append(product.Options, &ProductOption{Name: o.Name, Values: o.Values})
+	}
+	for _, v := range p.Variants {
+		variant := &Variant{
+			ID:        v.ID,
+			Sku:       v.SKU,
+			Options:   []*OptionValue{},
+			Price:     p.PriceOf(v.ID),
+			Stock:     int(v.Stock),
+			Available: int(v.Available()),
+			InStock:   v.Available() > 0,
+		}
+		for _, o := range v.Options {
+			variant.Options = append(variant.Options, &OptionValue{Name: o.Name, Value: o.Value})
+		}
+		product.Variants = append(product.Variants, variant)
+	}
+	return product
+}
+
+func productOptions(in []*ProductOptionInput) []catalog.ProductOption {
+	options := []catalog.ProductOption{}
+	for _, o := range in {
+		options = append(options, catalog.ProductOption{Name: o.Name, Values: o.Values})
+	}
+	return options
+}
+
+func (in VariantInput) variant() catalog.Variant {
+	v := catalog.Variant{SKU: in.Sku, Options: []catalog.OptionValue{}, Price: in.Price}
+	for _, o := range in.Options {
+		v.Options = append(v.Options, catalog.OptionValue{Name: o.Name, Value: o.Value})
+	}
+	return v
+}
+
+type Category struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	ParentID  *string   `json:"parentId"`
+	Path      string    `json:"path"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func newCategory(c *catalog.Category) *Category {
+	category := &Category{
+		ID:        c.ID,
+		Name:      c.Name,
+		Path:      c.Path,
+		CreatedAt: c.CreatedAt,
+	}
+	if c.ParentID != "" {
+		category.ParentID = &c.ParentID
+	}
+	return category
+}
+
+func newProductConnection(r *catalog.SearchResult) *ProductConnection {
+	result := &ProductConnection{
+		Edges:    []*ProductEdge{},
+		PageInfo: &PageInfo{HasNextPage: r.HasNextPage},
+		Total:    int(r.Total),
+		Facets: &ProductFacets{
+			Prices:     []*PriceBucket{},
+			Categories: []*CategoryFacet{},
+		},
+	}
+	for i, p := range r.Products {
+		edge := &ProductEdge{Node: newProduct(p)}
+		if i < len(r.Cursors) {
+			edge.Cursor = r.Cursors[i]
+		}
+		result.Edges = append(result.Edges, edge)
+	}
+	if r.EndCursor != "" {
+		result.PageInfo.EndCursor = &r.EndCursor
+	}
+	for _, b := range r.Facets.Prices {
+		result.Facets.Prices = append(result.Facets.Prices, &PriceBucket{From: b.From, To: b.To, Count: int(b.Count)})
+	}
+	for _, c := range r.Facets.Categories {
+		result.Facets.Categories = append(result.Facets.Categories, &CategoryFacet{Category: c.Category, Count: int(c.Count)})
+	}
+	return result
+}
+
+func (s ProductSort) sort() catalog.ProductSort {
+	return catalog.ProductSort(strings.ToLower(string(s)))
+}
+
+func newOrder(o *order.Order) *Order {
+	var products []*OrderedProduct
+	for _, p := range o.Products {
+		product := &OrderedProduct{
+			ID:          p.ID,
+			VariantID:   optionalString(p.VariantID),
+			Sku:         optionalString(p.SKU),
+			Name:        p.Name,
+			Price:       p.Price,
+			Description: p.Description,
+			Quantity:    int(p.Quantity),
+		}
+		if r := p.ExchangeRate; r != nil {
+			product.ExchangeRate = &ExchangeRate{From: r.From, To: r.To, Rate: r.Value}
+		}
+		products = append(products, product)
+	}
+	history := []*OrderStatusChange{}
+	for _, c := range o.StatusHistory {
+		change := &OrderStatusChange{
+			To:        newOrderStatus(c.To),
+			ChangedBy: c.ChangedBy,
+			Reason:    c.Reason,
+			ChangedAt: c.ChangedAt,
+		}
+		if c.From != "" {
+			from := newOrderStatus(c.From)
+			change.From = &from
+		}
+		history = append(history, change)
+	}
+	return &Order{
+		ID:            o.ID,
+		Products:      products,
+		TotalPrice:    o.TotalPrice,
+		CreatedAt:     o.CreatedAt,
+		Status:        newOrderStatus(o.Status),
+		StatusHistory: history,
+	}
+}
+
+func newOrderStatus(s order.Status) OrderStatus {
+	return OrderStatus(strings.ToUpper(string(s)))
+}
+
+func (s OrderStatus) status() order.Status {
+	return order.Status(strings.ToLower(string(s)))
+}
+
+func (s OrderSort) sort() order.Sort {
+	return order.Sort(strings.ToLower(string(s)))
+}
+
+func newCart(c *cart.Cart) *Cart {
+	result := &Cart{
+		ID:        optionalString(c.ID),
+		SessionID: optionalString(c.SessionID),
+		Items:     []*CartItem{},
+		Total:     c.Pricing.Total,
+		Quantity:  int(c.Pricing.Quantity),
+		Ready:     len(c.Items) > 0 && c.Pricing.Problems == 0,
+	}
+	if !c.UpdatedAt.IsZero() {
+		result.UpdatedAt = &c.UpdatedAt
+	}
+	for _, item := range c.Items {
+		ci := &CartItem{
+			ProductID: item.ProductID,
+			VariantID: optionalString(item.VariantID),
+			Sku:       optionalString(item.Details.SKU),
+			Name:      item.Details.Name,
+			Quantity:  int(item.Quantity),
+			Price:     item.Details.Price,
+			Total:     item.Details.Total,
+			Available: int(item.Details.Available),
+		}
+		if r := item.Details.ExchangeRate; r != nil {
+			ci.ExchangeRate = &ExchangeRate{From: r.From, To: r.To, Rate: r.Value}
+		}
+		if item.Details.Problem != cart.ProblemNone {
+			problem := CartItemProblem(strings.ToUpper(string(item.Details.Problem)))
+			ci.Problem = &problem
+		}
+		if !item.AddedAt.IsZero() {
+			ci.AddedAt = &item.AddedAt
+		}
+		result.Items = append(result.Items, ci)
+	}
+	return result
+}
+
+func newExchangeRate(r *catalog.ExchangeRate) *ExchangeRate {
+	er := &ExchangeRate{From: r.From, To: r.To, Rate: r.Value}
+	if !r.UpdatedAt.IsZero() {
+		er.UpdatedAt = &r.UpdatedAt
+	}
+	return er
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
 }
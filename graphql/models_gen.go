@@ -3,39 +3,153 @@
 package main
 
 import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
 	"time"
+
+	"github.com/suryanshp1/go-microservice/money"
 )
 
 type AccountInput struct {
 	Name string `json:"name"`
 }
 
+type AuthPayload struct {
+	Account               *Account  `json:"account,omitempty"`
+	AccessToken           string    `json:"accessToken"`
+	AccessTokenExpiresAt  time.Time `json:"accessTokenExpiresAt"`
+	RefreshToken          string    `json:"refreshToken"`
+	RefreshTokenExpiresAt time.Time `json:"refreshTokenExpiresAt"`
+}
+
+type Cart struct {
+	ID        *string     `json:"id,omitempty"`
+	SessionID *string     `json:"sessionId,omitempty"`
+	Items     []*CartItem `json:"items"`
+	Total     money.Money `json:"total"`
+	Quantity  int         `json:"quantity"`
+	Ready     bool        `json:"ready"`
+	UpdatedAt *time.Time  `json:"updatedAt,omitempty"`
+}
+
+type CartItem struct {
+	ProductID    string           `json:"productId"`
+	VariantID    *string          `json:"variantId,omitempty"`
+	Sku          *string          `json:"sku,omitempty"`
+	Name         string           `json:"name"`
+	Quantity     int              `json:"quantity"`
+	Price        *money.Money     `json:"price,omitempty"`
+	Total        *money.Money     `json:"total,omitempty"`
+	Available    int              `json:"available"`
+	ExchangeRate *ExchangeRate    `json:"exchangeRate,omitempty"`
+	Problem      *CartItemProblem `json:"problem,omitempty"`
+	AddedAt      *time.Time       `json:"addedAt,omitempty"`
+}
+
+type CategoryFacet struct {
+	Category string `json:"category"`
+	Count    int    `json:"count"`
+}
+
+type CategoryInput struct {
+	Name     string  `json:"name"`
+	ParentID *string `json:"parentId,omitempty"`
+}
+
+type CategorySuggestion struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+type ExchangeRate struct {
+	From      string     `json:"from"`
+	To        string     `json:"to"`
+	Rate      string     `json:"rate"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
+
+type LoginInput struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
 type Mutation struct {
 }
 
+type OptionValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type OptionValueInput struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
 type Order struct {
-	ID         string            `json:"id"`
-	Products   []*OrderedProduct `json:"products"`
-	TotalPrice float64           `json:"totalPrice"`
-	CreatedAt  time.Time         `json:"createdAt"`
+	ID            string               `json:"id"`
+	Products      []*OrderedProduct    `json:"products"`
+	TotalPrice    money.Money          `json:"totalPrice"`
+	CreatedAt     time.Time            `json:"createdAt"`
+	Status        OrderStatus          `json:"status"`
+	StatusHistory []*OrderStatusChange `json:"statusHistory"`
+}
+
+type OrderConnection struct {
+	Edges    []*OrderEdge `json:"edges"`
+	PageInfo *PageInfo    `json:"pageInfo"`
+}
+
+type OrderEdge struct {
+	Cursor string `json:"cursor"`
+	Node   *Order `json:"node"`
+}
+
+type OrderFilter struct {
+	CreatedAfter  *time.Time    `json:"createdAfter,omitempty"`
+	CreatedBefore *time.Time    `json:"createdBefore,omitempty"`
+	Status        []OrderStatus `json:"status,omitempty"`
+	MinTotal      *money.Money  `json:"minTotal,omitempty"`
+	MaxTotal      *money.Money  `json:"maxTotal,omitempty"`
 }
 
 type OrderInput struct {
-	AccountID string               `json:"accountId"`
-	Products  []*OrderProductInput `json:"products"`
+	AccountID      string               `json:"accountId"`
+	Products       []*OrderProductInput `json:"products"`
+	IdempotencyKey *string              `json:"idempotencyKey,omitempty"`
 }
 
 type OrderProductInput struct {
-	ID       string `json:"id"`
-	Quantity int    `json:"quantity"`
+	ID        string  `json:"id"`
+	VariantID *string `json:"variantId,omitempty"`
+	Quantity  int     `json:"quantity"`
+}
+
+type OrderStatusChange struct {
+	From      *OrderStatus `json:"from,omitempty"`
+	To        OrderStatus  `json:"to"`
+	ChangedBy string       `json:"changedBy"`
+	Reason    string       `json:"reason"`
+	ChangedAt time.Time    `json:"changedAt"`
 }
 
 type OrderedProduct struct {
-	ID          string  `json:"id"`
-	Name        string  `json:"name"`
-	Price       float64 `json:"price"`
-	Quantity    int     `json:"quantity"`
-	Description string  `json:"description"`
+	ID           string        `json:"id"`
+	VariantID    *string       `json:"variantId,omitempty"`
+	Sku          *string       `json:"sku,omitempty"`
+	Name         string        `json:"name"`
+	Price        money.Money   `json:"price"`
+	ExchangeRate *ExchangeRate `json:"exchangeRate,omitempty"`
+	Quantity     int           `json:"quantity"`
+	Description  string        `json:"description"`
+}
+
+type PageInfo struct {
+	HasNextPage bool    `json:"hasNextPage"`
+	EndCursor   *string `json:"endCursor,omitempty"`
 }
 
 type PaginationInput struct {
@@ -43,18 +157,478 @@ type PaginationInput struct {
 	Take int `json:"take"`
 }
 
+type PriceBucket struct {
+	From  money.Money `json:"from"`
+	To    money.Money `json:"to"`
+	Count int         `json:"count"`
+}
+
 type Product struct {
-	ID          string  `json:"id"`
-	Name        string  `json:"name"`
-	Price       float64 `json:"price"`
-	Description string  `json:"description"`
+	ID           string           `json:"id"`
+	Name         string           `json:"name"`
+	Price        money.Money      `json:"price"`
+	Description  string           `json:"description"`
+	Stock        int              `json:"stock"`
+	Available    int              `json:"available"`
+	InStock      bool             `json:"inStock"`
+	Version      string           `json:"version"`
+	Categories   []string         `json:"categories"`
+	Options      []*ProductOption `json:"options"`
+	Variants     []*Variant       `json:"variants"`
+	ExternalSku  *string          `json:"externalSku,omitempty"`
+	ExchangeRate *ExchangeRate    `json:"exchangeRate,omitempty"`
+	CreatedAt    *time.Time       `json:"createdAt,omitempty"`
+	DeletedAt    *time.Time       `json:"deletedAt,omitempty"`
+}
+
+type ProductConnection struct {
+	Edges    []*ProductEdge `json:"edges"`
+	PageInfo *PageInfo      `json:"pageInfo"`
+	Total    int            `json:"total"`
+	Facets   *ProductFacets `json:"facets"`
+}
+
+type ProductEdge struct {
+	Cursor string   `json:"cursor"`
+	Node   *Product `json:"node"`
+}
+
+type ProductFacets struct {
+	Prices     []*PriceBucket   `json:"prices"`
+	Categories []*CategoryFacet `json:"categories"`
+}
+
+type ProductFilter struct {
+	MinPrice   *money.Money `json:"minPrice,omitempty"`
+	MaxPrice   *money.Money `json:"maxPrice,omitempty"`
+	Categories []string     `json:"categories,omitempty"`
 }
 
 type ProductInput struct {
-	Name        string  `json:"name"`
-	Price       float64 `json:"price"`
-	Description string  `json:"description"`
+	Name        string                `json:"name"`
+	Price       money.Money           `json:"price"`
+	Description string                `json:"description"`
+	Categories  []string              `json:"categories,omitempty"`
+	Options     []*ProductOptionInput `json:"options,omitempty"`
+	Variants    []*VariantInput       `json:"variants,omitempty"`
+}
+
+type ProductOption struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+type ProductOptionInput struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+type ProductSuggestion struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type ProductSuggestions struct {
+	Products   []*ProductSuggestion  `json:"products"`
+	Categories []*CategorySuggestion `json:"categories"`
+	DidYouMean []string              `json:"didYouMean"`
 }
 
 type Query struct {
 }
+
+type RegisterInput struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type UpdateAccountInput struct {
+	Name  *string `json:"name,omitempty"`
+	Email *string `json:"email,omitempty"`
+}
+
+type UpdateProductInput struct {
+	Name        *string               `json:"name,omitempty"`
+	Description *string               `json:"description,omitempty"`
+	Price       *money.Money          `json:"price,omitempty"`
+	Categories  []string              `json:"categories,omitempty"`
+	Options     []*ProductOptionInput `json:"options,omitempty"`
+	Version     *string               `json:"version,omitempty"`
+}
+
+type Variant struct {
+	ID        string         `json:"id"`
+	Sku       string         `json:"sku"`
+	Options   []*OptionValue `json:"options"`
+	Price     money.Money    `json:"price"`
+	Stock     int            `json:"stock"`
+	Available int            `json:"available"`
+	InStock   bool           `json:"inStock"`
+}
+
+type VariantInput struct {
+	Sku     string              `json:"sku"`
+	Options []*OptionValueInput `json:"options"`
+	Price   *money.Money        `json:"price,omitempty"`
+}
+
+type AccountStatus string
+
+const (
+	AccountStatusActive      AccountStatus = "ACTIVE"
+	AccountStatusDeactivated AccountStatus = "DEACTIVATED"
+	AccountStatusErased      AccountStatus = "ERASED"
+)
+
+var AllAccountStatus = []AccountStatus{
+	AccountStatusActive,
+	AccountStatusDeactivated,
+	AccountStatusErased,
+}
+
+func (e AccountStatus) IsValid() bool {
+	switch e {
+	case AccountStatusActive, AccountStatusDeactivated, AccountStatusErased:
+		return true
+	}
+	return false
+}
+
+func (e AccountStatus) String() string {
+	return string(e)
+}
+
+func (e *AccountStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = AccountStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid AccountStatus", str)
+	}
+	return nil
+}
+
+func (e AccountStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *AccountStatus) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e AccountStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type CartItemProblem string
+
+const (
+	CartItemProblemUnavailable       CartItemProblem = "UNAVAILABLE"
+	CartItemProblemVariantRequired   CartItemProblem = "VARIANT_REQUIRED"
+	CartItemProblemVariantNotFound   CartItemProblem = "VARIANT_NOT_FOUND"
+	CartItemProblemInsufficientStock CartItemProblem = "INSUFFICIENT_STOCK"
+	CartItemProblemCurrencyMismatch  CartItemProblem = "CURRENCY_MISMATCH"
+)
+
+var AllCartItemProblem = []CartItemProblem{
+	CartItemProblemUnavailable,
+	CartItemProblemVariantRequired,
+	CartItemProblemVariantNotFound,
+	CartItemProblemInsufficientStock,
+	CartItemProblemCurrencyMismatch,
+}
+
+func (e CartItemProblem) IsValid() bool {
+	switch e {
+	case CartItemProblemUnavailable, CartItemProblemVariantRequired, CartItemProblemVariantNotFound, CartItemProblemInsufficientStock, CartItemProblemCurrencyMismatch:
+		return true
+	}
+	return false
+}
+
+func (e CartItemProblem) String() string {
+	return string(e)
+}
+
+func (e *CartItemProblem) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = CartItemProblem(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid CartItemProblem", str)
+	}
+	return nil
+}
+
+func (e CartItemProblem) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *CartItemProblem) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e CartItemProblem) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type OrderSort string
+
+const (
+	OrderSortCreatedAtDesc OrderSort = "CREATED_AT_DESC"
+	OrderSortCreatedAtAsc  OrderSort = "CREATED_AT_ASC"
+	OrderSortTotalDesc     OrderSort = "TOTAL_DESC"
+	OrderSortTotalAsc      OrderSort = "TOTAL_ASC"
+)
+
+var AllOrderSort = []OrderSort{
+	OrderSortCreatedAtDesc,
+	OrderSortCreatedAtAsc,
+	OrderSortTotalDesc,
+	OrderSortTotalAsc,
+}
+
+func (e OrderSort) IsValid() bool {
+	switch e {
+	case OrderSortCreatedAtDesc, OrderSortCreatedAtAsc, OrderSortTotalDesc, OrderSortTotalAsc:
+		return true
+	}
+	return false
+}
+
+func (e OrderSort) String() string {
+	return string(e)
+}
+
+func (e *OrderSort) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = OrderSort(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid OrderSort", str)
+	}
+	return nil
+}
+
+func (e OrderSort) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *OrderSort) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e OrderSort) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type OrderStatus string
+
+const (
+	OrderStatusPending   OrderStatus = "PENDING"
+	OrderStatusConfirmed OrderStatus = "CONFIRMED"
+	OrderStatusPaid      OrderStatus = "PAID"
+	OrderStatusShipped   OrderStatus = "SHIPPED"
+	OrderStatusDelivered OrderStatus = "DELIVERED"
+	OrderStatusCancelled OrderStatus = "CANCELLED"
+	OrderStatusRefunded  OrderStatus = "REFUNDED"
+)
+
+var AllOrderStatus = []OrderStatus{
+	OrderStatusPending,
+	OrderStatusConfirmed,
+	OrderStatusPaid,
+	OrderStatusShipped,
+	OrderStatusDelivered,
+	OrderStatusCancelled,
+	OrderStatusRefunded,
+}
+
+func (e OrderStatus) IsValid() bool {
+	switch e {
+	case OrderStatusPending, OrderStatusConfirmed, OrderStatusPaid, OrderStatusShipped, OrderStatusDelivered, OrderStatusCancelled, OrderStatusRefunded:
+		return true
+	}
+	return false
+}
+
+func (e OrderStatus) String() string {
+	return string(e)
+}
+
+func (e *OrderStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = OrderStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid OrderStatus", str)
+	}
+	return nil
+}
+
+func (e OrderStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *OrderStatus) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e OrderStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type ProductSort string
+
+const (
+	ProductSortRelevance ProductSort = "RELEVANCE"
+	ProductSortPriceAsc  ProductSort = "PRICE_ASC"
+	ProductSortPriceDesc ProductSort = "PRICE_DESC"
+	ProductSortNewest    ProductSort = "NEWEST"
+)
+
+var AllProductSort = []ProductSort{
+	ProductSortRelevance,
+	ProductSortPriceAsc,
+	ProductSortPriceDesc,
+	ProductSortNewest,
+}
+
+func (e ProductSort) IsValid() bool {
+	switch e {
+	case ProductSortRelevance, ProductSortPriceAsc, ProductSortPriceDesc, ProductSortNewest:
+		return true
+	}
+	return false
+}
+
+func (e ProductSort) String() string {
+	return string(e)
+}
+
+func (e *ProductSort) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ProductSort(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ProductSort", str)
+	}
+	return nil
+}
+
+func (e ProductSort) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ProductSort) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ProductSort) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type Role string
+
+const (
+	RoleCustomer       Role = "CUSTOMER"
+	RoleCatalogManager Role = "CATALOG_MANAGER"
+	RoleSupport        Role = "SUPPORT"
+	RoleAdmin          Role = "ADMIN"
+)
+
+var AllRole = []Role{
+	RoleCustomer,
+	RoleCatalogManager,
+	RoleSupport,
+	RoleAdmin,
+}
+
+func (e Role) IsValid() bool {
+	switch e {
+	case RoleCustomer, RoleCatalogManager, RoleSupport, RoleAdmin:
+		return true
+	}
+	return false
+}
+
+func (e Role) String() string {
+	return string(e)
+}
+
+func (e *Role) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Role(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Role", str)
+	}
+	return nil
+}
+
+func (e Role) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *Role) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e Role) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
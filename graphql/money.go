@@ -0,0 +1,44 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/suryanshp1/go-microservice/money"
+)
+
+// MarshalMoney writes an amount as a string such as "12.34 USD", which keeps
+// it exact where a Float would not.
+func MarshalMoney(m money.Money) graphql.Marshaler {
+	return graphql.WriterFunc(func(w io.Writer) {
+		io.WriteString(w, strconv.Quote(m.String()))
+	})
+}
+
+// UnmarshalMoney reads an amount such as "12.34 USD". Amounts without a
+// currency, including plain numbers, are in money.DefaultCurrency.
+func UnmarshalMoney(v interface{}) (money.Money, error) {
+	var s string
+	switch v := v.(type) {
+	case string:
+		s = v
+	case json.Number:
+		s = v.String()
+	case int:
+		s = strconv.Itoa(v)
+	case int64:
+		s = strconv.FormatInt(v, 10)
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return money.Money{}, fmt.Errorf("%T is not an amount of money", v)
+	}
+	m, err := money.Parse(s)
+	if err != nil {
+		return money.Money{}, fmt.Errorf("invalid amount of money %q: %w", s, err)
+	}
+	return m, nil
+}
@@ -3,8 +3,11 @@ package main
 import (
 	"context"
 	"errors"
+	"strings"
 	"time"
 
+	"github.com/suryanshp1/go-microservice/account"
+	"github.com/suryanshp1/go-microservice/catalog"
 	"github.com/suryanshp1/go-microservice/order"
 )
 
@@ -25,63 +28,388 @@ func (r *mutationResolver) CreateAccount(ctx context.Context, in AccountInput) (
 		return nil, err
 	}
 
-	return &Account{
-		ID:   account.ID,
-		Name: account.Name,
-	}, nil
+	return newAccount(account), nil
+}
+
+func (r *mutationResolver) UpdateAccount(ctx context.Context, id string, in UpdateAccountInput) (*Account, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	a, err := r.server.accountClient.UpdateAccount(ctx, id, in.Name, in.Email)
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+	return newAccount(a), nil
+}
+
+func (r *mutationResolver) DeactivateAccount(ctx context.Context, id string) (*Account, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	a, err := r.server.accountClient.DeactivateAccount(ctx, id)
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+	return newAccount(a), nil
+}
+
+func (r *mutationResolver) EraseAccount(ctx context.Context, id string) (*Account, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	a, err := r.server.accountClient.EraseAccount(ctx, id)
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+	return newAccount(a), nil
 }
 
 func (r *mutationResolver) CreateProduct(ctx context.Context, in ProductInput) (*Product, error) {
 	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
-	product, err := r.server.catalogClient.PostProduct(ctx, in.Name, in.Description, in.Price)
+	variants := []catalog.Variant{}
+	for _, v := range in.Variants {
+		variants = append(variants, v.variant())
+	}
+	product, err := r.server.catalogClient.PostProduct(
+		ctx,
+		in.Name,
+		in.Description,
+		in.Price,
+		in.Categories,
+		productOptions(in.Options),
+		variants,
+	)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Product{
-		ID:          product.ID,
-		Name:        product.Name,
-		Description: product.Description,
-		Price:       product.Price,
-	}, nil
+	return newProduct(product), nil
+}
+
+func (r *mutationResolver) UpdateProduct(ctx context.Context, id string, in UpdateProductInput) (*Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	version := ""
+	if in.Version != nil {
+		version = *in.Version
+	}
+	var categories *[]string
+	if in.Categories != nil {
+		categories = &in.Categories
+	}
+	var options *[]catalog.ProductOption
+	if in.Options != nil {
+		o := productOptions(in.Options)
+		options = &o
+	}
+	product, err := r.server.catalogClient.UpdateProduct(ctx, id, catalog.ProductUpdate{
+		Name:        in.Name,
+		Description: in.Description,
+		Price:       in.Price,
+		Categories:  categories,
+		Options:     options,
+	}, version)
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+
+	return newProduct(product), nil
+}
+
+func (r *mutationResolver) DeleteProduct(ctx context.Context, id string, version *string) (*Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	v := ""
+	if version != nil {
+		v = *version
+	}
+	product, err := r.server.catalogClient.DeleteProduct(ctx, id, v)
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+
+	return newProduct(product), nil
+}
+
+func (r *mutationResolver) AddVariant(ctx context.Context, productID string, in VariantInput, version *string) (*Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	v := ""
+	if version != nil {
+		v = *version
+	}
+	product, err := r.server.catalogClient.AddVariant(ctx, productID, in.variant(), v)
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+
+	return newProduct(product), nil
+}
+
+func (r *mutationResolver) UpdateVariant(ctx context.Context, productID string, variantID string, in VariantInput, version *string) (*Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	v := ""
+	if version != nil {
+		v = *version
+	}
+	variant := in.variant()
+	variant.ID = variantID
+	product, err := r.server.catalogClient.UpdateVariant(ctx, productID, variant, v)
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+
+	return newProduct(product), nil
+}
+
+func (r *mutationResolver) RemoveVariant(ctx context.Context, productID string, variantID string, version *string) (*Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	v := ""
+	if version != nil {
+		v = *version
+	}
+	product, err := r.server.catalogClient.RemoveVariant(ctx, productID, variantID, v)
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+
+	return newProduct(product), nil
+}
+
+func (r *mutationResolver) AdjustStock(ctx context.Context, productID string, variantID *string, delta int) (*Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	variant := ""
+	if variantID != nil {
+		variant = *variantID
+	}
+	product, err := r.server.catalogClient.AdjustStock(ctx, productID, variant, int64(delta))
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+
+	return newProduct(product), nil
+}
+
+func (r *mutationResolver) CreateCategory(ctx context.Context, in CategoryInput) (*Category, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	parentID := ""
+	if in.ParentID != nil {
+		parentID = *in.ParentID
+	}
+	c, err := r.server.catalogClient.CreateCategory(ctx, in.Name, parentID)
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+	return newCategory(c), nil
+}
+
+func (r *mutationResolver) RenameCategory(ctx context.Context, id string, name string) (*Category, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	c, err := r.server.catalogClient.RenameCategory(ctx, id, name)
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+	return newCategory(c), nil
+}
+
+func (r *mutationResolver) MoveCategory(ctx context.Context, id string, parentID *string) (*Category, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	parent := ""
+	if parentID != nil {
+		parent = *parentID
+	}
+	c, err := r.server.catalogClient.MoveCategory(ctx, id, parent)
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+	return newCategory(c), nil
 }
 
-func (r *mutationResolver) CreateOrder(ctx context.Context, in OrderInput) (*Order, error) {
+func (r *mutationResolver) CreateOrder(ctx context.Context, in OrderInput, currency *string) (*Order, error) {
 	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
+	if strings.TrimSpace(in.AccountID) != claimsFromContext(ctx).AccountID() {
+		return nil, ErrForbidden
+	}
+
 	var products []order.OrderedProduct
 	for _, p := range in.Products {
 		if p.Quantity <= 0 {
 			return nil, ErrInvalidParameter
 		}
+		variantID := ""
+		if p.VariantID != nil {
+			variantID = *p.VariantID
+		}
 		products = append(products, order.OrderedProduct{
-			ID:       p.ID,
-			Quantity: uint32(p.Quantity),
+			ID:        p.ID,
+			VariantID: variantID,
+			Quantity:  uint32(p.Quantity),
 		})
 	}
 
-	orderResp, err := r.server.orderClient.PostOrder(ctx, in.AccountID, products)
+	idempotencyKey := ""
+	if in.IdempotencyKey != nil {
+		idempotencyKey = *in.IdempotencyKey
+	}
+	orderCurrency := ""
+	if currency != nil {
+		orderCurrency = *currency
+	}
+	orderResp, err := r.server.orderClient.PostOrder(ctx, in.AccountID, products, idempotencyKey, orderCurrency)
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+	return newOrder(orderResp), nil
+}
+
+func (r *mutationResolver) AddToCart(ctx context.Context, productID string, variantID *string, quantity int, sessionID *string, currency *string) (*Cart, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	if quantity <= 0 {
+		return nil, ErrInvalidParameter
+	}
+	variant, session, cartCurrency := "", "", ""
+	if variantID != nil {
+		variant = *variantID
+	}
+	if sessionID != nil {
+		session = *sessionID
+	}
+	if currency != nil {
+		cartCurrency = *currency
+	}
+	c, err := r.server.cartClient.AddItem(ctx, session, productID, variant, uint32(quantity), cartCurrency)
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+	return newCart(c), nil
+}
+
+func (r *mutationResolver) UpdateCartItem(ctx context.Context, productID string, variantID *string, quantity int, sessionID *string, currency *string) (*Cart, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	if quantity < 0 {
+		return nil, ErrInvalidParameter
+	}
+	variant, session, cartCurrency := "", "", ""
+	if variantID != nil {
+		variant = *variantID
+	}
+	if sessionID != nil {
+		session = *sessionID
+	}
+	if currency != nil {
+		cartCurrency = *currency
+	}
+	c, err := r.server.cartClient.UpdateItem(ctx, session, productID, variant, uint32(quantity), cartCurrency)
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+	return newCart(c), nil
+}
+
+func (r *mutationResolver) Checkout(ctx context.Context, sessionID *string, currency *string, idempotencyKey *string) (*Order, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	session, orderCurrency, key := "", "", ""
+	if sessionID != nil {
+		session = *sessionID
+	}
+	if currency != nil {
+		orderCurrency = *currency
+	}
+	if idempotencyKey != nil {
+		key = *idempotencyKey
+	}
+	orderID, _, err := r.server.cartClient.Checkout(ctx, session, orderCurrency, key)
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+	o, err := r.server.orderClient.GetOrder(ctx, orderID)
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+	return newOrder(o), nil
+}
+
+func (r *mutationResolver) SetExchangeRate(ctx context.Context, from string, to string, rate string) (*ExchangeRate, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	er, err := r.server.catalogClient.SetExchangeRate(ctx, from, to, rate)
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+	return newExchangeRate(er), nil
+}
+
+func (r *mutationResolver) Register(ctx context.Context, in RegisterInput) (*AuthPayload, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	a, tokens, err := r.server.accountClient.Register(ctx, in.Name, in.Email, in.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	return authPayload(a, tokens), nil
+}
+
+func (r *mutationResolver) Login(ctx context.Context, in LoginInput) (*AuthPayload, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	a, tokens, err := r.server.accountClient.Login(ctx, in.Email, in.Password)
 	if err != nil {
 		return nil, err
 	}
-	var orderedProducts []*OrderedProduct
-	for _, p := range orderResp.Products {
-		orderedProducts = append(orderedProducts, &OrderedProduct{
-			ID:          p.ID,
-			Name:        p.Name,
-			Price:       p.Price,
-			Description: p.Description,
-			Quantity:    int(p.Quantity),
-		})
+
+	return authPayload(a, tokens), nil
+}
+
+func (r *mutationResolver) RefreshToken(ctx context.Context, refreshToken string) (*AuthPayload, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	tokens, err := r.server.accountClient.RefreshToken(ctx, refreshToken)
+	if err != nil {
+		return nil, err
 	}
 
-	return &Order{
-		ID:         orderResp.ID,
-		Products:   orderedProducts,
-		TotalPrice: orderResp.TotalPrice,
-		CreatedAt:  orderResp.CreatedAt,
-	}, nil
+	return authPayload(nil, tokens), nil
+}
+
+func authPayload(a *account.Account, tokens *account.Tokens) *AuthPayload {
+	payload := &AuthPayload{
+		AccessToken:           tokens.AccessToken,
+		AccessTokenExpiresAt:  tokens.AccessTokenExpiresAt,
+		RefreshToken:          tokens.RefreshToken,
+		RefreshTokenExpiresAt: tokens.RefreshTokenExpiresAt,
+	}
+	if a != nil {
+		payload.Account = newAccount(a)
+	}
+	return payload
 }
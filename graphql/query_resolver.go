@@ -4,6 +4,10 @@ import (
 	"context"
 	"log"
 	"time"
+
+	"github.com/suryanshp1/go-microservice/account"
+	"github.com/suryanshp1/go-microservice/catalog"
+	"github.com/suryanshp1/go-microservice/money"
 )
 
 type queryResolver struct {
@@ -14,16 +18,28 @@ func (r *queryResolver) Accounts(ctx context.Context, pagination *PaginationInpu
 	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
+	// Callers only see their own account unless they may read any account.
+	claims := claimsFromContext(ctx)
+	readAny, err := r.server.accountClient.CheckPermission(ctx, claims.AccountID(), account.PermissionAccountReadAny)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	if !readAny {
+		if id != nil && *id != claims.AccountID() {
+			return nil, ErrForbidden
+		}
+		self := claims.AccountID()
+		id = &self
+	}
+
 	if id != nil {
 		r, err := r.server.accountClient.GetAccount(ctx, *id)
 		if err != nil {
 			log.Println(err)
 			return nil, err
 		}
-		return []*Account{{
-			ID:   r.ID,
-			Name: r.Name,
-		}}, nil
+		return []*Account{newAccount(r)}, nil
 	}
 
 	skip, take := uint64(0), uint64(0)
@@ -39,59 +55,184 @@ func (r *queryResolver) Accounts(ctx context.Context, pagination *PaginationInpu
 
 	var accounts []*Account
 	for _, a := range accountList {
-		accounts = append(accounts, &Account{
-			ID:   a.ID,
-			Name: a.Name,
-		})
+		accounts = append(accounts, newAccount(a))
 	}
 
 	return accounts, nil
 }
 
-func (r *queryResolver) Products(ctx context.Context, pagination *PaginationInput, query *string, id *string) ([]*Product, error) {
+func (r *queryResolver) Products(
+	ctx context.Context,
+	first *int,
+	after *string,
+	query *string,
+	id *string,
+	filter *ProductFilter,
+	sort *ProductSort,
+	priceInterval *money.Money,
+	currency *string,
+) (*ProductConnection, error) {
 	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
+	q := catalog.SearchQuery{}
+	if currency != nil {
+		q.Currency = *currency
+	}
 	if id != nil {
-		p, err := r.server.catalogClient.GetProduct(ctx, *id)
+		p, err := r.server.catalogClient.GetProduct(ctx, *id, q.Currency)
 		if err != nil {
 			log.Println(err)
 			return nil, err
 		}
-		return []*Product{{
-			ID:          p.ID,
-			Name:        p.Name,
-			Description: p.Description,
-			Price:       p.Price,
-		}}, nil
+		return newProductConnection(&catalog.SearchResult{Products: []*catalog.Product{p}, Total: 1}), nil
 	}
 
-	skip, take := uint64(0), uint64(0)
-
-	if pagination != nil {
-		skip, take = pagination.bounds()
+	if first != nil {
+		if *first < 0 {
+			return nil, ErrInvalidParameter
+		}
+		q.Take = uint64(*first)
+	}
+	if after != nil {
+		q.After = *after
 	}
-
-	q := ""
 	if query != nil {
-		q = *query
+		q.Query = *query
+	}
+	if filter != nil {
+		q.MinPrice = filter.MinPrice
+		q.MaxPrice = filter.MaxPrice
+		q.Categories = filter.Categories
+	}
+	if sort != nil {
+		q.Sort = sort.sort()
+	}
+	if priceInterval != nil {
+		q.PriceInterval = *priceInterval
 	}
 
-	productList, err := r.server.catalogClient.GetProducts(ctx, skip, take, []string{}, q)
+	res, err := r.server.catalogClient.SearchProducts(ctx, q)
 	if err != nil {
 		log.Println(err)
-		return nil, err
+		return nil, grpcError(ctx, err)
 	}
-	var products []*Product
-	for _, p := range productList {
-		products = append(products, &Product{
-			ID:          p.ID,
-			Name:        p.Name,
-			Description: p.Description,
-			Price:       p.Price,
+	return newProductConnection(res), nil
+}
+
+func (r *queryResolver) ProductSuggestions(ctx context.Context, prefix string, limit *int) (*ProductSuggestions, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	n := 0
+	if limit != nil {
+		n = *limit
+	}
+	s, err := r.server.catalogClient.SuggestProducts(ctx, prefix, n)
+	if err != nil {
+		log.Println(err)
+		return nil, grpcError(ctx, err)
+	}
+
+	suggestions := &ProductSuggestions{
+		Products:   []*ProductSuggestion{},
+		Categories: []*CategorySuggestion{},
+		DidYouMean: s.Corrections,
+	}
+	for _, p := range s.Products {
+		suggestions.Products = append(suggestions.Products, &ProductSuggestion{ID: p.ProductID, Name: p.Name})
+	}
+	for _, c := range s.Categories {
+		suggestions.Categories = append(suggestions.Categories, &CategorySuggestion{
+			ID:    c.CategoryID,
+			Name:  c.Name,
+			Count: int(c.Count),
 		})
 	}
-	return products, nil
+	return suggestions, nil
+}
+
+func (r *queryResolver) Categories(ctx context.Context, parentID *string) ([]*Category, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	parent := ""
+	if parentID != nil {
+		parent = *parentID
+	}
+	categoryList, err := r.server.catalogClient.GetCategories(ctx, parent)
+	if err != nil {
+		log.Println(err)
+		return nil, grpcError(ctx, err)
+	}
+
+	categories := []*Category{}
+	for _, c := range categoryList {
+		categories = append(categories, newCategory(c))
+	}
+	return categories, nil
+}
+
+func (r *queryResolver) Category(ctx context.Context, id string) (*Category, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	c, err := r.server.catalogClient.GetCategory(ctx, id)
+	if err != nil {
+		log.Println(err)
+		return nil, grpcError(ctx, err)
+	}
+	return newCategory(c), nil
+}
+
+func (r *queryResolver) Order(ctx context.Context, id string) (*Order, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	o, err := r.server.orderClient.GetOrder(ctx, id)
+	if err != nil {
+		log.Println(err)
+		return nil, grpcError(ctx, err)
+	}
+
+	return newOrder(o), nil
+}
+
+func (r *queryResolver) Cart(ctx context.Context, sessionID *string, currency *string) (*Cart, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	session, cartCurrency := "", ""
+	if sessionID != nil {
+		session = *sessionID
+	}
+	if currency != nil {
+		cartCurrency = *currency
+	}
+	c, err := r.server.cartClient.GetCart(ctx, session, cartCurrency)
+	if err != nil {
+		log.Println(err)
+		return nil, grpcError(ctx, err)
+	}
+
+	return newCart(c), nil
+}
+
+func (r *queryResolver) ExchangeRates(ctx context.Context) ([]*ExchangeRate, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	res, err := r.server.catalogClient.GetExchangeRates(ctx)
+	if err != nil {
+		log.Println(err)
+		return nil, grpcError(ctx, err)
+	}
+
+	rates := []*ExchangeRate{}
+	for _, er := range res {
+		rates = append(rates, newExchangeRate(er))
+	}
+	return rates, nil
 }
 
 func (p PaginationInput) bounds() (uint64, uint64) {
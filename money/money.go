@@ -0,0 +1,218 @@
+// Package money represents amounts of money exactly, as an integer number of
+// the minor unit of a currency, e.g. cents, and an ISO 4217 currency code.
+//
+// Arithmetic on amounts is exact: totals are sums of unit prices multiplied
+// by quantities and are never rounded. Rounding only happens when a value
+// more precise than the minor unit of its currency is turned into Money,
+// such as a decimal with too many digits or a converted amount, and always
+// rounds half to even so that rounding errors do not pile up in one
+// direction.
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// DefaultCurrency is the currency of amounts given without one, and of
+// prices stored before they had a currency.
+const DefaultCurrency = "USD"
+
+var (
+	ErrInvalidAmount    = errors.New("invalid amount of money")
+	ErrInvalidCurrency  = errors.New("invalid currency, expected an ISO 4217 code")
+	ErrCurrencyMismatch = errors.New("amounts are in different currencies")
+)
+
+// Money is an amount in the minor unit of Currency.
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// exponents lists the currencies whose minor unit is not a hundredth of the
+// major unit.
+var exponents = map[string]int{
+	"BHD": 3, "BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "IQD": 3, "ISK": 0,
+	"JOD": 3, "JPY": 0, "KMF": 0, "KRW": 0, "KWD": 3, "LYD": 3, "OMR": 3,
+	"PYG": 0, "RWF": 0, "TND": 3, "UGX": 0, "VND": 0, "VUV": 0, "XAF": 0,
+	"XOF": 0, "XPF": 0,
+}
+
+// Exponent returns the number of decimal digits of the minor unit of
+// currency, 2 for most currencies.
+func Exponent(currency string) int {
+	if e, ok := exponents[currency]; ok {
+		return e
+	}
+	return 2
+}
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// ValidCurrency reports whether currency looks like an ISO 4217 code.
+func ValidCurrency(currency string) bool {
+	return currencyPattern.MatchString(currency)
+}
+
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+var decimalPattern = regexp.MustCompile(`^[+-]?[0-9]+(\.[0-9]+)?$`)
+
+// ParseDecimal reads an amount written in major units, e.g. "12.34", exactly.
+// Digits beyond the minor unit of currency are rounded half to even.
+func ParseDecimal(s, currency string) (Money, error) {
+	if !ValidCurrency(currency) {
+		return Money{}, ErrInvalidCurrency
+	}
+	s = strings.TrimSpace(s)
+	if !decimalPattern.MatchString(s) {
+		return Money{}, ErrInvalidAmount
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return Money{}, ErrInvalidAmount
+	}
+	amount, ok := roundToMinor(r, currency)
+	if !ok {
+		return Money{}, ErrInvalidAmount
+	}
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// Parse reads an amount in the format of String, e.g. "12.34 USD". Amounts
+// without a currency are in DefaultCurrency.
+func Parse(s string) (Money, error) {
+	amount, currency, ok := strings.Cut(strings.TrimSpace(s), " ")
+	if !ok {
+		currency = DefaultCurrency
+	}
+	return ParseDecimal(amount, strings.ToUpper(strings.TrimSpace(currency)))
+}
+
+// FromFloat converts an amount in major units held in a float64. It is only
+// meant for reading amounts stored as floats before this package existed.
+func FromFloat(f float64, currency string) Money {
+	r := new(big.Rat)
+	if r.SetFloat64(f) == nil {
+		return Money{Currency: currency}
+	}
+	amount, _ := roundToMinor(r, currency)
+	return Money{Amount: amount, Currency: currency}
+}
+
+// roundToMinor converts r major units of currency to minor units, rounding
+// half to even.
+func roundToMinor(r *big.Rat, currency string) (int64, bool) {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(Exponent(currency))), nil)
+	r = new(big.Rat).Mul(r, new(big.Rat).SetInt(scale))
+	n := roundHalfEven(r)
+	if !n.IsInt64() {
+		return 0, false
+	}
+	return n.Int64(), true
+}
+
+func roundHalfEven(r *big.Rat) *big.Int {
+	q, m := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	// Compare twice the remainder with the denominator to find out which
+	// integer is closer.
+	twice := new(big.Int).Abs(m)
+	twice.Lsh(twice, 1)
+	switch c := twice.Cmp(r.Denom()); {
+	case c > 0, c == 0 && q.Bit(0) == 1:
+		if r.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return q
+}
+
+// Valid reports whether m has a currency.
+func (m Money) Valid() bool {
+	return ValidCurrency(m.Currency)
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// Add returns m + o. Both must be in the same currency, except that a zero
+// Money without currency can be added to anything.
+func (m Money) Add(o Money) (Money, error) {
+	switch {
+	case m == Money{}:
+		return o, nil
+	case o == Money{}:
+		return m, nil
+	case m.Currency != o.Currency:
+		return Money{}, ErrCurrencyMismatch
+	}
+	sum := m.Amount + o.Amount
+	if (sum > m.Amount) != (o.Amount > 0) {
+		return Money{}, ErrInvalidAmount
+	}
+	return Money{Amount: sum, Currency: m.Currency}, nil
+}
+
+// Mul returns m multiplied by n, e.g. a unit price by a quantity.
+func (m Money) Mul(n int64) (Money, error) {
+	if n != 0 && (m.Amount > math.MaxInt64/abs(n) || m.Amount < math.MinInt64/abs(n)) {
+		return Money{}, ErrInvalidAmount
+	}
+	return Money{Amount: m.Amount * n, Currency: m.Currency}, nil
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// Cmp compares m and o, which must be in the same currency, and returns -1,
+// 0 or 1.
+func (m Money) Cmp(o Money) (int, error) {
+	if m.Currency != o.Currency {
+		return 0, ErrCurrencyMismatch
+	}
+	switch {
+	case m.Amount < o.Amount:
+		return -1, nil
+	case m.Amount > o.Amount:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// Decimal formats m in major units, e.g. "12.34", with as many decimals as
+// the currency's minor unit.
+func (m Money) Decimal() string {
+	e := Exponent(m.Currency)
+	r := new(big.Rat).SetFrac(big.NewInt(m.Amount), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(e)), nil))
+	return r.FloatString(e)
+}
+
+// Float returns m in major units. It is meant for display and for
+// approximate computations such as statistics, never for storing amounts.
+func (m Money) Float() float64 {
+	return float64(m.Amount) / math.Pow10(Exponent(m.Currency))
+}
+
+// String formats m as its decimal amount followed by its currency, e.g.
+// "12.34 USD".
+func (m Money) String() string {
+	return fmt.Sprintf("%s %s", m.Decimal(), m.Currency)
+}
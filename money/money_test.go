@@ -0,0 +1,172 @@
+package money
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		s        string
+		currency string
+		want     int64
+		err      error
+	}{
+		{"12.34", "USD", 1234, nil},
+		{"12", "USD", 1200, nil},
+		{" 0.5 ", "USD", 50, nil},
+		{"+1.10", "USD", 110, nil},
+		{"-12.34", "USD", -1234, nil},
+		// Half to even.
+		{"12.345", "USD", 1234, nil},
+		{"12.355", "USD", 1236, nil},
+		{"12.3451", "USD", 1235, nil},
+		{"-12.345", "USD", -1234, nil},
+		{"-12.355", "USD", -1236, nil},
+		// Currencies with other minor units.
+		{"1000", "JPY", 1000, nil},
+		{"1.5", "JPY", 2, nil},
+		{"2.5", "JPY", 2, nil},
+		{"1.2345", "KWD", 1234, nil},
+		{"1.2355", "KWD", 1236, nil},
+		{"abc", "USD", 0, ErrInvalidAmount},
+		{"1e5", "USD", 0, ErrInvalidAmount},
+		{"1.", "USD", 0, ErrInvalidAmount},
+		{"", "USD", 0, ErrInvalidAmount},
+		{"99999999999999999999", "USD", 0, ErrInvalidAmount},
+		{"12.34", "usd", 0, ErrInvalidCurrency},
+		{"12.34", "", 0, ErrInvalidCurrency},
+	}
+	for _, tt := range tests {
+		got, err := ParseDecimal(tt.s, tt.currency)
+		if err != tt.err {
+			t.Errorf("ParseDecimal(%q, %q) error = %v, want %v", tt.s, tt.currency, err, tt.err)
+			continue
+		}
+		if err == nil && got != New(tt.want, tt.currency) {
+			t.Errorf("ParseDecimal(%q, %q) = %v, want %d", tt.s, tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		s    string
+		want Money
+		err  error
+	}{
+		{"12.34 USD", New(1234, "USD"), nil},
+		{"12.34", New(1234, DefaultCurrency), nil},
+		{" 5 eur ", New(500, "EUR"), nil},
+		{"500 JPY", New(500, "JPY"), nil},
+		{"12.34 US", Money{}, ErrInvalidCurrency},
+		{"USD 12.34", Money{}, ErrInvalidCurrency},
+		{"1,5 EUR", Money{}, ErrInvalidAmount},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.s)
+		if err != tt.err || got != tt.want {
+			t.Errorf("Parse(%q) = %v, %v, want %v, %v", tt.s, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestFromFloat(t *testing.T) {
+	tests := []struct {
+		f        float64
+		currency string
+		want     int64
+	}{
+		{12.34, "USD", 1234},
+		{0.1 + 0.2, "USD", 30},
+		{0.125, "USD", 12},
+		{-3.5, "JPY", -4},
+		{math.NaN(), "USD", 0},
+	}
+	for _, tt := range tests {
+		if got := FromFloat(tt.f, tt.currency); got != New(tt.want, tt.currency) {
+			t.Errorf("FromFloat(%v, %q) = %v, want %d", tt.f, tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	tests := []struct {
+		m    Money
+		want string
+	}{
+		{New(1234, "USD"), "12.34 USD"},
+		{New(5, "USD"), "0.05 USD"},
+		{New(-5, "USD"), "-0.05 USD"},
+		{New(0, "EUR"), "0.00 EUR"},
+		{New(1000, "JPY"), "1000 JPY"},
+		{New(1234, "KWD"), "1.234 KWD"},
+	}
+	for _, tt := range tests {
+		if got := tt.m.String(); got != tt.want {
+			t.Errorf("%#v.String() = %q, want %q", tt.m, got, tt.want)
+		}
+	}
+}
+
+func TestAdd(t *testing.T) {
+	tests := []struct {
+		a, b Money
+		want Money
+		err  error
+	}{
+		{New(100, "USD"), New(250, "USD"), New(350, "USD"), nil},
+		{New(100, "USD"), New(-250, "USD"), New(-150, "USD"), nil},
+		{Money{}, New(250, "EUR"), New(250, "EUR"), nil},
+		{New(250, "EUR"), Money{}, New(250, "EUR"), nil},
+		{New(100, "USD"), New(100, "EUR"), Money{}, ErrCurrencyMismatch},
+		{New(math.MaxInt64, "USD"), New(1, "USD"), Money{}, ErrInvalidAmount},
+		{New(math.MinInt64, "USD"), New(-1, "USD"), Money{}, ErrInvalidAmount},
+	}
+	for _, tt := range tests {
+		got, err := tt.a.Add(tt.b)
+		if err != tt.err || got != tt.want {
+			t.Errorf("%v.Add(%v) = %v, %v, want %v, %v", tt.a, tt.b, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestMul(t *testing.T) {
+	tests := []struct {
+		m    Money
+		n    int64
+		want Money
+		err  error
+	}{
+		{New(250, "USD"), 3, New(750, "USD"), nil},
+		{New(250, "USD"), 0, New(0, "USD"), nil},
+		{New(250, "USD"), -2, New(-500, "USD"), nil},
+		{New(math.MaxInt64/2+1, "USD"), 2, Money{}, ErrInvalidAmount},
+		{New(math.MaxInt64/2+1, "USD"), -2, Money{}, ErrInvalidAmount},
+	}
+	for _, tt := range tests {
+		got, err := tt.m.Mul(tt.n)
+		if err != tt.err || got != tt.want {
+			t.Errorf("%v.Mul(%d) = %v, %v, want %v, %v", tt.m, tt.n, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestCmp(t *testing.T) {
+	tests := []struct {
+		a, b Money
+		want int
+		err  error
+	}{
+		{New(100, "USD"), New(200, "USD"), -1, nil},
+		{New(200, "USD"), New(100, "USD"), 1, nil},
+		{New(100, "USD"), New(100, "USD"), 0, nil},
+		{New(100, "USD"), New(100, "EUR"), 0, ErrCurrencyMismatch},
+	}
+	for _, tt := range tests {
+		got, err := tt.a.Cmp(tt.b)
+		if err != tt.err || got != tt.want {
+			t.Errorf("%v.Cmp(%v) = %d, %v, want %d, %v", tt.a, tt.b, got, err, tt.want, tt.err)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package money
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+)
+
+var ErrInvalidRate = errors.New("invalid exchange rate, expected a positive decimal")
+
+// Rate is an exchange rate: one unit of From is worth Value units of To.
+// Value is a decimal such as "0.92" and is kept as text so that it stays
+// exact.
+type Rate struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Value string `json:"value"`
+}
+
+// NewRate checks the currencies and the value of an exchange rate.
+func NewRate(from, to, value string) (Rate, error) {
+	if !ValidCurrency(from) || !ValidCurrency(to) {
+		return Rate{}, ErrInvalidCurrency
+	}
+	r := Rate{From: from, To: to, Value: strings.TrimSpace(value)}
+	if _, err := r.value(); err != nil {
+		return Rate{}, err
+	}
+	return r, nil
+}
+
+func (r Rate) value() (*big.Rat, error) {
+	if !decimalPattern.MatchString(r.Value) {
+		return nil, ErrInvalidRate
+	}
+	v, ok := new(big.Rat).SetString(r.Value)
+	if !ok || v.Sign() <= 0 {
+		return nil, ErrInvalidRate
+	}
+	return v, nil
+}
+
+// Convert returns m in the currency r converts to. m must be in the currency
+// r converts from. The result is rounded half to even to the minor unit of
+// the new currency.
+func (m Money) Convert(r Rate) (Money, error) {
+	if m.Currency != r.From {
+		return Money{}, ErrCurrencyMismatch
+	}
+	v, err := r.value()
+	if err != nil {
+		return Money{}, err
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(Exponent(m.Currency))), nil)
+	major := new(big.Rat).SetFrac(big.NewInt(m.Amount), scale)
+	amount, ok := roundToMinor(major.Mul(major, v), r.To)
+	if !ok {
+		return Money{}, ErrInvalidAmount
+	}
+	return Money{Amount: amount, Currency: r.To}, nil
+}
@@ -0,0 +1,53 @@
+package money
+
+import "testing"
+
+func TestNewRate(t *testing.T) {
+	tests := []struct {
+		from, to, value string
+		err             error
+	}{
+		{"USD", "EUR", "0.92", nil},
+		{"USD", "JPY", " 151.235 ", nil},
+		{"USD", "EUR", "0", ErrInvalidRate},
+		{"USD", "EUR", "-1", ErrInvalidRate},
+		{"USD", "EUR", "abc", ErrInvalidRate},
+		{"USD", "EUR", "1e3", ErrInvalidRate},
+		{"usd", "EUR", "0.92", ErrInvalidCurrency},
+		{"USD", "", "0.92", ErrInvalidCurrency},
+	}
+	for _, tt := range tests {
+		if _, err := NewRate(tt.from, tt.to, tt.value); err != tt.err {
+			t.Errorf("NewRate(%q, %q, %q) error = %v, want %v", tt.from, tt.to, tt.value, err, tt.err)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		m    Money
+		rate Rate
+		want Money
+		err  error
+	}{
+		{New(1000, "USD"), Rate{"USD", "EUR", "0.92"}, New(920, "EUR"), nil},
+		{New(100, "USD"), Rate{"USD", "JPY", "151.235"}, New(151, "JPY"), nil},
+		{New(1000, "JPY"), Rate{"JPY", "USD", "0.0066"}, New(660, "USD"), nil},
+		{New(1000, "USD"), Rate{"USD", "KWD", "0.3075"}, New(3075, "KWD"), nil},
+		{New(-1000, "USD"), Rate{"USD", "EUR", "0.92"}, New(-920, "EUR"), nil},
+		// Half to even.
+		{New(100, "USD"), Rate{"USD", "JPY", "150.5"}, New(150, "JPY"), nil},
+		{New(100, "USD"), Rate{"USD", "JPY", "151.5"}, New(152, "JPY"), nil},
+		{New(1, "USD"), Rate{"USD", "EUR", "0.5"}, New(0, "EUR"), nil},
+		{New(3, "USD"), Rate{"USD", "EUR", "0.5"}, New(2, "EUR"), nil},
+		{New(100, "EUR"), Rate{"USD", "EUR", "0.92"}, Money{}, ErrCurrencyMismatch},
+		{New(100, "USD"), Rate{"USD", "EUR", "0"}, Money{}, ErrInvalidRate},
+		{New(1<<62, "USD"), Rate{"USD", "JPY", "1000"}, Money{}, ErrInvalidAmount},
+	}
+	for _, tt := range tests {
+		got, err := tt.m.Convert(tt.rate)
+		if err != tt.err || got != tt.want {
+			t.Errorf("%v.Convert(%v) = %v, %v, want %v, %v", tt.m, tt.rate, got, err, tt.want, tt.err)
+		}
+	}
+}
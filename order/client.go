@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/suryanshp1/go-microservice/money"
 	"github.com/suryanshp1/go-microservice/order/pb"
 	"google.golang.org/grpc"
 )
@@ -29,66 +30,166 @@ func (c *Client) Close() {
 	c.conn.Close()
 }
 
-func (c *Client) PostOrder(ctx context.Context, accountID string, products []OrderedProduct) (*Order, error) {
+// PostOrder places an order for accountID. A non-empty currency places it in
+// that currency, converting the prices of products priced in others.
+func (c *Client) PostOrder(ctx context.Context, accountID string, products []OrderedProduct, idempotencyKey, currency string) (*Order, error) {
 	protoProducts := []*pb.PostOrderRequest_OrderProduct{}
 	for _, p := range products {
 		protoProducts = append(protoProducts, &pb.PostOrderRequest_OrderProduct{
 			ProductId: p.ID,
+			VariantId: p.VariantID,
 			Quantity:  p.Quantity,
 		})
 	}
 
 	r, err := c.service.PostOrder(ctx, &pb.PostOrderRequest{
-		AccountId: accountID,
-		Products:  protoProducts,
+		AccountId:      accountID,
+		Products:       protoProducts,
+		IdempotencyKey: idempotencyKey,
+		Currency:       currency,
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	newOrder := r.Order
-	newOrderCreatedAt := time.Time{}
-	newOrderCreatedAt.UnmarshalBinary(r.Order.CreatedAt)
+	return orderFromProto(r.Order), nil
+}
 
-	return &Order{
-		ID:         newOrder.Id,
-		AccountID:  newOrder.AccountId,
-		Products:   products,
-		CreatedAt:  newOrderCreatedAt,
-		TotalPrice: newOrder.TotalPrice,
-	}, nil
+func (c *Client) GetOrder(ctx context.Context, id string) (*Order, error) {
+	r, err := c.service.GetOrder(ctx, &pb.GetOrderRequest{
+		Id: id,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return orderFromProto(r.Order), nil
 }
 
-func (c *Client) GetOrdersForAccount(ctx context.Context, accountID string) ([]*Order, error) {
+func (c *Client) GetOrdersForAccount(ctx context.Context, accountID string, q Query) (*Page, error) {
+	filter := &pb.OrderFilter{
+		MinTotal: optionalMoneyToProto(q.Filter.MinTotal),
+		MaxTotal: optionalMoneyToProto(q.Filter.MaxTotal),
+	}
+	if !q.Filter.CreatedAfter.IsZero() {
+		filter.CreatedAfter, _ = q.Filter.CreatedAfter.MarshalBinary()
+	}
+	if !q.Filter.CreatedBefore.IsZero() {
+		filter.CreatedBefore, _ = q.Filter.CreatedBefore.MarshalBinary()
+	}
+	for _, status := range q.Filter.Statuses {
+		filter.Statuses = append(filter.Statuses, string(status))
+	}
+
 	r, err := c.service.GetOrdersForAccount(ctx, &pb.GetOrdersForAccountRequest{
 		AccountId: accountID,
+		Filter:    filter,
+		Sort:      string(q.Sort),
+		First:     uint32(q.First),
+		After:     q.After,
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	orders := []*Order{}
-	for _, orderProto := range r.Orders {
-		newOrder := Order{
-			ID:         orderProto.Id,
-			AccountID:  orderProto.AccountId,
-			TotalPrice: orderProto.TotalPrice,
+	page := &Page{
+		Edges:       []Edge{},
+		EndCursor:   r.EndCursor,
+		HasNextPage: r.HasNextPage,
+	}
+	for _, e := range r.Edges {
+		page.Edges = append(page.Edges, Edge{
+			Cursor: e.Cursor,
+			Order:  *orderFromProto(e.Order),
+		})
+	}
+	return page, nil
+}
+
+func (c *Client) UpdateOrderStatus(ctx context.Context, orderID string, status Status, reason string) (*StatusChange, error) {
+	r, err := c.service.UpdateOrderStatus(ctx, &pb.UpdateOrderStatusRequest{
+		OrderId: orderID,
+		Status:  string(status),
+		Reason:  reason,
+	})
+	if err != nil {
+		return nil, err
+	}
+	change := statusChangeFromProto(r.Change)
+	return &change, nil
+}
+
+func (c *Client) CancelOrder(ctx context.Context, orderID string, reason string) (*StatusChange, error) {
+	r, err := c.service.CancelOrder(ctx, &pb.CancelOrderRequest{
+		OrderId: orderID,
+		Reason:  reason,
+	})
+	if err != nil {
+		return nil, err
+	}
+	change := statusChangeFromProto(r.Change)
+	return &change, nil
+}
+
+func orderFromProto(orderProto *pb.Order) *Order {
+	newOrder := &Order{
+		ID:         orderProto.Id,
+		AccountID:  orderProto.AccountId,
+		TotalPrice: moneyFromProto(orderProto.TotalPrice),
+	}
+	newOrder.CreatedAt = time.Time{}
+	newOrder.CreatedAt.UnmarshalBinary(orderProto.CreatedAt)
+	newOrder.UpdatedAt = time.Time{}
+	newOrder.UpdatedAt.UnmarshalBinary(orderProto.UpdatedAt)
+
+	newOrder.Status = Status(orderProto.Status)
+	for _, c := range orderProto.StatusHistory {
+		newOrder.StatusHistory = append(newOrder.StatusHistory, statusChangeFromProto(c))
+	}
+
+	products := []OrderedProduct{}
+	for _, p := range orderProto.Products {
+		product := OrderedProduct{
+			ID:          p.Id,
+			VariantID:   p.VariantId,
+			SKU:         p.Sku,
+			Quantity:    p.Quantity,
+			Price:       moneyFromProto(p.Price),
+			Name:        p.Name,
+			Description: p.Description,
 		}
-		newOrder.CreatedAt = time.Time{}
-		newOrder.CreatedAt.UnmarshalBinary(orderProto.CreatedAt)
-
-		products := []OrderedProduct{}
-		for _, p := range orderProto.Products {
-			products = append(products, OrderedProduct{
-				ID:          p.Id,
-				Quantity:    p.Quantity,
-				Price:       p.Price,
-				Name:        p.Name,
-				Description: p.Description,
-			})
+		if r := p.ExchangeRate; r != nil {
+			product.ExchangeRate = &money.Rate{From: r.From, To: r.To, Value: r.Rate}
 		}
-		newOrder.Products = products
-		orders = append(orders, &newOrder)
+		products = append(products, product)
+	}
+	newOrder.Products = products
+	return newOrder
+}
+
+func moneyFromProto(pm *pb.Money) money.Money {
+	if pm == nil {
+		return money.Money{}
+	}
+	return money.New(pm.Amount, pm.Currency)
+}
+
+func optionalMoneyFromProto(pm *pb.Money) *money.Money {
+	if pm == nil {
+		return nil
+	}
+	m := moneyFromProto(pm)
+	return &m
+}
+
+func statusChangeFromProto(cp *pb.StatusChange) StatusChange {
+	c := StatusChange{
+		From:      Status(cp.From),
+		To:        Status(cp.To),
+		ChangedBy: cp.ChangedBy,
+		Reason:    cp.Reason,
 	}
-	return orders, nil
+	c.ChangedAt = time.Time{}
+	c.ChangedAt.UnmarshalBinary(cp.ChangedAt)
+	return c
 }
@@ -1,18 +1,25 @@
 package main
 
 import (
+	"context"
 	"log"
+	"os"
 	"time"
 
 	"github.com/kelseyhightower/envconfig"
+	"github.com/suryanshp1/go-microservice/account"
+	"github.com/suryanshp1/go-microservice/catalog"
 	"github.com/suryanshp1/go-microservice/order"
 	"github.com/tinrab/retry"
 )
 
 type Config struct {
-	DatabaseURL string `envconfig:"DATABASE_URL"`
-	AccountURL  string `envconfig:"ACCOUNT_SERVICE_URL"`
-	CatalogURL  string `envconfig:"CATALOG_SERVICE_URL"`
+	DatabaseURL     string `envconfig:"DATABASE_URL"`
+	AccountURL      string `envconfig:"ACCOUNT_SERVICE_URL"`
+	CatalogURL      string `envconfig:"CATALOG_SERVICE_URL"`
+	PublicKey       string `envconfig:"TOKEN_PUBLIC_KEY"`
+	ServiceEmail    string `envconfig:"SERVICE_ACCOUNT_EMAIL"`
+	ServicePassword string `envconfig:"SERVICE_ACCOUNT_PASSWORD"`
 }
 
 func main() {
@@ -22,6 +29,23 @@ func main() {
 		log.Fatal(err)
 	}
 
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "migrate":
+			migrate(cfg)
+		case "sagas":
+			sagas(cfg, os.Args[2:])
+		default:
+			log.Fatalf("unknown command %q, expected migrate or sagas", os.Args[1])
+		}
+		return
+	}
+
+	publicKey, err := account.ParsePublicKey(cfg.PublicKey)
+	if err != nil {
+		log.Fatalf("invalid TOKEN_PUBLIC_KEY: %v", err)
+	}
+
 	var r order.Repository
 	retry.ForeverSleep(2*time.Second, func(_ int) (err error) {
 		r, err = order.NewPostgresRepository(cfg.DatabaseURL)
@@ -32,8 +56,37 @@ func main() {
 	})
 
 	defer r.Close()
-	log.Println("Listening on port 8080......")
+
+	accountClient, err := account.NewClient(cfg.AccountURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer accountClient.Close()
+
+	catalogClient, err := catalog.NewClient(cfg.CatalogURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer catalogClient.Close()
 
 	s := order.NewService(r)
-	log.Fatal(order.ListenGRPC(s, cfg.AccountURL, cfg.CatalogURL, 8080))
+	session := account.NewServiceSession(accountClient, cfg.ServiceEmail, cfg.ServicePassword)
+	runner := order.NewSagas(r, s, catalogClient, session)
+
+	// Finish or undo order placements that were interrupted or are due for
+	// a retry.
+	go func() {
+		for range time.Tick(30 * time.Second) {
+			n, err := runner.Resume(context.Background())
+			if err != nil {
+				log.Println("Error resuming sagas:", err)
+			}
+			if n > 0 {
+				log.Printf("Resumed %d sagas", n)
+			}
+		}
+	}()
+
+	log.Println("Listening on port 8080......")
+	log.Fatal(order.ListenGRPC(s, accountClient, catalogClient, runner, account.NewTokenVerifier(publicKey), 8080))
 }
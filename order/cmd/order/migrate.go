@@ -0,0 +1,18 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/suryanshp1/go-microservice/order"
+)
+
+// migrate converts a database created by an older version of up.sql, for
+// example one that still stores prices as MONEY.
+func migrate(cfg Config) {
+	n, err := order.Migrate(context.Background(), cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("Error migrating orders database: %v", err)
+	}
+	log.Printf("Migrated orders database, converted %d price columns", n)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/suryanshp1/go-microservice/order"
+)
+
+// sagas prints the sagas that are taking longer than order.StuckAfter, or
+// the saga with the given ID, with every attempt at their steps.
+func sagas(cfg Config, args []string) {
+	r, err := order.NewPostgresRepository(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("Error connecting to orders database: %v", err)
+	}
+	defer r.Close()
+
+	ctx := context.Background()
+	var list []order.Saga
+	if len(args) > 0 {
+		s, err := r.GetSaga(ctx, args[0])
+		if err != nil {
+			log.Fatalf("Error getting saga %s: %v", args[0], err)
+		}
+		list = []order.Saga{*s}
+	} else {
+		list, err = r.GetStuckSagas(ctx, time.Now().Add(-order.StuckAfter), 100)
+		if err != nil {
+			log.Fatalf("Error getting stuck sagas: %v", err)
+		}
+		if len(list) == 0 {
+			fmt.Println("No stuck sagas")
+			return
+		}
+	}
+
+	for _, s := range list {
+		printSaga(s)
+	}
+}
+
+func printSaga(s order.Saga) {
+	w := os.Stdout
+	fmt.Fprintf(w, "saga %s: %s, order %s of account %s\n",
+		s.ID, s.Status, s.Placement.OrderID, strings.TrimSpace(s.Placement.AccountID))
+	fmt.Fprintf(w, "  created %s, updated %s\n", s.CreatedAt.Format(time.RFC3339), s.UpdatedAt.Format(time.RFC3339))
+	if s.Placement.ReservationID != "" {
+		fmt.Fprintf(w, "  reservation %s\n", s.Placement.ReservationID)
+	}
+	if s.Status == order.SagaRunning || s.Status == order.SagaCompensating {
+		fmt.Fprintf(w, "  %d failed attempts, next attempt %s\n", s.Attempts, s.NextAttemptAt.Format(time.RFC3339))
+	}
+	if s.Error != "" {
+		fmt.Fprintf(w, "  error: %s\n", s.Error)
+	}
+	for _, e := range s.Log {
+		action := "run"
+		if e.Compensate {
+			action = "compensate"
+		}
+		outcome := "ok"
+		if e.Error != "" {
+			outcome = "failed: " + e.Error
+		}
+		fmt.Fprintf(w, "  %s %s %s %s\n", e.At.Format(time.RFC3339), action, e.Step, outcome)
+	}
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,174 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Migrate brings a database created by an older version of up.sql up to
+// date. Columns, constraints and tables added since are created; order lines
+// that predate them get empty product details and a zero unit price, and
+// orders without a request hash can never match an idempotent retry. Totals
+// and unit prices stored as MONEY become integers in the minor unit of their
+// currency, and orders get the currency of their products,
+// money.DefaultCurrency for orders without any. Migrate can be run again
+// safely; it returns the number of columns it converted.
+func Migrate(ctx context.Context, url string) (n int, err error) {
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	// Keep concurrent runs from converting the same column twice.
+	if _, err = tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock(hashtext('order migrate'))"); err != nil {
+		return
+	}
+	// Columns that up.sql declares NOT NULL without a default get one for
+	// the rows that already exist, which is dropped again afterwards.
+	for _, stmt := range []string{
+		"ALTER TABLE orders ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'USD'",
+		"ALTER TABLE orders ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'pending'",
+		"ALTER TABLE orders ADD COLUMN IF NOT EXISTS idempotency_key VARCHAR(255)",
+		"ALTER TABLE orders ADD COLUMN IF NOT EXISTS request_hash CHAR(64) NOT NULL DEFAULT ''",
+		"ALTER TABLE orders ALTER COLUMN request_hash DROP DEFAULT",
+		"ALTER TABLE orders ADD COLUMN IF NOT EXISTS reservation_id VARCHAR(36) NOT NULL DEFAULT ''",
+		"CREATE INDEX IF NOT EXISTS orders_account_id_created_at_idx ON orders (account_id, created_at, id)",
+
+		"ALTER TABLE order_products ADD COLUMN IF NOT EXISTS variant_id VARCHAR(36) NOT NULL DEFAULT ''",
+		"ALTER TABLE order_products ADD COLUMN IF NOT EXISTS sku VARCHAR(255) NOT NULL DEFAULT ''",
+		"ALTER TABLE order_products ADD COLUMN IF NOT EXISTS unit_price BIGINT NOT NULL DEFAULT 0",
+		"ALTER TABLE order_products ALTER COLUMN unit_price DROP DEFAULT",
+		"ALTER TABLE order_products ADD COLUMN IF NOT EXISTS currency CHAR(3)",
+		"UPDATE order_products p SET currency = o.currency FROM orders o WHERE p.order_id = o.id AND p.currency IS NULL",
+		"ALTER TABLE order_products ALTER COLUMN currency SET NOT NULL",
+		"ALTER TABLE order_products ADD COLUMN IF NOT EXISTS exchange_rate_from CHAR(3)",
+		"ALTER TABLE order_products ADD COLUMN IF NOT EXISTS exchange_rate NUMERIC",
+		"ALTER TABLE order_products ADD COLUMN IF NOT EXISTS name VARCHAR(255) NOT NULL DEFAULT ''",
+		"ALTER TABLE order_products ALTER COLUMN name DROP DEFAULT",
+		"ALTER TABLE order_products ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT ''",
+		"ALTER TABLE order_products ALTER COLUMN description DROP DEFAULT",
+
+		`CREATE TABLE IF NOT EXISTS order_status_history (
+    id BIGSERIAL PRIMARY KEY,
+    order_id CHAR(36) NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    from_status VARCHAR(20),
+    to_status VARCHAR(20) NOT NULL,
+    changed_by CHAR(36) NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+)`,
+		"CREATE INDEX IF NOT EXISTS order_status_history_order_id_idx ON order_status_history (order_id)",
+
+		`CREATE TABLE IF NOT EXISTS order_sagas (
+    id VARCHAR(36) PRIMARY KEY,
+    status VARCHAR(20) NOT NULL,
+    step INT NOT NULL DEFAULT 0,
+    placement JSONB NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    lease_id VARCHAR(16) NOT NULL DEFAULT '',
+    lease_expires_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+)`,
+		`CREATE INDEX IF NOT EXISTS order_sagas_unfinished_idx ON order_sagas (next_attempt_at)
+    WHERE status IN ('running', 'compensating')`,
+		`CREATE TABLE IF NOT EXISTS order_saga_log (
+    id BIGSERIAL PRIMARY KEY,
+    saga_id VARCHAR(36) NOT NULL REFERENCES order_sagas(id) ON DELETE CASCADE,
+    step VARCHAR(50) NOT NULL,
+    compensate BOOLEAN NOT NULL DEFAULT FALSE,
+    error TEXT NOT NULL DEFAULT '',
+    at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+)`,
+		"CREATE INDEX IF NOT EXISTS order_saga_log_saga_id_idx ON order_saga_log (saga_id)",
+	} {
+		if _, err = tx.ExecContext(ctx, stmt); err != nil {
+			return
+		}
+	}
+
+	// Constraints cannot be added if they do not exist yet, so their
+	// columns are looked up first.
+	for _, c := range []struct {
+		table, name, columns, add string
+	}{
+		{
+			"orders", "orders_account_id_idempotency_key_key", "account_id,idempotency_key",
+			"ALTER TABLE orders ADD CONSTRAINT orders_account_id_idempotency_key_key UNIQUE (account_id, idempotency_key)",
+		},
+		{
+			"order_products", "order_products_pkey", "product_id,variant_id,order_id",
+			"ALTER TABLE order_products DROP CONSTRAINT IF EXISTS order_products_pkey, ADD PRIMARY KEY (product_id, variant_id, order_id)",
+		},
+	} {
+		var columns string
+		err = tx.QueryRowContext(
+			ctx,
+			`SELECT COALESCE(string_agg(column_name, ',' ORDER BY ordinal_position), '')
+    FROM information_schema.key_column_usage
+    WHERE table_name = $1 AND constraint_name = $2`,
+			c.table,
+			c.name,
+		).Scan(&columns)
+		if err != nil {
+			return
+		}
+		if columns == c.columns {
+			continue
+		}
+		if _, err = tx.ExecContext(ctx, c.add); err != nil {
+			return
+		}
+	}
+
+	for _, c := range []struct{ table, column string }{{"orders", "total_price"}, {"order_products", "unit_price"}} {
+		var dataType string
+		err = tx.QueryRowContext(
+			ctx,
+			"SELECT data_type FROM information_schema.columns WHERE table_name = $1 AND column_name = $2",
+			c.table,
+			c.column,
+		).Scan(&dataType)
+		if err != nil {
+			return
+		}
+		if dataType != "money" {
+			continue
+		}
+		// MONEY values are cents for the USD locale the database was
+		// created with.
+		_, err = tx.ExecContext(ctx, fmt.Sprintf(
+			"ALTER TABLE %[1]s ALTER COLUMN %[2]s TYPE BIGINT USING round(%[2]s::numeric * 100)::bigint",
+			c.table,
+			c.column,
+		))
+		if err != nil {
+			return
+		}
+		n++
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		`UPDATE orders o SET currency = p.currency
+    FROM (SELECT DISTINCT ON (order_id) order_id, currency FROM order_products) p
+    WHERE p.order_id = o.id AND o.currency <> p.currency`,
+	)
+	return
+}
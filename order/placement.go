@@ -0,0 +1,95 @@
+package order
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"github.com/suryanshp1/go-microservice/catalog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// placementSteps places an order: stock is reserved first, then the order
+// is stored, and the reservation is committed last. Once the order is
+// stored it is never undone: committing is retried until the catalog
+// answers, and a saga whose reservation cannot be committed is left stuck.
+func (sg *Sagas) placementSteps() []sagaStep {
+	return []sagaStep{
+		{name: "reserve_stock", run: sg.reserveStock, compensate: sg.releaseStock},
+		{name: "create_order", run: sg.createOrder, compensate: sg.cancelOrder},
+		{name: "commit_reservation", run: sg.commitReservation, retry: true},
+	}
+}
+
+func (sg *Sagas) reserveStock(ctx context.Context, p *Placement) error {
+	lines := []catalog.ReservationLine{}
+	for _, op := range p.Products {
+		lines = append(lines, catalog.ReservationLine{ProductID: op.ID, VariantID: op.VariantID, Quantity: op.Quantity})
+	}
+	r, err := sg.catalogClient.ReserveStock(ctx, lines, 0)
+	if err != nil {
+		return err
+	}
+	p.ReservationID = r.ID
+	return nil
+}
+
+// releaseStock gives reserved stock back to the catalog. A reservation
+// made by a call that timed out is not known and expires on its own.
+func (sg *Sagas) releaseStock(ctx context.Context, p *Placement, _ string) error {
+	if p.ReservationID == "" {
+		return nil
+	}
+	_, err := sg.catalogClient.ReleaseReservation(ctx, p.ReservationID)
+	if status.Code(err) == codes.NotFound {
+		return nil
+	}
+	return err
+}
+
+func (sg *Sagas) createOrder(ctx context.Context, p *Placement) error {
+	if _, err := sg.service.GetOrder(ctx, p.OrderID); err != ErrOrderNotFound {
+		// Created by an earlier attempt, or the lookup failed.
+		return err
+	}
+	o, err := sg.service.PostOrder(ctx, p.OrderID, p.AccountID, p.Currency, p.Products, p.IdempotencyKey, p.ReservationID)
+	if err != nil {
+		return err
+	}
+	if o.ReservationID != p.ReservationID {
+		// A concurrent request with the same idempotency key created the
+		// order, and with it its own reservation.
+		p.OrderID = strings.TrimSpace(o.ID)
+		p.Superseded = true
+	}
+	return nil
+}
+
+// cancelOrder cancels an order the saga created but could not finish.
+func (sg *Sagas) cancelOrder(ctx context.Context, p *Placement, cause string) error {
+	if p.Superseded {
+		return nil
+	}
+	_, err := sg.service.CancelOrder(ctx, p.OrderID, "", p.AccountID, "order placement failed: "+cause)
+	if err == ErrOrderNotFound {
+		return nil
+	}
+	if err == ErrInvalidTransition {
+		o, getErr := sg.service.GetOrder(ctx, p.OrderID)
+		if getErr == nil && o.Status == StatusCancelled {
+			return nil
+		}
+		log.Printf("cannot cancel order %s to undo its placement: %v", p.OrderID, err)
+	}
+	return err
+}
+
+func (sg *Sagas) commitReservation(ctx context.Context, p *Placement) error {
+	if p.Superseded {
+		// The order holds the stock of the other request.
+		return sg.releaseStock(ctx, p, "")
+	}
+	_, err := sg.catalogClient.CommitReservation(ctx, p.ReservationID)
+	return err
+}
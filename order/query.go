@@ -0,0 +1,135 @@
+package order
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/suryanshp1/go-microservice/money"
+)
+
+type Sort string
+
+const (
+	SortCreatedAtDesc Sort = "created_at_desc"
+	SortCreatedAtAsc  Sort = "created_at_asc"
+	SortTotalDesc     Sort = "total_desc"
+	SortTotalAsc      Sort = "total_asc"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+var (
+	ErrInvalidSort   = errors.New("invalid order sort")
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidFilter = errors.New("invalid order filter")
+)
+
+func (s Sort) IsValid() bool {
+	switch s {
+	case SortCreatedAtDesc, SortCreatedAtAsc, SortTotalDesc, SortTotalAsc:
+		return true
+	}
+	return false
+}
+
+func (s Sort) descending() bool {
+	return s == SortCreatedAtDesc || s == SortTotalDesc
+}
+
+func (s Sort) byTotal() bool {
+	return s == SortTotalDesc || s == SortTotalAsc
+}
+
+// Filter narrows down the orders of an account. Zero values do not filter.
+// MinTotal and MaxTotal only match orders in their currency.
+type Filter struct {
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	Statuses      []Status
+	MinTotal      *money.Money
+	MaxTotal      *money.Money
+}
+
+func (f Filter) validateTotals() error {
+	for _, m := range []*money.Money{f.MinTotal, f.MaxTotal} {
+		if m != nil && !m.Valid() {
+			return ErrInvalidFilter
+		}
+	}
+	if f.MinTotal != nil && f.MaxTotal != nil {
+		if c, err := f.MinTotal.Cmp(*f.MaxTotal); err != nil || c > 0 {
+			return ErrInvalidFilter
+		}
+	}
+	return nil
+}
+
+// Query selects one page of an account's orders. After is the cursor of the
+// last order of the previous page.
+type Query struct {
+	Filter Filter
+	Sort   Sort
+	First  int
+	After  string
+}
+
+type Edge struct {
+	Cursor string
+	Order  Order
+}
+
+type Page struct {
+	Edges       []Edge
+	EndCursor   string
+	HasNextPage bool
+}
+
+// cursor is the position of an order in a sorted list: the value of the sort
+// column and the order ID as a tie breaker. It is handed to clients as an
+// opaque string and is only valid for the sort it was created with.
+type cursor struct {
+	Sort  Sort   `json:"s"`
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+func newCursor(s Sort, o Order) cursor {
+	c := cursor{Sort: s, ID: strings.TrimSpace(o.ID)}
+	if s.byTotal() {
+		c.Value = strconv.FormatInt(o.TotalPrice.Amount, 10)
+	} else {
+		c.Value = o.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+	return c
+}
+
+func (c cursor) encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string, sort Sort) (*cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	c := &cursor{}
+	if err := json.Unmarshal(b, c); err != nil || c.Sort != sort || c.ID == "" {
+		return nil, ErrInvalidCursor
+	}
+	if sort.byTotal() {
+		if _, err := strconv.ParseInt(c.Value, 10, 64); err != nil {
+			return nil, ErrInvalidCursor
+		}
+	} else if _, err := time.Parse(time.RFC3339Nano, c.Value); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return c, nil
+}
@@ -3,16 +3,35 @@ package order
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/lib/pq"
+	"github.com/suryanshp1/go-microservice/money"
 )
 
 type Repository interface {
 	Close()
 	PutOrder(ctx context.Context, o Order) error
-	GetOrdersForAccount(ctx context.Context, accountID string) ([]Order, error)
+	GetOrder(ctx context.Context, id string) (*Order, error)
+	GetOrderByIdempotencyKey(ctx context.Context, accountID, idempotencyKey string) (*Order, error)
+	GetOrdersForAccount(ctx context.Context, accountID string, q Query) ([]Order, error)
+	GetOrderStatus(ctx context.Context, orderID string) (accountID string, status Status, err error)
+	PutOrderStatus(ctx context.Context, orderID string, change StatusChange) error
+	PutSaga(ctx context.Context, s Saga) error
+	UpdateSaga(ctx context.Context, s Saga, entry SagaLogEntry) error
+	ClaimSagas(ctx context.Context, leaseID string, leaseExpires time.Time, limit int) ([]Saga, error)
+	GetSaga(ctx context.Context, id string) (*Saga, error)
+	GetStuckSagas(ctx context.Context, createdBefore time.Time, limit int) ([]Saga, error)
 }
 
+var (
+	ErrDuplicateIdempotencyKey = errors.New("duplicate idempotency key")
+)
+
 type postgresRepository struct {
 	db *sql.DB
 }
@@ -49,20 +68,54 @@ func (r *postgresRepository) PutOrder(ctx context.Context, o Order) (err error)
 	// Insert order
 	_, err = tx.ExecContext(
 		ctx,
-		"INSERT INTO orders(id, created_at, account_id, total_price) VALUES($1, $2, $3, $4)",
+		`INSERT INTO orders(id, created_at, account_id, total_price, currency, status, idempotency_key, request_hash, reservation_id)
+    VALUES($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8, $9)`,
 		o.ID,
 		o.CreatedAt,
 		o.AccountID,
-		o.TotalPrice,
+		o.TotalPrice.Amount,
+		o.TotalPrice.Currency,
+		o.Status,
+		o.IdempotencyKey,
+		o.RequestHash,
+		o.ReservationID,
 	)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Constraint == "orders_account_id_idempotency_key_key" {
+		err = ErrDuplicateIdempotencyKey
+	}
 	if err != nil {
 		return
 	}
 
+	// Insert initial status history
+	for _, change := range o.StatusHistory {
+		err = insertStatusChange(ctx, tx, o.ID, change)
+		if err != nil {
+			return
+		}
+	}
+
 	// Insert order products
-	stmt, _ := tx.PrepareContext(ctx, pq.CopyIn("order_products", "order_id", "product_id", "quantity"))
+	stmt, _ := tx.PrepareContext(ctx, pq.CopyIn(
+		"order_products",
+		"order_id",
+		"product_id",
+		"variant_id",
+		"sku",
+		"quantity",
+		"unit_price",
+		"currency",
+		"exchange_rate_from",
+		"exchange_rate",
+		"name",
+		"description",
+	))
 	for _, p := range o.Products {
-		_, err = stmt.ExecContext(ctx, o.ID, p.ID, p.Quantity)
+		var rateFrom, rate interface{}
+		if p.ExchangeRate != nil {
+			rateFrom, rate = p.ExchangeRate.From, p.ExchangeRate.Value
+		}
+		_, err = stmt.ExecContext(ctx, o.ID, p.ID, p.VariantID, p.SKU, p.Quantity, p.Price.Amount, p.Price.Currency, rateFrom, rate, p.Name, p.Description)
 		if err != nil {
 			return
 		}
@@ -76,20 +129,136 @@ func (r *postgresRepository) PutOrder(ctx context.Context, o Order) (err error)
 	return
 }
 
-func (r *postgresRepository) GetOrdersForAccount(ctx context.Context, accountID string) ([]Order, error) {
-	rows, err := r.db.QueryContext(
+func (r *postgresRepository) GetOrder(ctx context.Context, id string) (*Order, error) {
+	o := &Order{}
+	err := r.db.QueryRowContext(
 		ctx,
 		`SELECT
+      id,
+      created_at,
+      updated_at,
+      account_id,
+      total_price,
+      currency,
+      status,
+      reservation_id
+    FROM orders
+    WHERE id = $1`,
+		id,
+	).Scan(&o.ID, &o.CreatedAt, &o.UpdatedAt, &o.AccountID, &o.TotalPrice.Amount, &o.TotalPrice.Currency, &o.Status, &o.ReservationID)
+	if err == sql.ErrNoRows {
+		return nil, ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	orders := []Order{*o}
+	if err = r.loadProducts(ctx, orders); err != nil {
+		return nil, err
+	}
+	if err = r.loadStatusHistory(ctx, orders); err != nil {
+		return nil, err
+	}
+
+	return &orders[0], nil
+}
+
+func (r *postgresRepository) GetOrderByIdempotencyKey(ctx context.Context, accountID, idempotencyKey string) (*Order, error) {
+	var id, hash string
+	err := r.db.QueryRowContext(
+		ctx,
+		"SELECT id, request_hash FROM orders WHERE account_id = $1 AND idempotency_key = $2",
+		accountID,
+		idempotencyKey,
+	).Scan(&id, &hash)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	o, err := r.GetOrder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	o.IdempotencyKey = idempotencyKey
+	o.RequestHash = hash
+	return o, nil
+}
+
+// GetOrdersForAccount returns up to q.First orders of an account matching
+// q.Filter, in q.Sort order, starting after the order q.After points to.
+func (r *postgresRepository) GetOrdersForAccount(ctx context.Context, accountID string, q Query) ([]Order, error) {
+	conditions := []string{"o.account_id = $1"}
+	args := []interface{}{accountID}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if !q.Filter.CreatedAfter.IsZero() {
+		conditions = append(conditions, "o.created_at >= "+arg(q.Filter.CreatedAfter))
+	}
+	if !q.Filter.CreatedBefore.IsZero() {
+		conditions = append(conditions, "o.created_at < "+arg(q.Filter.CreatedBefore))
+	}
+	if len(q.Filter.Statuses) > 0 {
+		statuses := []string{}
+		for _, status := range q.Filter.Statuses {
+			statuses = append(statuses, string(status))
+		}
+		conditions = append(conditions, "o.status = ANY("+arg(pq.Array(statuses))+")")
+	}
+	if q.Filter.MinTotal != nil {
+		conditions = append(conditions, "o.total_price >= "+arg(q.Filter.MinTotal.Amount), "o.currency = "+arg(q.Filter.MinTotal.Currency))
+	}
+	if q.Filter.MaxTotal != nil {
+		conditions = append(conditions, "o.total_price <= "+arg(q.Filter.MaxTotal.Amount), "o.currency = "+arg(q.Filter.MaxTotal.Currency))
+	}
+
+	column := "o.created_at"
+	if q.Sort.byTotal() {
+		column = "o.total_price"
+	}
+	direction, comparison := "ASC", ">"
+	if q.Sort.descending() {
+		direction, comparison = "DESC", "<"
+	}
+	if q.After != "" {
+		c, err := decodeCursor(q.After, q.Sort)
+		if err != nil {
+			return nil, err
+		}
+		value := arg(c.Value)
+		if q.Sort.byTotal() {
+			value += "::bigint"
+		} else {
+			value += "::timestamptz"
+		}
+		conditions = append(conditions, fmt.Sprintf("(%s, o.id) %s (%s, %s)", column, comparison, value, arg(c.ID)))
+	}
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		fmt.Sprintf(`SELECT
       o.id,
       o.created_at,
+      o.updated_at,
       o.account_id,
-      o.total_price::money::numeric::float8,
-      op.product_id,
-      op.quantity
-    FROM orders o JOIN order_products op ON (o.id = op.order_id)
-    WHERE o.account_id = $1
-    ORDER BY o.id`,
-		accountID,
+      o.total_price,
+      o.currency,
+      o.status
+    FROM orders o
+    WHERE %s
+    ORDER BY %s %s, o.id %s
+    LIMIT %s`,
+			strings.Join(conditions, " AND "),
+			column, direction, direction,
+			arg(q.First),
+		),
+		args...,
 	)
 	if err != nil {
 		return nil, err
@@ -97,59 +266,414 @@ func (r *postgresRepository) GetOrdersForAccount(ctx context.Context, accountID
 	defer rows.Close()
 
 	orders := []Order{}
-	order := &Order{}
-	lastOrder := &Order{}
-	orderedProduct := &OrderedProduct{}
-	products := []OrderedProduct{}
+	for rows.Next() {
+		o := Order{}
+		if err = rows.Scan(&o.ID, &o.CreatedAt, &o.UpdatedAt, &o.AccountID, &o.TotalPrice.Amount, &o.TotalPrice.Currency, &o.Status); err != nil {
+			return nil, err
+		}
+		orders = append(orders, o)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err = r.loadProducts(ctx, orders); err != nil {
+		return nil, err
+	}
+	if err = r.loadStatusHistory(ctx, orders); err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+func (r *postgresRepository) GetOrderStatus(ctx context.Context, orderID string) (string, Status, error) {
+	var accountID string
+	var status Status
+	err := r.db.QueryRowContext(
+		ctx,
+		"SELECT TRIM(account_id), status FROM orders WHERE id = $1",
+		orderID,
+	).Scan(&accountID, &status)
+	if err == sql.ErrNoRows {
+		return "", "", ErrOrderNotFound
+	}
+	if err != nil {
+		return "", "", err
+	}
+	return accountID, status, nil
+}
+
+// PutOrderStatus moves an order from change.From to change.To and records the
+// change. It fails with ErrStatusConflict if the order is no longer in
+// change.From, so concurrent updates cannot skip a transition check.
+func (r *postgresRepository) PutOrderStatus(ctx context.Context, orderID string, change StatusChange) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	res, err := tx.ExecContext(
+		ctx,
+		"UPDATE orders SET status = $1 WHERE id = $2 AND status = $3",
+		change.To,
+		orderID,
+		change.From,
+	)
+	if err != nil {
+		return
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return
+	}
+	if n == 0 {
+		err = ErrStatusConflict
+		return
+	}
+
+	err = insertStatusChange(ctx, tx, orderID, change)
+	return
+}
+
+func insertStatusChange(ctx context.Context, tx *sql.Tx, orderID string, change StatusChange) error {
+	_, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO order_status_history(order_id, from_status, to_status, changed_by, reason, changed_at)
+    VALUES($1, NULLIF($2, ''), $3, $4, $5, $6)`,
+		orderID,
+		change.From,
+		change.To,
+		change.ChangedBy,
+		change.Reason,
+		change.ChangedAt,
+	)
+	return err
+}
+
+// loadProducts fills in the lines of orders.
+func (r *postgresRepository) loadProducts(ctx context.Context, orders []Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+	index := map[string]int{}
+	ids := []string{}
+	for i, o := range orders {
+		index[o.ID] = i
+		ids = append(ids, o.ID)
+		orders[i].Products = []OrderedProduct{}
+	}
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT
+      order_id,
+      product_id,
+      variant_id,
+      sku,
+      quantity,
+      unit_price,
+      currency,
+      COALESCE(exchange_rate_from, ''),
+      COALESCE(exchange_rate::text, ''),
+      name,
+      description
+    FROM order_products
+    WHERE order_id = ANY($1)`,
+		pq.Array(ids),
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
 
-	// Scan rows into Order structs
 	for rows.Next() {
-		if err = rows.Scan(
-			&order.ID,
-			&order.CreatedAt,
-			&order.AccountID,
-			&order.TotalPrice,
-			&orderedProduct.ID,
-			&orderedProduct.Quantity,
+		var orderID, rateFrom, rate string
+		p := OrderedProduct{}
+		if err := rows.Scan(
+			&orderID,
+			&p.ID,
+			&p.VariantID,
+			&p.SKU,
+			&p.Quantity,
+			&p.Price.Amount,
+			&p.Price.Currency,
+			&rateFrom,
+			&rate,
+			&p.Name,
+			&p.Description,
 		); err != nil {
-			return nil, err
+			return err
 		}
-		// Scan order
-		if lastOrder.ID != "" && lastOrder.ID != order.ID {
-			newOrder := Order{
-				ID:         lastOrder.ID,
-				AccountID:  lastOrder.AccountID,
-				CreatedAt:  lastOrder.CreatedAt,
-				TotalPrice: lastOrder.TotalPrice,
-				Products:   products,
-			}
-			orders = append(orders, newOrder)
-			products = []OrderedProduct{}
+		if rate != "" {
+			p.ExchangeRate = &money.Rate{From: rateFrom, To: p.Price.Currency, Value: rate}
 		}
-		// Scan products
-		products = append(products, OrderedProduct{
-			ID:       orderedProduct.ID,
-			Quantity: orderedProduct.Quantity,
-		})
+		if i, ok := index[orderID]; ok {
+			orders[i].Products = append(orders[i].Products, p)
+		}
+	}
+	return rows.Err()
+}
 
-		*lastOrder = *order
+// loadStatusHistory fills in the status history of orders, oldest change
+// first.
+func (r *postgresRepository) loadStatusHistory(ctx context.Context, orders []Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+	index := map[string]int{}
+	ids := []string{}
+	for i, o := range orders {
+		index[o.ID] = i
+		ids = append(ids, o.ID)
+	}
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT order_id, COALESCE(from_status, ''), to_status, changed_by, reason, changed_at
+    FROM order_status_history
+    WHERE order_id = ANY($1)
+    ORDER BY id`,
+		pq.Array(ids),
+	)
+	if err != nil {
+		return err
 	}
+	defer rows.Close()
 
-	// Add last order (or first :D)
-	if lastOrder != nil {
-		newOrder := Order{
-			ID:         lastOrder.ID,
-			AccountID:  lastOrder.AccountID,
-			CreatedAt:  lastOrder.CreatedAt,
-			TotalPrice: lastOrder.TotalPrice,
-			Products:   products,
+	for rows.Next() {
+		var orderID string
+		change := StatusChange{}
+		if err := rows.Scan(
+			&orderID,
+			&change.From,
+			&change.To,
+			&change.ChangedBy,
+			&change.Reason,
+			&change.ChangedAt,
+		); err != nil {
+			return err
+		}
+		if i, ok := index[orderID]; ok {
+			orders[i].StatusHistory = append(orders[i].StatusHistory, change)
 		}
-		orders = append(orders, newOrder)
 	}
+	return rows.Err()
+}
 
-	if err = rows.Err(); err != nil {
+const sagaColumns = `id, status, step, placement, error, attempts, next_attempt_at, lease_id, lease_expires_at,
+    created_at, updated_at`
+
+func (r *postgresRepository) PutSaga(ctx context.Context, s Saga) error {
+	placement, err := json.Marshal(s.Placement)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(
+		ctx,
+		`INSERT INTO order_sagas(id, status, step, placement, error, attempts, next_attempt_at, lease_id, lease_expires_at,
+      created_at, updated_at)
+    VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		s.ID,
+		s.Status,
+		s.Step,
+		placement,
+		s.Error,
+		s.Attempts,
+		s.NextAttemptAt,
+		s.LeaseID,
+		s.LeaseExpires,
+		s.CreatedAt,
+		s.UpdatedAt,
+	)
+	return err
+}
+
+// UpdateSaga stores the progress of a saga along with the attempt that made
+// it. It fails with errSagaLeaseLost if another runner claimed the saga in
+// the meantime.
+func (r *postgresRepository) UpdateSaga(ctx context.Context, s Saga, entry SagaLogEntry) (err error) {
+	placement, err := json.Marshal(s.Placement)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	res, err := tx.ExecContext(
+		ctx,
+		`UPDATE order_sagas
+    SET status = $1, step = $2, placement = $3, error = $4, attempts = $5, next_attempt_at = $6,
+      lease_expires_at = $7, updated_at = $8
+    WHERE id = $9 AND lease_id = $10`,
+		s.Status,
+		s.Step,
+		placement,
+		s.Error,
+		s.Attempts,
+		s.NextAttemptAt,
+		s.LeaseExpires,
+		s.UpdatedAt,
+		s.ID,
+		s.LeaseID,
+	)
+	if err != nil {
+		return
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return
+	}
+	if n == 0 {
+		err = errSagaLeaseLost
+		return
+	}
+
+	if entry.Step == "" {
+		return
+	}
+	_, err = tx.ExecContext(
+		ctx,
+		`INSERT INTO order_saga_log(saga_id, step, compensate, error, at)
+    VALUES($1, $2, $3, $4, $5)`,
+		s.ID,
+		entry.Step,
+		entry.Compensate,
+		entry.Error,
+		entry.At,
+	)
+	return
+}
+
+// ClaimSagas takes over up to limit unfinished sagas that no runner holds
+// and that are due, giving them the lease leaseID until leaseExpires.
+func (r *postgresRepository) ClaimSagas(ctx context.Context, leaseID string, leaseExpires time.Time, limit int) ([]Saga, error) {
+	return r.querySagas(
+		ctx,
+		`UPDATE order_sagas SET lease_id = $1, lease_expires_at = $2
+    WHERE id IN (
+      SELECT id FROM order_sagas
+      WHERE status IN ($3, $4) AND lease_expires_at <= now() AND next_attempt_at <= now()
+      ORDER BY next_attempt_at
+      LIMIT $5
+      FOR UPDATE SKIP LOCKED
+    )
+    RETURNING `+sagaColumns,
+		leaseID,
+		leaseExpires,
+		SagaRunning,
+		SagaCompensating,
+		limit,
+	)
+}
+
+func (r *postgresRepository) GetSaga(ctx context.Context, id string) (*Saga, error) {
+	sagas, err := r.querySagas(ctx, `SELECT `+sagaColumns+` FROM order_sagas WHERE id = $1`, id)
+	if err != nil {
 		return nil, err
 	}
+	if len(sagas) == 0 {
+		return nil, ErrSagaNotFound
+	}
+	return &sagas[0], nil
+}
 
-	return orders, nil
+// GetStuckSagas returns unfinished sagas created before createdBefore and
+// sagas left stuck, oldest first.
+func (r *postgresRepository) GetStuckSagas(ctx context.Context, createdBefore time.Time, limit int) ([]Saga, error) {
+	return r.querySagas(
+		ctx,
+		`SELECT `+sagaColumns+` FROM order_sagas
+    WHERE (status IN ($1, $2) AND created_at < $3) OR status = $5
+    ORDER BY created_at, id
+    LIMIT $4`,
+		SagaRunning,
+		SagaCompensating,
+		createdBefore,
+		limit,
+		SagaStuck,
+	)
+}
+
+// querySagas reads the sagas a query returns, with their logs.
+func (r *postgresRepository) querySagas(ctx context.Context, query string, args ...interface{}) ([]Saga, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sagas := []Saga{}
+	for rows.Next() {
+		var s Saga
+		var placement []byte
+		if err := rows.Scan(
+			&s.ID,
+			&s.Status,
+			&s.Step,
+			&placement,
+			&s.Error,
+			&s.Attempts,
+			&s.NextAttemptAt,
+			&s.LeaseID,
+			&s.LeaseExpires,
+			&s.CreatedAt,
+			&s.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(placement, &s.Placement); err != nil {
+			return nil, fmt.Errorf("saga %s: %w", s.ID, err)
+		}
+		sagas = append(sagas, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range sagas {
+		if sagas[i].Log, err = r.loadSagaLog(ctx, sagas[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	return sagas, nil
+}
+
+func (r *postgresRepository) loadSagaLog(ctx context.Context, sagaID string) ([]SagaLogEntry, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		"SELECT step, compensate, error, at FROM order_saga_log WHERE saga_id = $1 ORDER BY id",
+		sagaID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	log := []SagaLogEntry{}
+	for rows.Next() {
+		var e SagaLogEntry
+		if err := rows.Scan(&e.Step, &e.Compensate, &e.Error, &e.At); err != nil {
+			return nil, err
+		}
+		log = append(log, e)
+	}
+	return log, rows.Err()
 }
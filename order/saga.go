@@ -0,0 +1,310 @@
+package order
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/segmentio/ksuid"
+	"github.com/suryanshp1/go-microservice/account"
+	"github.com/suryanshp1/go-microservice/catalog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type SagaStatus string
+
+const (
+	SagaRunning SagaStatus = "running"
+	// SagaCompensating undoes the steps taken so far after a step failed.
+	SagaCompensating SagaStatus = "compensating"
+	SagaCompleted    SagaStatus = "completed"
+	SagaCompensated  SagaStatus = "compensated"
+	// SagaStuck is left for an operator: a step past the point of no return
+	// failed in a way retrying will not fix.
+	SagaStuck SagaStatus = "stuck"
+)
+
+const (
+	// StuckAfter is how long a saga may take before it counts as stuck.
+	StuckAfter = 5 * time.Minute
+
+	// sagaLease is how long a runner owns a saga after it last saved it.
+	// Sagas whose runner stopped saving them, e.g. because the service
+	// crashed, are resumed once their lease ran out.
+	sagaLease       = time.Minute
+	sagaStepTimeout = 10 * time.Second
+	maxSagaBackoff  = 10 * time.Minute
+)
+
+var (
+	ErrSagaNotFound = errors.New("saga not found")
+
+	errSagaLeaseLost = errors.New("saga was taken over by another runner")
+)
+
+// Saga records the placement of an order across the catalog and the order
+// database, so that it can be finished or undone after a failure or a
+// crash. While running, Step is the index of the next step; while
+// compensating, it is the number of steps that may still need undoing.
+// Error is the last error a step or compensation failed with.
+type Saga struct {
+	ID            string
+	Status        SagaStatus
+	Step          int
+	Placement     Placement
+	Error         string
+	Attempts      int
+	NextAttemptAt time.Time
+	LeaseID       string
+	LeaseExpires  time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	Log           []SagaLogEntry
+}
+
+// SagaLogEntry records one attempt at running or compensating a step.
+type SagaLogEntry struct {
+	Step       string
+	Compensate bool
+	Error      string
+	At         time.Time
+}
+
+// Placement is the order a saga places and what the saga did so far. The
+// order ID is chosen up front, so that creating the order can be retried
+// without creating it twice.
+type Placement struct {
+	AccountID      string           `json:"accountId"`
+	Currency       string           `json:"currency"`
+	Products       []OrderedProduct `json:"products"`
+	IdempotencyKey string           `json:"idempotencyKey"`
+	OrderID        string           `json:"orderId"`
+	ReservationID  string           `json:"reservationId"`
+	// Superseded is set if a concurrent request with the same idempotency
+	// key placed the order; OrderID is then that order's.
+	Superseded bool `json:"superseded"`
+}
+
+// sagaStep is a step of a saga and the compensation that undoes it.
+// Compensations must be safe to run more than once and for steps that
+// never ran. Steps that retry are past the point of no return: they are
+// retried when they fail temporarily, and leave the saga stuck when they
+// fail otherwise, instead of undoing it.
+type sagaStep struct {
+	name       string
+	run        func(ctx context.Context, p *Placement) error
+	compensate func(ctx context.Context, p *Placement, cause string) error
+	retry      bool
+}
+
+// Sagas places orders with sagas stored in the order database, and resumes
+// those that were interrupted.
+type Sagas struct {
+	repository    Repository
+	service       Service
+	catalogClient *catalog.Client
+	session       *account.ServiceSession
+	steps         []sagaStep
+}
+
+// NewSagas returns the saga runner. session signs in the service account
+// that places orders and resumes sagas in the background; it needs
+// stock:reserve to reserve stock and product:write to settle reservations
+// made before it placed orders itself.
+func NewSagas(r Repository, s Service, catalogClient *catalog.Client, session *account.ServiceSession) *Sagas {
+	sg := &Sagas{repository: r, service: s, catalogClient: catalogClient, session: session}
+	sg.steps = sg.placementSteps()
+	return sg
+}
+
+// Place runs a saga placing an order as the service account, which
+// reserves stock on behalf of the caller. It returns the error that made the
+// saga undo its steps, or nil if the order was placed, possibly with steps
+// left that are finished in the background or by an operator.
+func (sg *Sagas) Place(ctx context.Context, p Placement) (*Saga, error) {
+	ctx, err := sg.session.Context(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("sign in to place order: %w", err)
+	}
+	now := time.Now().UTC()
+	p.OrderID = ksuid.New().String()
+	saga := &Saga{
+		ID:            ksuid.New().String(),
+		Status:        SagaRunning,
+		Placement:     p,
+		NextAttemptAt: now,
+		LeaseID:       newLeaseID(),
+		LeaseExpires:  now.Add(sagaLease),
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := sg.repository.PutSaga(ctx, *saga); err != nil {
+		return nil, err
+	}
+	// The saga must not stop halfway because the caller went away.
+	return saga, sg.run(context.WithoutCancel(ctx), saga)
+}
+
+// Resume continues sagas whose runner stopped working on them or whose
+// steps are due to be retried, as the service account. Sagas interrupted
+// before the order was placed are undone, since their caller got an error.
+// It returns how many sagas were resumed.
+func (sg *Sagas) Resume(ctx context.Context) (int, error) {
+	sagas, err := sg.repository.ClaimSagas(ctx, newLeaseID(), time.Now().UTC().Add(sagaLease), 10)
+	if err != nil || len(sagas) == 0 {
+		return 0, err
+	}
+	// Claimed sagas the service account cannot sign in for are retried
+	// when their lease runs out.
+	ctx, err = sg.session.Context(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("sign in to resume sagas: %w", err)
+	}
+	for i := range sagas {
+		saga := &sagas[i]
+		if saga.Status == SagaRunning && !sg.steps[saga.Step].retry {
+			// The interrupted step is undone too, in case it took effect.
+			sg.startCompensating(saga, saga.Step+1, "interrupted before the order was placed")
+		}
+		if err := sg.run(ctx, saga); err != nil {
+			log.Printf("saga %s: %v", saga.ID, err)
+		}
+	}
+	return len(sagas), nil
+}
+
+// run takes a saga as far as it can go. Failing steps and compensations are
+// saved with the time to retry them.
+func (sg *Sagas) run(ctx context.Context, saga *Saga) error {
+	var cause error
+	for saga.Status == SagaRunning {
+		step := sg.steps[saga.Step]
+		err := sg.call(ctx, func(ctx context.Context) error { return step.run(ctx, &saga.Placement) })
+		entry := SagaLogEntry{Step: step.name}
+		if err == nil {
+			saga.Step++
+			saga.Attempts = 0
+			saga.Error = ""
+			if saga.Step == len(sg.steps) {
+				saga.Status = SagaCompleted
+			}
+		} else if step.retry && isTemporary(err) {
+			entry.Error = err.Error()
+			sg.scheduleRetry(saga, err)
+			return sg.save(ctx, saga, entry)
+		} else if step.retry {
+			// The order was acknowledged, so it must not be undone.
+			entry.Error = err.Error()
+			saga.Status = SagaStuck
+			saga.Error = err.Error()
+			log.Printf("saga %s is stuck at %s: %v", saga.ID, step.name, err)
+			return sg.save(ctx, saga, entry)
+		} else {
+			entry.Error = err.Error()
+			cause = err
+			// The failed step may have taken effect anyway, e.g. if it
+			// timed out, so it is undone along with the others.
+			sg.startCompensating(saga, saga.Step+1, err.Error())
+		}
+		if err := sg.save(ctx, saga, entry); err != nil {
+			return err
+		}
+	}
+
+	for saga.Status == SagaCompensating {
+		entry := SagaLogEntry{Compensate: true}
+		if saga.Step > 0 {
+			step := sg.steps[saga.Step-1]
+			entry.Step = step.name
+			if step.compensate != nil {
+				reason := saga.Error
+				err := sg.call(ctx, func(ctx context.Context) error { return step.compensate(ctx, &saga.Placement, reason) })
+				if err != nil {
+					entry.Error = err.Error()
+					sg.scheduleRetry(saga, err)
+					if err := sg.save(ctx, saga, entry); err != nil {
+						return err
+					}
+					return cause
+				}
+			}
+			saga.Step--
+			saga.Attempts = 0
+		}
+		if saga.Step == 0 {
+			saga.Status = SagaCompensated
+		}
+		if err := sg.save(ctx, saga, entry); err != nil {
+			return err
+		}
+	}
+	return cause
+}
+
+func (sg *Sagas) call(ctx context.Context, f func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, sagaStepTimeout)
+	defer cancel()
+	return f(ctx)
+}
+
+// startCompensating switches a saga to undoing its first n steps.
+func (sg *Sagas) startCompensating(saga *Saga, n int, cause string) {
+	saga.Status = SagaCompensating
+	saga.Step = n
+	saga.Attempts = 0
+	saga.Error = cause
+}
+
+func (sg *Sagas) scheduleRetry(saga *Saga, err error) {
+	saga.Attempts++
+	backoff := maxSagaBackoff
+	if saga.Attempts < 10 {
+		backoff = min(time.Duration(1<<saga.Attempts)*5*time.Second, maxSagaBackoff)
+	}
+	saga.NextAttemptAt = time.Now().UTC().Add(backoff)
+	if saga.Status == SagaRunning {
+		saga.Error = err.Error()
+	}
+}
+
+// save stores the progress of a saga and extends the runner's lease. Sagas
+// that are finished or wait for a retry are let go.
+func (sg *Sagas) save(ctx context.Context, saga *Saga, entry SagaLogEntry) error {
+	now := time.Now().UTC()
+	saga.UpdatedAt = now
+	saga.LeaseExpires = now.Add(sagaLease)
+	if saga.Status == SagaCompleted || saga.Status == SagaCompensated || saga.Status == SagaStuck || saga.NextAttemptAt.After(now) {
+		saga.LeaseExpires = now
+	}
+	entry.At = now
+	if err := sg.repository.UpdateSaga(ctx, *saga, entry); err != nil {
+		return err
+	}
+	saga.Log = append(saga.Log, entry)
+	return nil
+}
+
+// isTemporary reports whether a failed call may succeed when retried.
+func isTemporary(err error) bool {
+	s, ok := status.FromError(err)
+	if !ok {
+		// Not from another service, e.g. the database.
+		return true
+	}
+	switch s.Code() {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Aborted, codes.ResourceExhausted, codes.Internal, codes.Unknown:
+		return true
+	}
+	return false
+}
+
+func newLeaseID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,262 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeSagaRepository stores sagas in memory and, like the database, only
+// lets the runner holding a saga's lease update it.
+type fakeSagaRepository struct {
+	Repository
+	sagas map[string]Saga
+	log   []SagaLogEntry
+}
+
+func newFakeSagaRepository() *fakeSagaRepository {
+	return &fakeSagaRepository{sagas: map[string]Saga{}}
+}
+
+func (r *fakeSagaRepository) PutSaga(ctx context.Context, s Saga) error {
+	r.sagas[s.ID] = s
+	return nil
+}
+
+func (r *fakeSagaRepository) UpdateSaga(ctx context.Context, s Saga, entry SagaLogEntry) error {
+	if stored, ok := r.sagas[s.ID]; !ok || stored.LeaseID != s.LeaseID {
+		return errSagaLeaseLost
+	}
+	r.sagas[s.ID] = s
+	r.log = append(r.log, entry)
+	return nil
+}
+
+// stepRecorder builds saga steps that fail as told and records what ran.
+type stepRecorder struct {
+	calls []string
+	fail  map[string]error
+}
+
+func (rec *stepRecorder) step(name string, retry bool) sagaStep {
+	return sagaStep{
+		name: name,
+		run: func(ctx context.Context, p *Placement) error {
+			rec.calls = append(rec.calls, "run "+name)
+			return rec.fail["run "+name]
+		},
+		compensate: func(ctx context.Context, p *Placement, cause string) error {
+			rec.calls = append(rec.calls, "compensate "+name)
+			return rec.fail["compensate "+name]
+		},
+		retry: retry,
+	}
+}
+
+func newTestSagas(fail map[string]error) (*Sagas, *fakeSagaRepository, *stepRecorder) {
+	repository := newFakeSagaRepository()
+	rec := &stepRecorder{fail: fail}
+	sg := &Sagas{
+		repository: repository,
+		steps: []sagaStep{
+			rec.step("reserve", false),
+			rec.step("create", false),
+			rec.step("commit", true),
+		},
+	}
+	return sg, repository, rec
+}
+
+func newTestSaga(t *testing.T, repository *fakeSagaRepository) *Saga {
+	now := time.Now().UTC()
+	saga := &Saga{
+		ID:            "saga",
+		Status:        SagaRunning,
+		NextAttemptAt: now,
+		LeaseID:       newLeaseID(),
+		LeaseExpires:  now.Add(sagaLease),
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := repository.PutSaga(context.Background(), *saga); err != nil {
+		t.Fatal(err)
+	}
+	return saga
+}
+
+var (
+	errRejected    = status.Error(codes.FailedPrecondition, "rejected")
+	errUnavailable = status.Error(codes.Unavailable, "unavailable")
+)
+
+func TestSagaRun(t *testing.T) {
+	tests := []struct {
+		name     string
+		fail     map[string]error
+		err      error
+		status   SagaStatus
+		step     int
+		attempts int
+		calls    []string
+	}{
+		{
+			name:   "completes",
+			status: SagaCompleted,
+			step:   3,
+			calls:  []string{"run reserve", "run create", "run commit"},
+		},
+		{
+			name:   "first step fails",
+			fail:   map[string]error{"run reserve": errRejected},
+			err:    errRejected,
+			status: SagaCompensated,
+			calls:  []string{"run reserve", "compensate reserve"},
+		},
+		{
+			name:   "step before the point of no return fails",
+			fail:   map[string]error{"run create": errRejected},
+			err:    errRejected,
+			status: SagaCompensated,
+			calls:  []string{"run reserve", "run create", "compensate create", "compensate reserve"},
+		},
+		{
+			name:   "step before the point of no return fails temporarily",
+			fail:   map[string]error{"run create": errUnavailable},
+			err:    errUnavailable,
+			status: SagaCompensated,
+			calls:  []string{"run reserve", "run create", "compensate create", "compensate reserve"},
+		},
+		{
+			name:     "retried step fails temporarily",
+			fail:     map[string]error{"run commit": errUnavailable},
+			status:   SagaRunning,
+			step:     2,
+			attempts: 1,
+			calls:    []string{"run reserve", "run create", "run commit"},
+		},
+		{
+			name:   "retried step fails for good",
+			fail:   map[string]error{"run commit": errRejected},
+			status: SagaStuck,
+			step:   2,
+			calls:  []string{"run reserve", "run create", "run commit"},
+		},
+		{
+			name:     "compensation fails",
+			fail:     map[string]error{"run create": errRejected, "compensate reserve": errUnavailable},
+			err:      errRejected,
+			status:   SagaCompensating,
+			step:     1,
+			attempts: 1,
+			calls:    []string{"run reserve", "run create", "compensate create", "compensate reserve"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sg, repository, rec := newTestSagas(tt.fail)
+			saga := newTestSaga(t, repository)
+
+			err := sg.run(context.Background(), saga)
+			if err != tt.err {
+				t.Errorf("run() error = %v, want %v", err, tt.err)
+			}
+			if !reflect.DeepEqual(rec.calls, tt.calls) {
+				t.Errorf("calls = %v, want %v", rec.calls, tt.calls)
+			}
+			stored := repository.sagas[saga.ID]
+			if stored.Status != tt.status || stored.Step != tt.step || stored.Attempts != tt.attempts {
+				t.Errorf("saga is %s at step %d after %d attempts, want %s at step %d after %d attempts",
+					stored.Status, stored.Step, stored.Attempts, tt.status, tt.step, tt.attempts)
+			}
+			if len(repository.log) != len(tt.calls) {
+				t.Errorf("logged %d entries, want one per call: %d", len(repository.log), len(tt.calls))
+			}
+		})
+	}
+}
+
+func TestSagaRunReleasesLease(t *testing.T) {
+	tests := []struct {
+		name string
+		fail map[string]error
+	}{
+		{"completed", nil},
+		{"compensated", map[string]error{"run create": errRejected}},
+		{"stuck", map[string]error{"run commit": errRejected}},
+		{"waiting for a retry", map[string]error{"run commit": errUnavailable}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sg, repository, _ := newTestSagas(tt.fail)
+			saga := newTestSaga(t, repository)
+			sg.run(context.Background(), saga)
+
+			stored := repository.sagas[saga.ID]
+			if stored.LeaseExpires.After(stored.UpdatedAt) {
+				t.Errorf("lease held until %v, want it released at %v", stored.LeaseExpires, stored.UpdatedAt)
+			}
+		})
+	}
+}
+
+func TestSagaRunRetriesAfterTemporaryFailure(t *testing.T) {
+	sg, repository, rec := newTestSagas(map[string]error{"run commit": errUnavailable})
+	saga := newTestSaga(t, repository)
+	if err := sg.run(context.Background(), saga); err != nil {
+		t.Fatal(err)
+	}
+	if !saga.NextAttemptAt.After(time.Now()) {
+		t.Errorf("next attempt at %v, want a later time", saga.NextAttemptAt)
+	}
+
+	// The next runner claims the saga and the catalog is back.
+	delete(rec.fail, "run commit")
+	saga.LeaseID = newLeaseID()
+	repository.sagas[saga.ID] = *saga
+	if err := sg.run(context.Background(), saga); err != nil {
+		t.Fatal(err)
+	}
+	if saga.Status != SagaCompleted || saga.Attempts != 0 || saga.Error != "" {
+		t.Errorf("saga is %s after %d attempts with error %q, want completed", saga.Status, saga.Attempts, saga.Error)
+	}
+}
+
+func TestSagaRunStopsWhenLeaseIsLost(t *testing.T) {
+	sg, repository, rec := newTestSagas(nil)
+	saga := newTestSaga(t, repository)
+
+	// Another runner took over the saga.
+	stored := repository.sagas[saga.ID]
+	stored.LeaseID = newLeaseID()
+	repository.sagas[saga.ID] = stored
+
+	if err := sg.run(context.Background(), saga); !errors.Is(err, errSagaLeaseLost) {
+		t.Errorf("run() error = %v, want %v", err, errSagaLeaseLost)
+	}
+	if want := []string{"run reserve"}; !reflect.DeepEqual(rec.calls, want) {
+		t.Errorf("calls = %v, want %v", rec.calls, want)
+	}
+}
+
+func TestScheduleRetryBacksOff(t *testing.T) {
+	sg := &Sagas{}
+	saga := &Saga{Status: SagaRunning}
+	var last time.Duration
+	for i := 0; i < 15; i++ {
+		before := time.Now().UTC()
+		sg.scheduleRetry(saga, errUnavailable)
+		backoff := saga.NextAttemptAt.Sub(before)
+		if backoff < last-time.Second || backoff > maxSagaBackoff+time.Second {
+			t.Fatalf("attempt %d backs off %v after %v", saga.Attempts, backoff, last)
+		}
+		last = backoff
+	}
+	if last < maxSagaBackoff-time.Second {
+		t.Errorf("backoff after %d attempts = %v, want %v", saga.Attempts, last, maxSagaBackoff)
+	}
+}
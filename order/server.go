@@ -7,12 +7,17 @@ import (
 	"fmt"
 	"log"
 	"net"
+	"strings"
 
 	account "github.com/suryanshp1/go-microservice/account"
 	catalog "github.com/suryanshp1/go-microservice/catalog"
+	"github.com/suryanshp1/go-microservice/money"
 	"github.com/suryanshp1/go-microservice/order/pb"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 )
 
 type grpcServer struct {
@@ -20,33 +25,31 @@ type grpcServer struct {
 	service       Service
 	accountClient *account.Client
 	catalogClient *catalog.Client
+	sagas         *Sagas
 }
 
-func ListenGRPC(s Service, accountURL, catalogURL string, port int) error {
-	accountClient, err := account.NewClient(accountURL)
-	if err != nil {
-		return err
-	}
-
-	catalogClient, err := catalog.NewClient(catalogURL)
-	if err != nil {
-		accountClient.Close()
-		return err
-	}
-
+// ListenGRPC serves the order API. It takes its clients rather than their
+// URLs, because the sagas resumed in the background share them.
+func ListenGRPC(
+	s Service,
+	accountClient *account.Client,
+	catalogClient *catalog.Client,
+	sagas *Sagas,
+	verifier *account.TokenVerifier,
+	port int,
+) error {
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
 	if err != nil {
-		accountClient.Close()
-		catalogClient.Close()
 		return err
 	}
 
-	serv := grpc.NewServer()
+	serv := grpc.NewServer(grpc.UnaryInterceptor(account.UnaryServerInterceptor(verifier)))
 	pb.RegisterOrderServiceServer(serv, &grpcServer{
 		pb.UnimplementedOrderServiceServer{},
 		s,
 		accountClient,
 		catalogClient,
+		sagas,
 	})
 	reflection.Register(serv)
 
@@ -54,132 +57,403 @@ func ListenGRPC(s Service, accountURL, catalogURL string, port int) error {
 }
 
 func (s *grpcServer) PostOrder(ctx context.Context, req *pb.PostOrderRequest) (*pb.PostOrderResponse, error) {
-	_, err := s.accountClient.GetAccount(ctx, req.AccountId)
+	if err := s.accountClient.Authorize(ctx, account.PermissionOrderCreate); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.AccountId) != account.ClaimsFromContext(ctx).AccountID() {
+		if err := s.accountClient.Authorize(ctx, account.PermissionOrderWriteAny); err != nil {
+			return nil, err
+		}
+	}
+
+	a, err := s.accountClient.GetAccount(ctx, req.AccountId)
+	if status.Code(err) == codes.NotFound {
+		return nil, err
+	}
 	if err != nil {
 		log.Printf("failed to get account: %v", err)
 		return nil, errors.New("failed to get account")
 	}
+	if !a.IsActive() {
+		return nil, status.Error(codes.FailedPrecondition, account.ErrAccountDeactivated.Error())
+	}
+
+	if err := validateOrderLines(req.Products); err != nil {
+		return nil, err
+	}
+	currency := strings.ToUpper(strings.TrimSpace(req.Currency))
+	if currency != "" && !money.ValidCurrency(currency) {
+		return nil, badRequest([]*errdetails.BadRequest_FieldViolation{{
+			Field:       "currency",
+			Description: money.ErrInvalidCurrency.Error(),
+		}})
+	}
+
+	// A retried request returns the order created by the first attempt
+	// without touching the catalog again.
+	requested := []OrderedProduct{}
+	for _, rp := range req.Products {
+		requested = append(requested, OrderedProduct{ID: rp.ProductId, VariantID: rp.VariantId, Quantity: rp.Quantity})
+	}
+	existing, err := s.service.GetIdempotentOrder(ctx, req.AccountId, currency, requested, req.IdempotencyKey)
+	if err != nil {
+		log.Printf("failed to look up idempotency key: %v", err)
+		return nil, toStatus(err)
+	}
+	if existing != nil {
+		return &pb.PostOrderResponse{Order: orderToProto(existing)}, nil
+	}
 
 	productIDs := []string{}
-	orderProducts, err := s.catalogClient.GetProducts(ctx, 0, 0, productIDs, "")
+	for _, rp := range req.Products {
+		productIDs = append(productIDs, rp.ProductId)
+	}
+	orderProducts, err := s.catalogClient.GetProducts(ctx, 0, 0, productIDs, "", currency)
+	if status.Code(err) == codes.FailedPrecondition {
+		// No exchange rate to the requested currency.
+		return nil, err
+	}
 	if err != nil {
 		log.Printf("failed to get products: %v", err)
 		return nil, errors.New("failed to get products")
 	}
 
-	products := []OrderedProduct{}
+	catalogProducts := map[string]*catalog.Product{}
 	for _, p := range orderProducts {
-		product := OrderedProduct{
+		catalogProducts[p.ID] = p
+	}
+
+	products := []OrderedProduct{}
+	violations := []*errdetails.BadRequest_FieldViolation{}
+	for i, rp := range req.Products {
+		p, ok := catalogProducts[rp.ProductId]
+		if !ok {
+			violations = append(violations, lineViolation(i, "productId", "product %s does not exist", rp.ProductId))
+			continue
+		}
+		available, sku := p.Available(), ""
+		if rp.VariantId != "" {
+			v := p.Variant(rp.VariantId)
+			if v == nil {
+				violations = append(violations, lineViolation(i, "variantId", "product %s has no variant %s", rp.ProductId, rp.VariantId))
+				continue
+			}
+			available, sku = v.Available(), v.SKU
+		} else if len(p.Variants) > 0 {
+			violations = append(violations, lineViolation(i, "variantId", "product %s comes in variants, one must be chosen", rp.ProductId))
+			continue
+		}
+		if int64(rp.Quantity) > available {
+			violations = append(violations, lineViolation(i, "quantity", "only %d of product %s in stock", available, rp.ProductId))
+			continue
+		}
+		price := p.PriceOf(rp.VariantId)
+		if len(products) > 0 && price.Currency != products[0].Price.Currency {
+			violations = append(violations, lineViolation(i, "productId", "product %s is priced in %s, other products in %s", rp.ProductId, price.Currency, products[0].Price.Currency))
+			continue
+		}
+		line := OrderedProduct{
 			ID:          p.ID,
-			Quantity:    0.0,
-			Price:       p.Price,
+			VariantID:   rp.VariantId,
+			SKU:         sku,
+			Quantity:    rp.Quantity,
+			Price:       price,
 			Name:        p.Name,
 			Description: p.Description,
 		}
-		for _, rp := range req.Products {
-			if rp.ProductId == p.ID {
-				product.Quantity = rp.Quantity
-				break
-			}
+		if p.ExchangeRate != nil {
+			line.ExchangeRate = &p.ExchangeRate.Rate
 		}
-		if product.Quantity != 0.0 {
-			products = append(products, product)
+		products = append(products, line)
+	}
+	if len(violations) > 0 {
+		return nil, badRequest(violations)
+	}
+
+	// Reserve the stock, persist the order and commit the reservation in a
+	// saga, so that a failing step undoes the ones before it. The
+	// availability check above is only a hint; the reservation is what
+	// prevents overselling.
+	saga, err := s.sagas.Place(ctx, Placement{
+		AccountID:      req.AccountId,
+		Currency:       currency,
+		Products:       products,
+		IdempotencyKey: req.IdempotencyKey,
+	})
+	if err != nil {
+		if saga != nil {
+			log.Printf("saga %s failed to post order: %v", saga.ID, err)
+		} else {
+			log.Printf("failed to start saga: %v", err)
+		}
+		if err == ErrIdempotencyKeyReused {
+			return nil, toStatus(err)
 		}
+		if _, ok := status.FromError(err); ok {
+			// From the catalog, e.g. insufficient stock.
+			return nil, err
+		}
+		return nil, errors.New("failed to post order")
 	}
-	order, err := s.service.PostOrder(ctx, req.AccountId, products)
 
+	order, err := s.service.GetOrder(ctx, saga.Placement.OrderID)
 	if err != nil {
-		log.Printf("failed to post order: %v", err)
+		log.Printf("failed to get order %s placed by saga %s: %v", saga.Placement.OrderID, saga.ID, err)
 		return nil, errors.New("failed to post order")
 	}
+	return &pb.PostOrderResponse{
+		Order: orderToProto(order),
+	}, nil
+}
 
-	orderProto := &pb.Order{
-		Id:         order.ID,
-		AccountId:  order.AccountID,
-		Products:   []*pb.Order_OrderProduct{},
-		TotalPrice: order.TotalPrice,
+func (s *grpcServer) GetOrder(ctx context.Context, r *pb.GetOrderRequest) (*pb.GetOrderResponse, error) {
+	claims := account.ClaimsFromContext(ctx)
+	if claims == nil {
+		return nil, status.Error(codes.Unauthenticated, "authentication required")
 	}
 
-	orderProto.CreatedAt, _ = order.CreatedAt.MarshalBinary()
-	orderProto.UpdatedAt, _ = order.UpdatedAt.MarshalBinary()
+	o, err := s.service.GetOrder(ctx, r.Id)
+	if err != nil {
+		log.Println(err)
+		return nil, toStatus(err)
+	}
 
-	for _, p := range order.Products {
-		orderProto.Products = append(orderProto.Products, &pb.Order_OrderProduct{
-			Id:          p.ID,
-			Quantity:    p.Quantity,
-			Price:       p.Price,
-			Name:        p.Name,
-			Description: p.Description,
-		})
+	// Only the owner and support can read an order. Others get the same
+	// answer as for a missing order so order IDs cannot be probed.
+	if strings.TrimSpace(o.AccountID) != claims.AccountID() {
+		allowed, err := s.accountClient.CheckPermission(ctx, claims.AccountID(), account.PermissionOrderReadAny)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, toStatus(ErrOrderNotFound)
+		}
 	}
 
-	return &pb.PostOrderResponse{
-		Order: orderProto,
-	}, nil
+	return &pb.GetOrderResponse{Order: orderToProto(o)}, nil
 }
 
 func (s *grpcServer) GetOrdersForAccount(
 	ctx context.Context,
 	r *pb.GetOrdersForAccountRequest,
 ) (*pb.GetOrdersForAccountResponse, error) {
-	// Get orders for account
-	accountOrders, err := s.service.GetOrdersForAccount(ctx, r.AccountId)
+	claims := account.ClaimsFromContext(ctx)
+	if claims == nil {
+		return nil, status.Error(codes.Unauthenticated, "authentication required")
+	}
+	if strings.TrimSpace(r.AccountId) != claims.AccountID() {
+		if err := s.accountClient.Authorize(ctx, account.PermissionOrderReadAny); err != nil {
+			return nil, err
+		}
+	}
+
+	q := Query{
+		Sort:  Sort(r.Sort),
+		First: int(r.First),
+		After: r.After,
+	}
+	if f := r.Filter; f != nil {
+		if len(f.CreatedAfter) > 0 {
+			if err := q.Filter.CreatedAfter.UnmarshalBinary(f.CreatedAfter); err != nil {
+				return nil, status.Error(codes.InvalidArgument, "invalid createdAfter")
+			}
+		}
+		if len(f.CreatedBefore) > 0 {
+			if err := q.Filter.CreatedBefore.UnmarshalBinary(f.CreatedBefore); err != nil {
+				return nil, status.Error(codes.InvalidArgument, "invalid createdBefore")
+			}
+		}
+		for _, st := range f.Statuses {
+			q.Filter.Statuses = append(q.Filter.Statuses, Status(st))
+		}
+		q.Filter.MinTotal = optionalMoneyFromProto(f.MinTotal)
+		q.Filter.MaxTotal = optionalMoneyFromProto(f.MaxTotal)
+	}
+
+	page, err := s.service.GetOrdersForAccount(ctx, r.AccountId, q)
 	if err != nil {
 		log.Println(err)
-		return nil, err
+		return nil, toStatus(err)
 	}
 
-	// Get all ordered products
-	productIDMap := map[string]bool{}
-	for _, o := range accountOrders {
-		for _, p := range o.Products {
-			productIDMap[p.ID] = true
-		}
+	edges := []*pb.OrderEdge{}
+	for _, e := range page.Edges {
+		edges = append(edges, &pb.OrderEdge{
+			Cursor: e.Cursor,
+			Order:  orderToProto(&e.Order),
+		})
 	}
-	productIDs := []string{}
-	for id := range productIDMap {
-		productIDs = append(productIDs, id)
+	return &pb.GetOrdersForAccountResponse{
+		Edges:       edges,
+		EndCursor:   page.EndCursor,
+		HasNextPage: page.HasNextPage,
+	}, nil
+}
+
+func (s *grpcServer) UpdateOrderStatus(
+	ctx context.Context,
+	r *pb.UpdateOrderStatusRequest,
+) (*pb.UpdateOrderStatusResponse, error) {
+	if err := s.accountClient.Authorize(ctx, account.PermissionOrderWriteAny); err != nil {
+		return nil, err
 	}
-	products, err := s.catalogClient.GetProducts(ctx, 0, 0, productIDs, "")
+
+	changedBy := account.ClaimsFromContext(ctx).AccountID()
+	change, err := s.service.UpdateOrderStatus(ctx, r.OrderId, Status(r.Status), changedBy, r.Reason)
 	if err != nil {
-		log.Println("Error getting account products: ", err)
+		log.Println(err)
+		return nil, toStatus(err)
+	}
+	return &pb.UpdateOrderStatusResponse{Change: statusChangeToProto(*change)}, nil
+}
+
+func (s *grpcServer) CancelOrder(
+	ctx context.Context,
+	r *pb.CancelOrderRequest,
+) (*pb.CancelOrderResponse, error) {
+	claims := account.ClaimsFromContext(ctx)
+	if claims == nil {
+		return nil, status.Error(codes.Unauthenticated, "authentication required")
+	}
+
+	// Customers may cancel their own orders; support can cancel any order.
+	owner := claims.AccountID()
+	if allowed, err := s.accountClient.CheckPermission(ctx, owner, account.PermissionOrderWriteAny); err != nil {
 		return nil, err
+	} else if allowed {
+		owner = ""
+	}
+
+	change, err := s.service.CancelOrder(ctx, r.OrderId, owner, claims.AccountID(), r.Reason)
+	if err != nil {
+		log.Println(err)
+		return nil, toStatus(err)
+	}
+	return &pb.CancelOrderResponse{Change: statusChangeToProto(*change)}, nil
+}
+
+// orderToProto encodes an order. Products are stored as they were at
+// purchase time, so the catalog is not consulted.
+func orderToProto(o *Order) *pb.Order {
+	op := &pb.Order{
+		Id:         o.ID,
+		AccountId:  o.AccountID,
+		TotalPrice: moneyToProto(o.TotalPrice),
+		Products:   []*pb.Order_OrderProduct{},
 	}
+	op.CreatedAt, _ = o.CreatedAt.MarshalBinary()
+	op.UpdatedAt, _ = o.UpdatedAt.MarshalBinary()
 
-	// Construct orders
-	orders := []*pb.Order{}
-	for _, o := range accountOrders {
-		// Encode order
-		op := &pb.Order{
-			AccountId:  o.AccountID,
-			Id:         o.ID,
-			TotalPrice: o.TotalPrice,
-			Products:   []*pb.Order_OrderProduct{},
-		}
-		op.CreatedAt, _ = o.CreatedAt.MarshalBinary()
-
-		// Decorate orders with products
-		for _, product := range o.Products {
-			// Populate product fields
-			for _, p := range products {
-				if p.ID == product.ID {
-					product.Name = p.Name
-					product.Description = p.Description
-					product.Price = p.Price
-					break
-				}
+	op.Status = string(o.Status)
+	for _, change := range o.StatusHistory {
+		op.StatusHistory = append(op.StatusHistory, statusChangeToProto(change))
+	}
+
+	for _, p := range o.Products {
+		pp := &pb.Order_OrderProduct{
+			Id:          p.ID,
+			VariantId:   p.VariantID,
+			Sku:         p.SKU,
+			Name:        p.Name,
+			Description: p.Description,
+			Price:       moneyToProto(p.Price),
+			Quantity:    p.Quantity,
+		}
+		if p.ExchangeRate != nil {
+			pp.ExchangeRate = &pb.ExchangeRate{
+				From: p.ExchangeRate.From,
+				To:   p.ExchangeRate.To,
+				Rate: p.ExchangeRate.Value,
 			}
+		}
+		op.Products = append(op.Products, pp)
+	}
+	return op
+}
+
+func moneyToProto(m money.Money) *pb.Money {
+	return &pb.Money{Amount: m.Amount, Currency: m.Currency}
+}
+
+func optionalMoneyToProto(m *money.Money) *pb.Money {
+	if m == nil {
+		return nil
+	}
+	return moneyToProto(*m)
+}
+
+func statusChangeToProto(c StatusChange) *pb.StatusChange {
+	cp := &pb.StatusChange{
+		From:      string(c.From),
+		To:        string(c.To),
+		ChangedBy: c.ChangedBy,
+		Reason:    c.Reason,
+	}
+	cp.ChangedAt, _ = c.ChangedAt.MarshalBinary()
+	return cp
+}
+
+func toStatus(err error) error {
+	switch err {
+	case ErrOrderNotFound:
+		return status.Error(codes.NotFound, err.Error())
+	case ErrInvalidStatus, ErrInvalidSort, ErrInvalidCursor, ErrInvalidFilter:
+		return status.Error(codes.InvalidArgument, err.Error())
+	case ErrInvalidTransition, ErrStatusConflict:
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case ErrIdempotencyKeyReused:
+		return status.Error(codes.AlreadyExists, err.Error())
+	}
+	return err
+}
+
+// validateOrderLines checks the requested lines on their own, before any
+// product is looked up in the catalog.
+func validateOrderLines(lines []*pb.PostOrderRequest_OrderProduct) error {
+	if len(lines) == 0 {
+		return badRequest([]*errdetails.BadRequest_FieldViolation{{
+			Field:       "products",
+			Description: "order must contain at least one product",
+		}})
+	}
 
-			op.Products = append(op.Products, &pb.Order_OrderProduct{
-				Id:          product.ID,
-				Name:        product.Name,
-				Description: product.Description,
-				Price:       product.Price,
-				Quantity:    product.Quantity,
-			})
+	violations := []*errdetails.BadRequest_FieldViolation{}
+	seen := map[string]int{}
+	for i, line := range lines {
+		if line.ProductId == "" {
+			violations = append(violations, lineViolation(i, "productId", "product ID is required"))
+			continue
+		}
+		key := line.ProductId + "/" + line.VariantId
+		if first, ok := seen[key]; ok {
+			violations = append(violations, lineViolation(i, "productId", "product %s is already ordered in products[%d]", line.ProductId, first))
+		} else {
+			seen[key] = i
 		}
+		if line.Quantity == 0 {
+			violations = append(violations, lineViolation(i, "quantity", "quantity must be greater than zero"))
+		}
+	}
+	if len(violations) > 0 {
+		return badRequest(violations)
+	}
+	return nil
+}
 
-		orders = append(orders, op)
+func lineViolation(line int, field, format string, args ...interface{}) *errdetails.BadRequest_FieldViolation {
+	return &errdetails.BadRequest_FieldViolation{
+		Field:       fmt.Sprintf("products[%d].%s", line, field),
+		Description: fmt.Sprintf(format, args...),
+	}
+}
+
+// badRequest returns an InvalidArgument status carrying the violations as
+// google.rpc.BadRequest details.
+func badRequest(violations []*errdetails.BadRequest_FieldViolation) error {
+	st := status.New(codes.InvalidArgument, "invalid order")
+	detailed, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+	if err != nil {
+		return st.Err()
 	}
-	return &pb.GetOrdersForAccountResponse{Orders: orders}, nil
+	return detailed.Err()
 }
@@ -2,31 +2,59 @@ package order
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/segmentio/ksuid"
+	"github.com/suryanshp1/go-microservice/money"
+)
+
+var (
+	ErrIdempotencyKeyReused = errors.New("idempotency key was already used with a different order")
 )
 
 type Service interface {
-	PostOrder(ctx context.Context, accountID string, products []OrderedProduct) (*Order, error)
-	GetOrdersForAccount(ctx context.Context, accountID string) ([]Order, error)
+	PostOrder(ctx context.Context, id, accountID, currency string, products []OrderedProduct, idempotencyKey, reservationID string) (*Order, error)
+	GetIdempotentOrder(ctx context.Context, accountID, currency string, products []OrderedProduct, idempotencyKey string) (*Order, error)
+	GetOrder(ctx context.Context, id string) (*Order, error)
+	GetOrdersForAccount(ctx context.Context, accountID string, q Query) (*Page, error)
+	UpdateOrderStatus(ctx context.Context, orderID string, status Status, changedBy, reason string) (*StatusChange, error)
+	CancelOrder(ctx context.Context, orderID, accountID, changedBy, reason string) (*StatusChange, error)
 }
 
 type Order struct {
-	ID         string
-	CreatedAt  time.Time
-	UpdatedAt  time.Time
-	TotalPrice float64
-	AccountID  string
-	Products   []OrderedProduct
+	ID             string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	TotalPrice     money.Money
+	AccountID      string
+	Status         Status
+	StatusHistory  []StatusChange
+	Products       []OrderedProduct
+	IdempotencyKey string
+	RequestHash    string
+	ReservationID  string
 }
 
+// OrderedProduct is a line of an order. Name, description, SKU and price
+// are a snapshot of the product at the time the order was placed.
+// VariantID is set if the product comes in variants. ExchangeRate is the
+// rate the price was converted at if the product is priced in another
+// currency than the order.
 type OrderedProduct struct {
-	ID          string
-	Name        string
-	Description string
-	Price       float64
-	Quantity    uint32
+	ID           string
+	VariantID    string
+	SKU          string
+	Name         string
+	Description  string
+	Price        money.Money
+	ExchangeRate *money.Rate
+	Quantity     uint32
 }
 
 type orderService struct {
@@ -37,29 +65,216 @@ func NewService(r Repository) Service {
 	return &orderService{r}
 }
 
+// PostOrder places an order with the given ID, or a new one if id is empty.
+// currency is the currency the order was requested in, if any; the products
+// must already be priced in the currency of the order.
 func (s orderService) PostOrder(
 	ctx context.Context,
+	id string,
 	accountID string,
+	currency string,
 	products []OrderedProduct,
+	idempotencyKey string,
+	reservationID string,
 ) (*Order, error) {
+	if id == "" {
+		id = ksuid.New().String()
+	}
+	now := time.Now().UTC()
 	o := &Order{
-		ID:        ksuid.New().String(),
-		CreatedAt: time.Now().UTC(),
+		ID:        id,
+		CreatedAt: now,
+		UpdatedAt: now,
 		AccountID: accountID,
-		Products:  products,
+		Status:    StatusPending,
+		StatusHistory: []StatusChange{{
+			To:        StatusPending,
+			ChangedBy: accountID,
+			ChangedAt: now,
+		}},
+		Products:       products,
+		IdempotencyKey: idempotencyKey,
+		RequestHash:    requestHash(accountID, currency, products),
+		ReservationID:  reservationID,
 	}
-	// Calculate total price
-	o.TotalPrice = 0.0
+	// The total is exact: unit prices times quantities, never rounded. All
+	// lines must be in the same currency.
 	for _, p := range products {
-		o.TotalPrice += p.Price * float64(p.Quantity)
+		line, err := p.Price.Mul(int64(p.Quantity))
+		if err != nil {
+			return nil, err
+		}
+		if o.TotalPrice, err = o.TotalPrice.Add(line); err != nil {
+			return nil, err
+		}
 	}
 	err := s.repository.PutOrder(ctx, *o)
+	if err == ErrDuplicateIdempotencyKey {
+		// A concurrent request with the same key won the race.
+		return s.GetIdempotentOrder(ctx, accountID, currency, products, idempotencyKey)
+	}
 	if err != nil {
 		return nil, err
 	}
 	return o, nil
 }
 
-func (s orderService) GetOrdersForAccount(ctx context.Context, accountID string) ([]Order, error) {
-	return s.repository.GetOrdersForAccount(ctx, accountID)
+// GetIdempotentOrder returns the order previously placed by accountID with
+// idempotencyKey, or nil if there is none. Reusing a key for a different
+// set of products or another currency fails with ErrIdempotencyKeyReused.
+func (s orderService) GetIdempotentOrder(
+	ctx context.Context,
+	accountID string,
+	currency string,
+	products []OrderedProduct,
+	idempotencyKey string,
+) (*Order, error) {
+	if idempotencyKey == "" {
+		return nil, nil
+	}
+	o, err := s.repository.GetOrderByIdempotencyKey(ctx, accountID, idempotencyKey)
+	if err != nil || o == nil {
+		return nil, err
+	}
+	if o.RequestHash != requestHash(accountID, currency, products) {
+		return nil, ErrIdempotencyKeyReused
+	}
+	return o, nil
+}
+
+// requestHash fingerprints what was ordered: the account, the requested
+// currency and the quantity of each product or variant, independent of line
+// order.
+func requestHash(accountID, currency string, products []OrderedProduct) string {
+	lines := []string{}
+	for _, p := range products {
+		if p.VariantID == "" {
+			lines = append(lines, fmt.Sprintf("%s:%d", p.ID, p.Quantity))
+		} else {
+			lines = append(lines, fmt.Sprintf("%s/%s:%d", p.ID, p.VariantID, p.Quantity))
+		}
+	}
+	sort.Strings(lines)
+	// Orders without a requested currency hash as they did before
+	// currencies could be requested.
+	if currency != "" {
+		lines = append([]string{"currency:" + currency}, lines...)
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s", strings.TrimSpace(accountID), strings.Join(lines, "\n"))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (s orderService) GetOrder(ctx context.Context, id string) (*Order, error) {
+	return s.repository.GetOrder(ctx, id)
+}
+
+func (s orderService) GetOrdersForAccount(ctx context.Context, accountID string, q Query) (*Page, error) {
+	if q.Sort == "" {
+		q.Sort = SortCreatedAtDesc
+	}
+	if !q.Sort.IsValid() {
+		return nil, ErrInvalidSort
+	}
+	if q.First <= 0 {
+		q.First = DefaultPageSize
+	}
+	if q.First > MaxPageSize {
+		q.First = MaxPageSize
+	}
+	for _, status := range q.Filter.Statuses {
+		if !status.IsValid() {
+			return nil, ErrInvalidStatus
+		}
+	}
+	if err := q.Filter.validateTotals(); err != nil {
+		return nil, err
+	}
+	if q.After != "" {
+		if _, err := decodeCursor(q.After, q.Sort); err != nil {
+			return nil, err
+		}
+	}
+
+	// Fetch one extra order to find out whether there is another page.
+	limit := q.First
+	q.First++
+	orders, err := s.repository.GetOrdersForAccount(ctx, accountID, q)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &Page{Edges: []Edge{}}
+	if len(orders) > limit {
+		orders = orders[:limit]
+		page.HasNextPage = true
+	}
+	for _, o := range orders {
+		page.Edges = append(page.Edges, Edge{Cursor: newCursor(q.Sort, o).encode(), Order: o})
+	}
+	if len(page.Edges) > 0 {
+		page.EndCursor = page.Edges[len(page.Edges)-1].Cursor
+	}
+	return page, nil
+}
+
+func (s orderService) UpdateOrderStatus(
+	ctx context.Context,
+	orderID string,
+	status Status,
+	changedBy string,
+	reason string,
+) (*StatusChange, error) {
+	if !status.IsValid() {
+		return nil, ErrInvalidStatus
+	}
+	_, current, err := s.repository.GetOrderStatus(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return s.changeStatus(ctx, orderID, current, status, changedBy, reason)
+}
+
+// CancelOrder cancels an order placed by accountID. An empty accountID
+// cancels the order regardless of who placed it.
+func (s orderService) CancelOrder(
+	ctx context.Context,
+	orderID string,
+	accountID string,
+	changedBy string,
+	reason string,
+) (*StatusChange, error) {
+	owner, current, err := s.repository.GetOrderStatus(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if accountID != "" && owner != accountID {
+		return nil, ErrOrderNotFound
+	}
+	return s.changeStatus(ctx, orderID, current, StatusCancelled, changedBy, reason)
+}
+
+func (s orderService) changeStatus(
+	ctx context.Context,
+	orderID string,
+	from Status,
+	to Status,
+	changedBy string,
+	reason string,
+) (*StatusChange, error) {
+	if !from.CanTransitionTo(to) {
+		return nil, ErrInvalidTransition
+	}
+	change := &StatusChange{
+		From:      from,
+		To:        to,
+		ChangedBy: changedBy,
+		Reason:    reason,
+		ChangedAt: time.Now().UTC(),
+	}
+	if err := s.repository.PutOrderStatus(ctx, orderID, *change); err != nil {
+		return nil, err
+	}
+	return change, nil
 }
@@ -0,0 +1,64 @@
+package order
+
+import (
+	"errors"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusConfirmed Status = "confirmed"
+	StatusPaid      Status = "paid"
+	StatusShipped   Status = "shipped"
+	StatusDelivered Status = "delivered"
+	StatusCancelled Status = "cancelled"
+	StatusRefunded  Status = "refunded"
+)
+
+var (
+	ErrOrderNotFound     = errors.New("order not found")
+	ErrInvalidStatus     = errors.New("invalid order status")
+	ErrInvalidTransition = errors.New("order status transition not allowed")
+	ErrStatusConflict    = errors.New("order status was changed concurrently")
+)
+
+// transitions lists the statuses an order may move to from each status.
+// Orders paid for right away skip confirmation. Cancelled and refunded
+// orders are final.
+var transitions = map[Status][]Status{
+	StatusPending:   {StatusConfirmed, StatusPaid, StatusCancelled},
+	StatusConfirmed: {StatusPaid, StatusCancelled},
+	StatusPaid:      {StatusShipped, StatusRefunded},
+	StatusShipped:   {StatusDelivered},
+	StatusDelivered: {StatusRefunded},
+}
+
+func (s Status) IsValid() bool {
+	switch s {
+	case StatusPending, StatusConfirmed, StatusPaid, StatusShipped,
+		StatusDelivered, StatusCancelled, StatusRefunded:
+		return true
+	}
+	return false
+}
+
+func (s Status) CanTransitionTo(to Status) bool {
+	for _, next := range transitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusChange records a single status transition of an order. From is
+// empty for the change that created the order.
+type StatusChange struct {
+	From      Status
+	To        Status
+	ChangedBy string
+	Reason    string
+	ChangedAt time.Time
+}
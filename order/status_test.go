@@ -0,0 +1,53 @@
+package order
+
+import "testing"
+
+func TestCanTransitionTo(t *testing.T) {
+	all := []Status{
+		StatusPending, StatusConfirmed, StatusPaid, StatusShipped,
+		StatusDelivered, StatusCancelled, StatusRefunded,
+	}
+	allowed := map[Status][]Status{
+		StatusPending:   {StatusConfirmed, StatusPaid, StatusCancelled},
+		StatusConfirmed: {StatusPaid, StatusCancelled},
+		StatusPaid:      {StatusShipped, StatusRefunded},
+		StatusShipped:   {StatusDelivered},
+		StatusDelivered: {StatusRefunded},
+	}
+	for _, from := range all {
+		for _, to := range all {
+			want := false
+			for _, s := range allowed[from] {
+				if s == to {
+					want = true
+				}
+			}
+			if got := from.CanTransitionTo(to); got != want {
+				t.Errorf("%s.CanTransitionTo(%s) = %v, want %v", from, to, got, want)
+			}
+		}
+	}
+}
+
+func TestIsValid(t *testing.T) {
+	tests := []struct {
+		status Status
+		want   bool
+	}{
+		{StatusPending, true},
+		{StatusConfirmed, true},
+		{StatusPaid, true},
+		{StatusShipped, true},
+		{StatusDelivered, true},
+		{StatusCancelled, true},
+		{StatusRefunded, true},
+		{"", false},
+		{"PAID", false},
+		{"unknown", false},
+	}
+	for _, tt := range tests {
+		if got := tt.status.IsValid(); got != tt.want {
+			t.Errorf("Status(%q).IsValid() = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+}
@@ -3,9 +3,17 @@ CREATE TABLE IF NOT EXISTS orders (
     created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
     account_id CHAR(36) NOT NULL,
-    total_price MONEY NOT NULL
+    total_price BIGINT NOT NULL,
+    currency CHAR(3) NOT NULL DEFAULT 'USD',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    idempotency_key VARCHAR(255),
+    request_hash CHAR(64) NOT NULL,
+    reservation_id VARCHAR(36) NOT NULL DEFAULT '',
+    UNIQUE (account_id, idempotency_key)
 );
 
+CREATE INDEX IF NOT EXISTS orders_account_id_created_at_idx ON orders (account_id, created_at, id);
+
 -- Create trigger function for auto-updating updated_at
 CREATE OR REPLACE FUNCTION update_updated_at_column()
 RETURNS TRIGGER AS $$
@@ -21,9 +29,65 @@ CREATE TRIGGER update_orders_updated_at
     FOR EACH ROW
     EXECUTE FUNCTION update_updated_at_column();
 
+-- Product details are copied at purchase time so order history does not
+-- change with the catalog. Prices, like order totals, are integers in the
+-- minor unit of their currency, e.g. cents. Prices converted from another
+-- currency keep the rate they were converted at.
 CREATE TABLE IF NOT EXISTS order_products (
     order_id CHAR(36) REFERENCES orders(id) ON DELETE CASCADE,
     product_id CHAR(36),
+    variant_id VARCHAR(36) NOT NULL DEFAULT '',
+    sku VARCHAR(255) NOT NULL DEFAULT '',
     quantity INT NOT NULL,
-    PRIMARY KEY (product_id, order_id)
-);
\ No newline at end of file
+    unit_price BIGINT NOT NULL,
+    currency CHAR(3) NOT NULL,
+    exchange_rate_from CHAR(3),
+    exchange_rate NUMERIC,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL,
+    PRIMARY KEY (product_id, variant_id, order_id)
+);
+
+CREATE TABLE IF NOT EXISTS order_status_history (
+    id BIGSERIAL PRIMARY KEY,
+    order_id CHAR(36) NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    from_status VARCHAR(20),
+    to_status VARCHAR(20) NOT NULL,
+    changed_by CHAR(36) NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS order_status_history_order_id_idx ON order_status_history (order_id);
+
+-- A saga places an order across the catalog and this database. Its progress
+-- is stored after every step, so that a saga interrupted by a crash can be
+-- finished or undone by the next runner once the lease of the last one ran
+-- out.
+CREATE TABLE IF NOT EXISTS order_sagas (
+    id VARCHAR(36) PRIMARY KEY,
+    status VARCHAR(20) NOT NULL,
+    step INT NOT NULL DEFAULT 0,
+    placement JSONB NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    lease_id VARCHAR(16) NOT NULL DEFAULT '',
+    lease_expires_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS order_sagas_unfinished_idx ON order_sagas (next_attempt_at)
+    WHERE status IN ('running', 'compensating');
+
+CREATE TABLE IF NOT EXISTS order_saga_log (
+    id BIGSERIAL PRIMARY KEY,
+    saga_id VARCHAR(36) NOT NULL REFERENCES order_sagas(id) ON DELETE CASCADE,
+    step VARCHAR(50) NOT NULL,
+    compensate BOOLEAN NOT NULL DEFAULT FALSE,
+    error TEXT NOT NULL DEFAULT '',
+    at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS order_saga_log_saga_id_idx ON order_saga_log (saga_id);
@@ -0,0 +1,65 @@
+package payment
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// SignatureHeader carries the signature of a provider callback as
+	// "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>">".
+	SignatureHeader = "Payment-Signature"
+
+	// signatureTolerance bounds how old a signed callback may be, so that
+	// captured requests cannot be replayed much later.
+	signatureTolerance = 5 * time.Minute
+)
+
+var ErrInvalidSignature = errors.New("invalid callback signature")
+
+// SignCallback returns the SignatureHeader value for a callback body sent
+// at the given time.
+func SignCallback(secret, body []byte, at time.Time) string {
+	t := strconv.FormatInt(at.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", t, signature(secret, t, body))
+}
+
+// VerifyCallback checks the SignatureHeader value of a callback body.
+func VerifyCallback(secret, body []byte, header string, now time.Time) error {
+	var t, v1 string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			t = value
+		case "v1":
+			v1 = value
+		}
+	}
+	unix, err := strconv.ParseInt(t, 10, 64)
+	if err != nil || v1 == "" {
+		return ErrInvalidSignature
+	}
+	if age := now.Sub(time.Unix(unix, 0)); age > signatureTolerance || age < -signatureTolerance {
+		return ErrInvalidSignature
+	}
+	expected := signature(secret, t, body)
+	if !hmac.Equal([]byte(v1), []byte(expected)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func signature(secret []byte, t string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(t))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
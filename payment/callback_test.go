@@ -0,0 +1,44 @@
+package payment
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestVerifyCallback(t *testing.T) {
+	secret := []byte("secret")
+	body := []byte(`{"id":"evt_1","operation":"capture","outcome":"succeeded"}`)
+	sentAt := time.Unix(1700000000, 0)
+	valid := SignCallback(secret, body, sentAt)
+
+	tests := []struct {
+		name   string
+		secret []byte
+		body   []byte
+		header string
+		now    time.Time
+		err    error
+	}{
+		{"valid", secret, body, valid, sentAt, nil},
+		{"received later", secret, body, valid, sentAt.Add(signatureTolerance), nil},
+		{"clock ahead of receiver", secret, body, valid, sentAt.Add(-signatureTolerance), nil},
+		{"parts in other order", secret, body, fmt.Sprintf("v1=%s, t=%d", signature(secret, "1700000000", body), sentAt.Unix()), sentAt, nil},
+		{"too old", secret, body, valid, sentAt.Add(signatureTolerance + time.Second), ErrInvalidSignature},
+		{"too far ahead", secret, body, valid, sentAt.Add(-signatureTolerance - time.Second), ErrInvalidSignature},
+		{"other secret", []byte("other"), body, valid, sentAt, ErrInvalidSignature},
+		{"changed body", secret, []byte(`{"id":"evt_1","operation":"capture","outcome":"failed"}`), valid, sentAt, ErrInvalidSignature},
+		{"changed time", secret, body, fmt.Sprintf("t=%d,v1=%s", sentAt.Unix()+1, signature(secret, "1700000000", body)), sentAt, ErrInvalidSignature},
+		{"missing signature", secret, body, fmt.Sprintf("t=%d", sentAt.Unix()), sentAt, ErrInvalidSignature},
+		{"missing time", secret, body, "v1=" + signature(secret, "1700000000", body), sentAt, ErrInvalidSignature},
+		{"malformed time", secret, body, "t=abc,v1=" + signature(secret, "abc", body), sentAt, ErrInvalidSignature},
+		{"empty", secret, body, "", sentAt, ErrInvalidSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := VerifyCallback(tt.secret, tt.body, tt.header, tt.now); err != tt.err {
+				t.Errorf("VerifyCallback() error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package payment
+
+import (
+	"context"
+
+	"github.com/suryanshp1/go-microservice/money"
+	"github.com/suryanshp1/go-microservice/payment/pb"
+	"google.golang.org/grpc"
+)
+
+type Client struct {
+	conn    *grpc.ClientConn
+	service pb.PaymentServiceClient
+}
+
+func NewClient(url string) (*Client, error) {
+	conn, err := grpc.Dial(url, grpc.WithInsecure())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		conn:    conn,
+		service: pb.NewPaymentServiceClient(conn),
+	}, nil
+}
+
+func (c *Client) Close() {
+	c.conn.Close()
+}
+
+// CreatePayment pays for an order with its total, capturing the payment
+// right away if capture is set.
+func (c *Client) CreatePayment(ctx context.Context, orderID, paymentMethod, idempotencyKey string, capture bool) (*Intent, error) {
+	r, err := c.service.CreatePayment(ctx, &pb.CreatePaymentRequest{
+		OrderId:        orderID,
+		PaymentMethod:  paymentMethod,
+		IdempotencyKey: idempotencyKey,
+		Capture:        capture,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return intentFromProto(r.Payment), nil
+}
+
+func (c *Client) GetPayment(ctx context.Context, id string) (*Intent, error) {
+	r, err := c.service.GetPayment(ctx, &pb.GetPaymentRequest{Id: id})
+	if err != nil {
+		return nil, err
+	}
+	return intentFromProto(r.Payment), nil
+}
+
+func (c *Client) GetPaymentsForOrder(ctx context.Context, orderID string) ([]Intent, error) {
+	r, err := c.service.GetPaymentsForOrder(ctx, &pb.GetPaymentsForOrderRequest{OrderId: orderID})
+	if err != nil {
+		return nil, err
+	}
+	intents := []Intent{}
+	for _, p := range r.Payments {
+		intents = append(intents, *intentFromProto(p))
+	}
+	return intents, nil
+}
+
+func (c *Client) CapturePayment(ctx context.Context, id string) (*Intent, error) {
+	r, err := c.service.CapturePayment(ctx, &pb.CapturePaymentRequest{Id: id})
+	if err != nil {
+		return nil, err
+	}
+	return intentFromProto(r.Payment), nil
+}
+
+func (c *Client) VoidPayment(ctx context.Context, id string) (*Intent, error) {
+	r, err := c.service.VoidPayment(ctx, &pb.VoidPaymentRequest{Id: id})
+	if err != nil {
+		return nil, err
+	}
+	return intentFromProto(r.Payment), nil
+}
+
+func (c *Client) RefundPayment(ctx context.Context, id string) (*Intent, error) {
+	r, err := c.service.RefundPayment(ctx, &pb.RefundPaymentRequest{Id: id})
+	if err != nil {
+		return nil, err
+	}
+	return intentFromProto(r.Payment), nil
+}
+
+func intentFromProto(p *pb.Payment) *Intent {
+	i := &Intent{
+		ID:            p.Id,
+		OrderID:       p.OrderId,
+		AccountID:     p.AccountId,
+		Amount:        moneyFromProto(p.Amount),
+		Provider:      p.Provider,
+		Reference:     p.Reference,
+		PaymentMethod: p.PaymentMethod,
+		Status:        Status(p.Status),
+		FailureReason: p.FailureReason,
+		StatusHistory: []StatusChange{},
+	}
+	i.CreatedAt.UnmarshalBinary(p.CreatedAt)
+	i.UpdatedAt.UnmarshalBinary(p.UpdatedAt)
+	for _, cp := range p.StatusHistory {
+		c := StatusChange{
+			From:   Status(cp.From),
+			To:     Status(cp.To),
+			Reason: cp.Reason,
+		}
+		c.ChangedAt.UnmarshalBinary(cp.ChangedAt)
+		i.StatusHistory = append(i.StatusHistory, c)
+	}
+	return i
+}
+
+func moneyFromProto(pm *pb.Money) money.Money {
+	if pm == nil {
+		return money.Money{}
+	}
+	return money.New(pm.Amount, pm.Currency)
+}
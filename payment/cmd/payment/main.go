@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/suryanshp1/go-microservice/account"
+	"github.com/suryanshp1/go-microservice/order"
+	"github.com/suryanshp1/go-microservice/payment"
+	"github.com/tinrab/retry"
+)
+
+type Config struct {
+	DatabaseURL     string `envconfig:"DATABASE_URL"`
+	AccountURL      string `envconfig:"ACCOUNT_SERVICE_URL"`
+	OrderURL        string `envconfig:"ORDER_SERVICE_URL"`
+	PublicKey       string `envconfig:"TOKEN_PUBLIC_KEY"`
+	ServiceEmail    string `envconfig:"SERVICE_ACCOUNT_EMAIL"`
+	ServicePassword string `envconfig:"SERVICE_ACCOUNT_PASSWORD"`
+	CallbackSecret  string `envconfig:"CALLBACK_SECRET"`
+	CallbackURL     string `envconfig:"CALLBACK_URL"`
+}
+
+func main() {
+	var cfg Config
+	err := envconfig.Process("", &cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if cfg.CallbackSecret == "" {
+		log.Fatal("CALLBACK_SECRET is required")
+	}
+
+	publicKey, err := account.ParsePublicKey(cfg.PublicKey)
+	if err != nil {
+		log.Fatalf("invalid TOKEN_PUBLIC_KEY: %v", err)
+	}
+
+	var r payment.Repository
+	retry.ForeverSleep(2*time.Second, func(_ int) (err error) {
+		r, err = payment.NewPostgresRepository(cfg.DatabaseURL)
+		if err != nil {
+			log.Printf("failed to connect to database: %v", err)
+		}
+		return
+	})
+	defer r.Close()
+
+	accountClient, err := account.NewClient(cfg.AccountURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer accountClient.Close()
+
+	orderClient, err := order.NewClient(cfg.OrderURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer orderClient.Close()
+
+	secret := []byte(cfg.CallbackSecret)
+	provider := payment.NewFakeProvider(cfg.CallbackURL, secret)
+	s := payment.NewService(r, provider)
+	session := account.NewServiceSession(accountClient, cfg.ServiceEmail, cfg.ServicePassword)
+	sync := payment.NewOrderSync(s, orderClient, session)
+
+	go func() {
+		mux := http.NewServeMux()
+		mux.Handle("/callbacks/"+provider.Name(), payment.NewCallbackHandler(s, sync, secret))
+		log.Println("Listening for callbacks on port 8081......")
+		log.Fatal(http.ListenAndServe(":8081", mux))
+	}()
+
+	// Catch up on orders that could not follow their payments right away.
+	go func() {
+		for range time.Tick(time.Minute) {
+			n, err := sync.SyncPending(context.Background())
+			if err != nil {
+				log.Println("Error syncing orders:", err)
+			}
+			if n > 0 {
+				log.Printf("Synced %d orders", n)
+			}
+		}
+	}()
+
+	log.Println("Listening on port 8080......")
+	log.Fatal(payment.ListenGRPC(s, accountClient, orderClient, sync, account.NewTokenVerifier(publicKey), 8080))
+}
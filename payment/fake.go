@@ -0,0 +1,172 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/suryanshp1/go-microservice/money"
+)
+
+// Payment methods understood by FakeProvider. Each one decides how all
+// operations on a payment made with it turn out.
+const (
+	// FakeMethodOK succeeds with every operation. It is also used for an
+	// empty payment method.
+	FakeMethodOK = "fake_ok"
+	// FakeMethodDeclined fails authorization with "card_declined".
+	FakeMethodDeclined = "fake_declined"
+	// FakeMethodCaptureDeclined authorizes, but fails captures with
+	// "capture_declined".
+	FakeMethodCaptureDeclined = "fake_capture_declined"
+	// FakeMethodAsync leaves every operation pending and reports its
+	// success through a callback.
+	FakeMethodAsync = "fake_async"
+)
+
+const fakeCallbackAttempts = 5
+
+// FakeProvider is an in-process provider for local use and tests. It moves
+// no money and keeps no state: outcomes only depend on the payment method,
+// which is encoded into the reference, so the same requests always get the
+// same answers, across restarts too.
+//
+// Pending operations are completed with a callback to callbackURL, signed
+// with secret. Without a callback URL they stay pending.
+type FakeProvider struct {
+	callbackURL string
+	secret      []byte
+	client      *http.Client
+}
+
+func NewFakeProvider(callbackURL string, secret []byte) *FakeProvider {
+	return &FakeProvider{
+		callbackURL: callbackURL,
+		secret:      secret,
+		client:      &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+func (p *FakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *FakeProvider) Authorize(ctx context.Context, r AuthorizeRequest) (Result, error) {
+	method := r.PaymentMethod
+	if method == "" {
+		method = FakeMethodOK
+	}
+	if !r.Amount.Valid() || r.Amount.Amount <= 0 {
+		return Result{Outcome: OutcomeFailed, FailureReason: "invalid_amount"}, nil
+	}
+	switch method {
+	case FakeMethodOK, FakeMethodCaptureDeclined:
+	case FakeMethodDeclined:
+		return Result{Outcome: OutcomeFailed, FailureReason: "card_declined"}, nil
+	case FakeMethodAsync:
+	default:
+		return Result{Outcome: OutcomeFailed, FailureReason: "unsupported_payment_method"}, nil
+	}
+
+	sum := sha256.Sum256([]byte(r.IntentID))
+	reference := method + "." + hex.EncodeToString(sum[:12])
+	return p.result(reference, OperationAuthorize), nil
+}
+
+func (p *FakeProvider) Capture(ctx context.Context, reference string, amount money.Money) (Result, error) {
+	method, err := fakeMethod(reference)
+	if err != nil {
+		return Result{}, err
+	}
+	if method == FakeMethodCaptureDeclined {
+		return Result{Reference: reference, Outcome: OutcomeFailed, FailureReason: "capture_declined"}, nil
+	}
+	return p.result(reference, OperationCapture), nil
+}
+
+func (p *FakeProvider) Void(ctx context.Context, reference string) (Result, error) {
+	if _, err := fakeMethod(reference); err != nil {
+		return Result{}, err
+	}
+	return p.result(reference, OperationVoid), nil
+}
+
+func (p *FakeProvider) Refund(ctx context.Context, reference string, amount money.Money) (Result, error) {
+	if _, err := fakeMethod(reference); err != nil {
+		return Result{}, err
+	}
+	return p.result(reference, OperationRefund), nil
+}
+
+// result succeeds right away, or for asynchronous payment methods reports
+// the operation as pending and sends its success as a callback.
+func (p *FakeProvider) result(reference string, op Operation) Result {
+	if !strings.HasPrefix(reference, FakeMethodAsync+".") {
+		return Result{Reference: reference, Outcome: OutcomeSucceeded}
+	}
+	if p.callbackURL != "" {
+		sum := sha256.Sum256([]byte(reference + "/" + string(op)))
+		go p.send(Event{
+			ID:        "evt_" + hex.EncodeToString(sum[:12]),
+			Operation: op,
+			Reference: reference,
+			Outcome:   OutcomeSucceeded,
+			CreatedAt: time.Now().UTC(),
+		})
+	}
+	return Result{Reference: reference, Outcome: OutcomePending}
+}
+
+// send delivers a callback, retrying with a growing delay while it is
+// refused. The first attempt is delayed too, giving the payment service time
+// to record that the operation is pending.
+func (p *FakeProvider) send(e Event) {
+	body, err := json.Marshal(e)
+	if err != nil {
+		log.Printf("fake provider: failed to encode event %s: %v", e.ID, err)
+		return
+	}
+	delay := 500 * time.Millisecond
+	for attempt := 1; attempt <= fakeCallbackAttempts; attempt++ {
+		time.Sleep(delay)
+		delay *= 2
+
+		req, err := http.NewRequest(http.MethodPost, p.callbackURL, bytes.NewReader(body))
+		if err != nil {
+			log.Printf("fake provider: invalid callback URL: %v", err)
+			return
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(SignatureHeader, SignCallback(p.secret, body, time.Now()))
+		res, err := p.client.Do(req)
+		if err != nil {
+			log.Printf("fake provider: callback %s failed: %v", e.ID, err)
+			continue
+		}
+		res.Body.Close()
+		if res.StatusCode < 300 {
+			return
+		}
+		log.Printf("fake provider: callback %s refused with %s", e.ID, res.Status)
+	}
+}
+
+// fakeMethod returns the payment method a reference of FakeProvider was
+// issued for.
+func fakeMethod(reference string) (string, error) {
+	method, _, ok := strings.Cut(reference, ".")
+	if !ok {
+		return "", ErrUnknownReference
+	}
+	switch method {
+	case FakeMethodOK, FakeMethodCaptureDeclined, FakeMethodAsync:
+		return method, nil
+	}
+	return "", ErrUnknownReference
+}
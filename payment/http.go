@@ -0,0 +1,62 @@
+package payment
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+// maxCallbackSize bounds the body of a provider callback.
+const maxCallbackSize = 64 << 10
+
+// NewCallbackHandler returns the HTTP handler for the callbacks of the
+// provider of s. Callbacks must be signed with secret. Providers retry
+// callbacks answered with an error status, which is how events that arrive
+// too early or during an outage get applied eventually.
+func NewCallbackHandler(s Service, sync *OrderSync, secret []byte) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxCallbackSize))
+		if err != nil {
+			http.Error(w, "failed to read body", http.StatusBadRequest)
+			return
+		}
+		if err := VerifyCallback(secret, body, r.Header.Get(SignatureHeader), time.Now()); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		var e Event
+		if err := json.Unmarshal(body, &e); err != nil || e.ID == "" || e.Reference == "" {
+			http.Error(w, "invalid event", http.StatusBadRequest)
+			return
+		}
+
+		i, err := s.HandleEvent(r.Context(), e)
+		switch err {
+		case nil:
+		case ErrIntentNotFound:
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		case ErrInvalidTransition, ErrStatusConflict:
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		default:
+			log.Printf("failed to handle event %s: %v", e.ID, err)
+			http.Error(w, "failed to handle event", http.StatusInternalServerError)
+			return
+		}
+
+		// Orders that cannot be synced now are synced in the background.
+		if err := sync.Sync(r.Context(), i); err != nil {
+			log.Printf("failed to sync order %s of payment %s: %v", i.OrderID, i.ID, err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,105 @@
+package payment
+
+import (
+	"errors"
+	"time"
+
+	"github.com/suryanshp1/go-microservice/money"
+)
+
+type Status string
+
+const (
+	// StatusPending waits for the provider to decide on the authorization.
+	StatusPending    Status = "pending"
+	StatusAuthorized Status = "authorized"
+	StatusDeclined   Status = "declined"
+	// StatusCapturing, StatusVoiding and StatusRefunding wait for the
+	// provider to complete the operation.
+	StatusCapturing Status = "capturing"
+	StatusCaptured  Status = "captured"
+	StatusVoiding   Status = "voiding"
+	StatusVoided    Status = "voided"
+	StatusRefunding Status = "refunding"
+	StatusRefunded  Status = "refunded"
+)
+
+var (
+	ErrIntentNotFound    = errors.New("payment not found")
+	ErrInvalidTransition = errors.New("payment cannot do this in its current status")
+	ErrStatusConflict    = errors.New("payment status was changed concurrently")
+)
+
+// transitions lists the statuses a payment may move to from each status.
+// Every operation first moves the payment to the status waiting for it,
+// before the provider is asked, so that operations cannot overlap.
+// Operations the provider fails move the payment back to where it
+// was. Declined, voided and refunded payments are final.
+var transitions = map[Status][]Status{
+	StatusPending:    {StatusAuthorized, StatusDeclined},
+	StatusAuthorized: {StatusCapturing, StatusVoiding},
+	StatusCapturing:  {StatusCaptured, StatusAuthorized},
+	StatusVoiding:    {StatusVoided, StatusAuthorized},
+	StatusCaptured:   {StatusRefunding},
+	StatusRefunding:  {StatusRefunded, StatusCaptured},
+}
+
+func (s Status) CanTransitionTo(to Status) bool {
+	for _, next := range transitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// outcomes gives the status a payment moves to after each outcome of an
+// operation.
+var outcomes = map[Operation]map[Outcome]Status{
+	OperationAuthorize: {OutcomeSucceeded: StatusAuthorized, OutcomePending: StatusPending, OutcomeFailed: StatusDeclined},
+	OperationCapture:   {OutcomeSucceeded: StatusCaptured, OutcomePending: StatusCapturing, OutcomeFailed: StatusAuthorized},
+	OperationVoid:      {OutcomeSucceeded: StatusVoided, OutcomePending: StatusVoiding, OutcomeFailed: StatusAuthorized},
+	OperationRefund:    {OutcomeSucceeded: StatusRefunded, OutcomePending: StatusRefunding, OutcomeFailed: StatusCaptured},
+}
+
+// pendingStatus gives the status that waits for the outcome of each
+// operation.
+var pendingStatus = map[Operation]Status{
+	OperationAuthorize: StatusPending,
+	OperationCapture:   StatusCapturing,
+	OperationVoid:      StatusVoiding,
+	OperationRefund:    StatusRefunding,
+}
+
+// Intent is a payment for an order, from authorization to capture and
+// possibly refund. Amount is the order total at the time the payment was
+// made. Reference is the provider's identifier of the payment.
+// OrderSynced is false while the order has not been moved along with a
+// captured or refunded payment.
+type Intent struct {
+	ID             string
+	OrderID        string
+	AccountID      string
+	Amount         money.Money
+	Provider       string
+	Reference      string
+	PaymentMethod  string
+	Status         Status
+	FailureReason  string
+	IdempotencyKey string
+	OrderSynced    bool
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	StatusHistory  []StatusChange
+}
+
+// StatusChange records a single status transition of a payment. From is
+// empty for the change that created it. EventID is set for changes made by
+// provider callbacks.
+type StatusChange struct {
+	From      Status
+	To        Status
+	Reason    string
+	EventID   string
+	ChangedAt time.Time
+}
@@ -0,0 +1,86 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/suryanshp1/go-microservice/account"
+	"github.com/suryanshp1/go-microservice/order"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// OrderSync moves orders along with their payments: to paid once a payment
+// is captured and to refunded once it is refunded. It calls the order
+// service as the payment service's own account, which needs
+// order:read:any and order:write:any, because payments also change on
+// provider callbacks that carry no caller.
+type OrderSync struct {
+	service     Service
+	orderClient *order.Client
+	session     *account.ServiceSession
+}
+
+func NewOrderSync(s Service, orderClient *order.Client, session *account.ServiceSession) *OrderSync {
+	return &OrderSync{s, orderClient, session}
+}
+
+// Sync moves the order of i along, if it has not been yet. Orders that
+// cannot follow, e.g. because they were cancelled in the meantime, are
+// logged and left as they are.
+func (o *OrderSync) Sync(ctx context.Context, i *Intent) error {
+	if i.OrderSynced {
+		return nil
+	}
+	var to order.Status
+	switch i.Status {
+	case StatusCaptured:
+		to = order.StatusPaid
+	case StatusRefunded:
+		to = order.StatusRefunded
+	default:
+		return o.service.MarkOrderSynced(ctx, i)
+	}
+
+	ctx, err := o.session.Context(context.WithoutCancel(ctx))
+	if err != nil {
+		return fmt.Errorf("sign in to update order %s: %w", i.OrderID, err)
+	}
+	current, err := o.orderClient.GetOrder(ctx, i.OrderID)
+	if err != nil {
+		return err
+	}
+	if current.Status != to {
+		reason := fmt.Sprintf("payment %s %s", i.ID, i.Status)
+		_, err = o.orderClient.UpdateOrderStatus(ctx, i.OrderID, to, reason)
+		if status.Code(err) == codes.FailedPrecondition {
+			log.Printf("order %s cannot move from %s to %s after payment %s was %s",
+				strings.TrimSpace(i.OrderID), current.Status, to, i.ID, i.Status)
+			err = nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return o.service.MarkOrderSynced(ctx, i)
+}
+
+// SyncPending moves along the orders of payments that could not be synced
+// when they changed, e.g. because the order service was unavailable. It
+// returns how many were synced.
+func (o *OrderSync) SyncPending(ctx context.Context) (int, error) {
+	intents, err := o.service.GetUnsyncedIntents(ctx, 100)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for i := range intents {
+		if err := o.Sync(ctx, &intents[i]); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
@@ -0,0 +1,77 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/suryanshp1/go-microservice/money"
+)
+
+var (
+	ErrUnknownReference = errors.New("provider does not know the payment")
+	ErrInvalidOperation = errors.New("provider cannot perform the operation in the payment's state")
+)
+
+// Provider moves money through a payment processor. Operations that the
+// processor decides on right away return their outcome; those that take
+// longer return OutcomePending and report their outcome later through a
+// signed callback carrying an Event. Errors mean that the outcome is not
+// known, not that the operation failed.
+type Provider interface {
+	Name() string
+	// Authorize reserves r.Amount on the payment method. The returned
+	// reference identifies the authorization in later operations.
+	Authorize(ctx context.Context, r AuthorizeRequest) (Result, error)
+	// Capture charges an authorized payment.
+	Capture(ctx context.Context, reference string, amount money.Money) (Result, error)
+	// Void releases an authorization that was not captured.
+	Void(ctx context.Context, reference string) (Result, error)
+	// Refund pays a captured amount back.
+	Refund(ctx context.Context, reference string, amount money.Money) (Result, error)
+}
+
+// AuthorizeRequest asks a provider to authorize a payment. IntentID is
+// unique per payment and lets providers recognize retried requests.
+type AuthorizeRequest struct {
+	IntentID      string
+	Amount        money.Money
+	PaymentMethod string
+}
+
+type Operation string
+
+const (
+	OperationAuthorize Operation = "authorize"
+	OperationCapture   Operation = "capture"
+	OperationVoid      Operation = "void"
+	OperationRefund    Operation = "refund"
+)
+
+type Outcome string
+
+const (
+	OutcomeSucceeded Outcome = "succeeded"
+	OutcomePending   Outcome = "pending"
+	OutcomeFailed    Outcome = "failed"
+)
+
+// Result is what a provider answered to an operation. Reference is set by
+// Authorize; FailureReason is set for failed operations.
+type Result struct {
+	Reference     string
+	Outcome       Outcome
+	FailureReason string
+}
+
+// Event is a provider callback reporting the outcome of an operation that
+// was pending. Providers may deliver an event more than once; ID tells
+// repeated deliveries apart from new events.
+type Event struct {
+	ID            string    `json:"id"`
+	Operation     Operation `json:"operation"`
+	Reference     string    `json:"reference"`
+	Outcome       Outcome   `json:"outcome"`
+	FailureReason string    `json:"failure_reason,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
@@ -0,0 +1,311 @@
+package payment
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+	"github.com/suryanshp1/go-microservice/money"
+)
+
+type Repository interface {
+	Close()
+	PutIntent(ctx context.Context, i Intent) error
+	GetIntent(ctx context.Context, id string) (*Intent, error)
+	GetIntentByIdempotencyKey(ctx context.Context, orderID, idempotencyKey string) (*Intent, error)
+	GetIntentByReference(ctx context.Context, provider, reference string) (*Intent, error)
+	GetIntentsForOrder(ctx context.Context, orderID string) ([]Intent, error)
+	PutIntentReference(ctx context.Context, id, reference string) error
+	PutIntentStatus(ctx context.Context, id string, change StatusChange, reference, failureReason string, orderSynced bool) error
+	GetUnsyncedIntents(ctx context.Context, limit int) ([]Intent, error)
+	PutOrderSynced(ctx context.Context, id string, status Status) error
+}
+
+var (
+	ErrDuplicateIdempotencyKey = errors.New("duplicate idempotency key")
+
+	errDuplicateEvent = errors.New("duplicate provider event")
+)
+
+const intentColumns = `id, order_id, account_id, amount, currency, provider, reference, payment_method,
+    status, failure_reason, COALESCE(idempotency_key, ''), order_synced, created_at, updated_at`
+
+type postgresRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresRepository(url string) (Repository, error) {
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		return nil, err
+	}
+	err = db.Ping()
+	if err != nil {
+		return nil, err
+	}
+	return &postgresRepository{db}, nil
+}
+
+func (r *postgresRepository) Close() {
+	r.db.Close()
+}
+
+func (r *postgresRepository) PutIntent(ctx context.Context, i Intent) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	_, err = tx.ExecContext(
+		ctx,
+		`INSERT INTO payment_intents(id, order_id, account_id, amount, currency, provider, reference, payment_method,
+      status, failure_reason, idempotency_key, order_synced, created_at, updated_at)
+    VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NULLIF($11, ''), $12, $13, $14)`,
+		i.ID,
+		i.OrderID,
+		i.AccountID,
+		i.Amount.Amount,
+		i.Amount.Currency,
+		i.Provider,
+		i.Reference,
+		i.PaymentMethod,
+		i.Status,
+		i.FailureReason,
+		i.IdempotencyKey,
+		i.OrderSynced,
+		i.CreatedAt,
+		i.UpdatedAt,
+	)
+	if pqErr, ok := err.(*pq.Error); ok {
+		switch pqErr.Constraint {
+		case "payment_intents_order_id_idempotency_key_key":
+			err = ErrDuplicateIdempotencyKey
+		case "payment_intents_active_order_idx":
+			err = ErrPaymentExists
+		}
+	}
+	if err != nil {
+		return
+	}
+
+	for _, change := range i.StatusHistory {
+		if err = insertStatusChange(ctx, tx, i.ID, change); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (r *postgresRepository) GetIntent(ctx context.Context, id string) (*Intent, error) {
+	return r.getIntent(ctx, "id = $1", id)
+}
+
+func (r *postgresRepository) GetIntentByIdempotencyKey(ctx context.Context, orderID, idempotencyKey string) (*Intent, error) {
+	return r.getIntent(ctx, "order_id = $1 AND idempotency_key = $2", orderID, idempotencyKey)
+}
+
+func (r *postgresRepository) GetIntentByReference(ctx context.Context, provider, reference string) (*Intent, error) {
+	if reference == "" {
+		return nil, ErrIntentNotFound
+	}
+	return r.getIntent(ctx, "provider = $1 AND reference = $2", provider, reference)
+}
+
+func (r *postgresRepository) getIntent(ctx context.Context, where string, args ...interface{}) (*Intent, error) {
+	intents, err := r.queryIntents(ctx, `SELECT `+intentColumns+` FROM payment_intents WHERE `+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	if len(intents) == 0 {
+		return nil, ErrIntentNotFound
+	}
+	return &intents[0], nil
+}
+
+func (r *postgresRepository) GetIntentsForOrder(ctx context.Context, orderID string) ([]Intent, error) {
+	return r.queryIntents(
+		ctx,
+		`SELECT `+intentColumns+` FROM payment_intents WHERE order_id = $1 ORDER BY created_at, id`,
+		orderID,
+	)
+}
+
+func (r *postgresRepository) GetUnsyncedIntents(ctx context.Context, limit int) ([]Intent, error) {
+	return r.queryIntents(
+		ctx,
+		`SELECT `+intentColumns+` FROM payment_intents WHERE NOT order_synced ORDER BY updated_at, id LIMIT $1`,
+		limit,
+	)
+}
+
+// queryIntents reads the payments a query selects, with their status
+// history.
+func (r *postgresRepository) queryIntents(ctx context.Context, query string, args ...interface{}) ([]Intent, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	intents := []Intent{}
+	for rows.Next() {
+		var i Intent
+		var amount int64
+		var currency string
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrderID,
+			&i.AccountID,
+			&amount,
+			&currency,
+			&i.Provider,
+			&i.Reference,
+			&i.PaymentMethod,
+			&i.Status,
+			&i.FailureReason,
+			&i.IdempotencyKey,
+			&i.OrderSynced,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		i.Amount = money.New(amount, currency)
+		intents = append(intents, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for n := range intents {
+		if intents[n].StatusHistory, err = r.loadStatusHistory(ctx, intents[n].ID); err != nil {
+			return nil, err
+		}
+	}
+	return intents, nil
+}
+
+func (r *postgresRepository) loadStatusHistory(ctx context.Context, intentID string) ([]StatusChange, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT COALESCE(from_status, ''), to_status, reason, COALESCE(event_id, ''), changed_at
+    FROM payment_status_history
+    WHERE intent_id = $1
+    ORDER BY changed_at, id`,
+		intentID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []StatusChange{}
+	for rows.Next() {
+		var c StatusChange
+		if err := rows.Scan(&c.From, &c.To, &c.Reason, &c.EventID, &c.ChangedAt); err != nil {
+			return nil, err
+		}
+		history = append(history, c)
+	}
+	return history, rows.Err()
+}
+
+// PutIntentReference records the provider's reference of a payment whose
+// authorization is still pending.
+func (r *postgresRepository) PutIntentReference(ctx context.Context, id, reference string) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		"UPDATE payment_intents SET reference = $2, updated_at = now() WHERE id = $1",
+		id,
+		reference,
+	)
+	return err
+}
+
+// PutIntentStatus moves a payment from change.From to change.To. It fails
+// with ErrStatusConflict if the payment is no longer in change.From.
+func (r *postgresRepository) PutIntentStatus(
+	ctx context.Context,
+	id string,
+	change StatusChange,
+	reference, failureReason string,
+	orderSynced bool,
+) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	res, err := tx.ExecContext(
+		ctx,
+		`UPDATE payment_intents
+    SET status = $1, reference = $2, failure_reason = $3, order_synced = $4, updated_at = $5
+    WHERE id = $6 AND status = $7`,
+		change.To,
+		reference,
+		failureReason,
+		orderSynced,
+		change.ChangedAt,
+		id,
+		change.From,
+	)
+	if err != nil {
+		return
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return
+	}
+	if n == 0 {
+		err = ErrStatusConflict
+		return
+	}
+
+	err = insertStatusChange(ctx, tx, id, change)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Constraint == "payment_status_history_intent_id_event_id_key" {
+		err = errDuplicateEvent
+	}
+	return
+}
+
+// PutOrderSynced records that the order of a payment was moved along with
+// status, unless the payment has moved on since.
+func (r *postgresRepository) PutOrderSynced(ctx context.Context, id string, status Status) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		"UPDATE payment_intents SET order_synced = TRUE WHERE id = $1 AND status = $2",
+		id,
+		status,
+	)
+	return err
+}
+
+func insertStatusChange(ctx context.Context, tx *sql.Tx, intentID string, change StatusChange) error {
+	_, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO payment_status_history(intent_id, from_status, to_status, reason, event_id, changed_at)
+    VALUES($1, NULLIF($2, ''), $3, $4, NULLIF($5, ''), $6)`,
+		intentID,
+		change.From,
+		change.To,
+		change.Reason,
+		change.EventID,
+		change.ChangedAt,
+	)
+	return err
+}
@@ -0,0 +1,237 @@
+// protoc --go_out=./ --go-grpc_out=./ payment.proto
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+
+	"github.com/suryanshp1/go-microservice/account"
+	"github.com/suryanshp1/go-microservice/money"
+	"github.com/suryanshp1/go-microservice/order"
+	"github.com/suryanshp1/go-microservice/payment/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+type grpcServer struct {
+	pb.UnimplementedPaymentServiceServer
+	service       Service
+	accountClient *account.Client
+	orderClient   *order.Client
+	sync          *OrderSync
+}
+
+// ListenGRPC serves the payment API. Unlike the other services it takes its
+// clients rather than their URLs, because the callback handler and the
+// background order sync share them.
+func ListenGRPC(
+	s Service,
+	accountClient *account.Client,
+	orderClient *order.Client,
+	sync *OrderSync,
+	verifier *account.TokenVerifier,
+	port int,
+) error {
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return err
+	}
+
+	serv := grpc.NewServer(grpc.UnaryInterceptor(account.UnaryServerInterceptor(verifier)))
+	pb.RegisterPaymentServiceServer(serv, &grpcServer{
+		pb.UnimplementedPaymentServiceServer{},
+		s,
+		accountClient,
+		orderClient,
+		sync,
+	})
+	reflection.Register(serv)
+
+	return serv.Serve(lis)
+}
+
+// CreatePayment pays for an order with its current total. Customers pay for
+// their own orders; support can pay for any order.
+func (s *grpcServer) CreatePayment(ctx context.Context, r *pb.CreatePaymentRequest) (*pb.CreatePaymentResponse, error) {
+	claims := account.ClaimsFromContext(ctx)
+	if claims == nil {
+		return nil, status.Error(codes.Unauthenticated, "authentication required")
+	}
+
+	// The order service only shows the caller orders they may read.
+	o, err := s.orderClient.GetOrder(ctx, r.OrderId)
+	if err != nil {
+		return nil, err
+	}
+	accountID := strings.TrimSpace(o.AccountID)
+	if accountID != claims.AccountID() {
+		if err := s.accountClient.Authorize(ctx, account.PermissionOrderWriteAny); err != nil {
+			return nil, err
+		}
+	}
+	if !o.Status.CanTransitionTo(order.StatusPaid) {
+		return nil, status.Errorf(codes.FailedPrecondition, "order is %s and cannot be paid for", o.Status)
+	}
+
+	i, err := s.service.CreateIntent(ctx, o.ID, accountID, o.TotalPrice, r.PaymentMethod, r.IdempotencyKey, r.Capture)
+	if err != nil {
+		log.Println(err)
+		return nil, toStatus(err)
+	}
+	s.syncOrder(ctx, i)
+	return &pb.CreatePaymentResponse{Payment: intentToProto(i)}, nil
+}
+
+// GetPayment returns a payment to the account that made it and to support.
+// Others get the same answer as for a missing payment.
+func (s *grpcServer) GetPayment(ctx context.Context, r *pb.GetPaymentRequest) (*pb.GetPaymentResponse, error) {
+	claims := account.ClaimsFromContext(ctx)
+	if claims == nil {
+		return nil, status.Error(codes.Unauthenticated, "authentication required")
+	}
+
+	i, err := s.service.GetIntent(ctx, r.Id)
+	if err != nil {
+		log.Println(err)
+		return nil, toStatus(err)
+	}
+	if i.AccountID != claims.AccountID() {
+		allowed, err := s.accountClient.CheckPermission(ctx, claims.AccountID(), account.PermissionOrderReadAny)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, toStatus(ErrIntentNotFound)
+		}
+	}
+	return &pb.GetPaymentResponse{Payment: intentToProto(i)}, nil
+}
+
+// GetPaymentsForOrder returns the payments of an order to whoever may read
+// the order.
+func (s *grpcServer) GetPaymentsForOrder(
+	ctx context.Context,
+	r *pb.GetPaymentsForOrderRequest,
+) (*pb.GetPaymentsForOrderResponse, error) {
+	if account.ClaimsFromContext(ctx) == nil {
+		return nil, status.Error(codes.Unauthenticated, "authentication required")
+	}
+	o, err := s.orderClient.GetOrder(ctx, r.OrderId)
+	if err != nil {
+		return nil, err
+	}
+
+	intents, err := s.service.GetIntentsForOrder(ctx, o.ID)
+	if err != nil {
+		log.Println(err)
+		return nil, toStatus(err)
+	}
+	payments := []*pb.Payment{}
+	for n := range intents {
+		payments = append(payments, intentToProto(&intents[n]))
+	}
+	return &pb.GetPaymentsForOrderResponse{Payments: payments}, nil
+}
+
+func (s *grpcServer) CapturePayment(ctx context.Context, r *pb.CapturePaymentRequest) (*pb.CapturePaymentResponse, error) {
+	i, err := s.operate(ctx, r.Id, s.service.Capture)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.CapturePaymentResponse{Payment: intentToProto(i)}, nil
+}
+
+func (s *grpcServer) VoidPayment(ctx context.Context, r *pb.VoidPaymentRequest) (*pb.VoidPaymentResponse, error) {
+	i, err := s.operate(ctx, r.Id, s.service.Void)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.VoidPaymentResponse{Payment: intentToProto(i)}, nil
+}
+
+func (s *grpcServer) RefundPayment(ctx context.Context, r *pb.RefundPaymentRequest) (*pb.RefundPaymentResponse, error) {
+	i, err := s.operate(ctx, r.Id, s.service.Refund)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.RefundPaymentResponse{Payment: intentToProto(i)}, nil
+}
+
+// operate runs an operation on an existing payment, which only support may
+// do.
+func (s *grpcServer) operate(ctx context.Context, id string, op func(context.Context, string) (*Intent, error)) (*Intent, error) {
+	if err := s.accountClient.Authorize(ctx, account.PermissionOrderWriteAny); err != nil {
+		return nil, err
+	}
+	i, err := op(ctx, id)
+	if err != nil {
+		log.Println(err)
+		return nil, toStatus(err)
+	}
+	s.syncOrder(ctx, i)
+	return i, nil
+}
+
+// syncOrder moves the order along with a payment. Orders that cannot be
+// synced now are synced in the background, so the payment is answered
+// either way.
+func (s *grpcServer) syncOrder(ctx context.Context, i *Intent) {
+	if err := s.sync.Sync(ctx, i); err != nil {
+		log.Printf("failed to sync order %s of payment %s: %v", i.OrderID, i.ID, err)
+	}
+}
+
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, ErrIntentNotFound):
+		return status.Error(codes.NotFound, ErrIntentNotFound.Error())
+	case errors.Is(err, ErrInvalidAmount):
+		return status.Error(codes.InvalidArgument, ErrInvalidAmount.Error())
+	case errors.Is(err, ErrInvalidTransition), errors.Is(err, ErrPaymentExists):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, ErrIdempotencyKeyReused):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, ErrStatusConflict):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, ErrUnknownReference), errors.Is(err, ErrInvalidOperation):
+		return status.Error(codes.Internal, err.Error())
+	}
+	return err
+}
+
+func intentToProto(i *Intent) *pb.Payment {
+	p := &pb.Payment{
+		Id:            i.ID,
+		OrderId:       i.OrderID,
+		AccountId:     i.AccountID,
+		Amount:        moneyToProto(i.Amount),
+		Provider:      i.Provider,
+		Reference:     i.Reference,
+		PaymentMethod: i.PaymentMethod,
+		Status:        string(i.Status),
+		FailureReason: i.FailureReason,
+		StatusHistory: []*pb.StatusChange{},
+	}
+	p.CreatedAt, _ = i.CreatedAt.MarshalBinary()
+	p.UpdatedAt, _ = i.UpdatedAt.MarshalBinary()
+	for _, c := range i.StatusHistory {
+		cp := &pb.StatusChange{
+			From:   string(c.From),
+			To:     string(c.To),
+			Reason: c.Reason,
+		}
+		cp.ChangedAt, _ = c.ChangedAt.MarshalBinary()
+		p.StatusHistory = append(p.StatusHistory, cp)
+	}
+	return p
+}
+
+func moneyToProto(m money.Money) *pb.Money {
+	return &pb.Money{Amount: m.Amount, Currency: m.Currency}
+}
@@ -0,0 +1,276 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/ksuid"
+	"github.com/suryanshp1/go-microservice/money"
+)
+
+var (
+	ErrInvalidAmount        = errors.New("payment amount must be positive")
+	ErrPaymentExists        = errors.New("order already has a payment that was not declined or voided")
+	ErrIdempotencyKeyReused = errors.New("idempotency key was already used with a different payment")
+)
+
+type Service interface {
+	CreateIntent(ctx context.Context, orderID, accountID string, amount money.Money, paymentMethod, idempotencyKey string, capture bool) (*Intent, error)
+	GetIntent(ctx context.Context, id string) (*Intent, error)
+	GetIntentsForOrder(ctx context.Context, orderID string) ([]Intent, error)
+	Capture(ctx context.Context, id string) (*Intent, error)
+	Void(ctx context.Context, id string) (*Intent, error)
+	Refund(ctx context.Context, id string) (*Intent, error)
+	HandleEvent(ctx context.Context, e Event) (*Intent, error)
+	GetUnsyncedIntents(ctx context.Context, limit int) ([]Intent, error)
+	MarkOrderSynced(ctx context.Context, i *Intent) error
+}
+
+type paymentService struct {
+	repository Repository
+	provider   Provider
+}
+
+func NewService(r Repository, p Provider) Service {
+	return &paymentService{r, p}
+}
+
+// CreateIntent authorizes a payment of amount for an order and, if capture
+// is set, captures it right away. An order has at most one payment that was
+// not declined or voided. Retrying with the same idempotency key returns the
+// payment created first, asking the provider again if it never answered.
+func (s *paymentService) CreateIntent(
+	ctx context.Context,
+	orderID, accountID string,
+	amount money.Money,
+	paymentMethod, idempotencyKey string,
+	capture bool,
+) (*Intent, error) {
+	if !amount.Valid() || amount.Amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	i, err := s.idempotentIntent(ctx, orderID, amount, paymentMethod, idempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+	if i == nil {
+		now := time.Now().UTC()
+		i = &Intent{
+			ID:             ksuid.New().String(),
+			OrderID:        orderID,
+			AccountID:      accountID,
+			Amount:         amount,
+			Provider:       s.provider.Name(),
+			PaymentMethod:  paymentMethod,
+			Status:         StatusPending,
+			IdempotencyKey: idempotencyKey,
+			OrderSynced:    true,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+			StatusHistory: []StatusChange{{
+				To:        StatusPending,
+				Reason:    "authorization requested",
+				ChangedAt: now,
+			}},
+		}
+		err = s.repository.PutIntent(ctx, *i)
+		if err == ErrDuplicateIdempotencyKey {
+			// A concurrent request with the same key won.
+			i, err = s.idempotentIntent(ctx, orderID, amount, paymentMethod, idempotencyKey)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if i.Status == StatusPending && i.Reference == "" {
+		res, err := s.provider.Authorize(ctx, AuthorizeRequest{
+			IntentID:      i.ID,
+			Amount:        i.Amount,
+			PaymentMethod: i.PaymentMethod,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("authorize payment %s: %w", i.ID, err)
+		}
+		if i, err = s.applyResult(ctx, i, OperationAuthorize, res, ""); err != nil {
+			return nil, err
+		}
+	}
+	if capture && i.Status == StatusAuthorized {
+		return s.Capture(ctx, i.ID)
+	}
+	return i, nil
+}
+
+// idempotentIntent returns the payment created for an order with key, or nil
+// if there is none.
+func (s *paymentService) idempotentIntent(ctx context.Context, orderID string, amount money.Money, paymentMethod, key string) (*Intent, error) {
+	if key == "" {
+		return nil, nil
+	}
+	i, err := s.repository.GetIntentByIdempotencyKey(ctx, orderID, key)
+	if err == ErrIntentNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if i.Amount != amount || i.PaymentMethod != paymentMethod {
+		return nil, ErrIdempotencyKeyReused
+	}
+	return i, nil
+}
+
+func (s *paymentService) GetIntent(ctx context.Context, id string) (*Intent, error) {
+	return s.repository.GetIntent(ctx, id)
+}
+
+func (s *paymentService) GetIntentsForOrder(ctx context.Context, orderID string) ([]Intent, error) {
+	return s.repository.GetIntentsForOrder(ctx, orderID)
+}
+
+// Capture charges the full authorized amount.
+func (s *paymentService) Capture(ctx context.Context, id string) (*Intent, error) {
+	return s.perform(ctx, id, OperationCapture, func(i *Intent) (Result, error) {
+		return s.provider.Capture(ctx, i.Reference, i.Amount)
+	})
+}
+
+// Void releases an authorization that was not captured.
+func (s *paymentService) Void(ctx context.Context, id string) (*Intent, error) {
+	return s.perform(ctx, id, OperationVoid, func(i *Intent) (Result, error) {
+		return s.provider.Void(ctx, i.Reference)
+	})
+}
+
+// Refund pays the full captured amount back.
+func (s *paymentService) Refund(ctx context.Context, id string) (*Intent, error) {
+	return s.perform(ctx, id, OperationRefund, func(i *Intent) (Result, error) {
+		return s.provider.Refund(ctx, i.Reference, i.Amount)
+	})
+}
+
+// perform moves a payment to the status waiting for op, asks the provider
+// to do it and records the outcome. Retrying an operation that is still
+// waiting asks the provider again.
+func (s *paymentService) perform(ctx context.Context, id string, op Operation, call func(i *Intent) (Result, error)) (*Intent, error) {
+	i, err := s.repository.GetIntent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if waiting := pendingStatus[op]; i.Status != waiting {
+		i, err = s.changeStatus(ctx, i, StatusChange{To: waiting, Reason: string(op) + " requested"}, "")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	res, err := call(i)
+	if err != nil {
+		return nil, fmt.Errorf("%s payment %s: %w", op, i.ID, err)
+	}
+	return s.applyResult(ctx, i, op, res, "")
+}
+
+// HandleEvent records the outcome a provider reported for an operation
+// that was pending. Repeated deliveries of an event are ignored. Events for
+// operations the payment is not waiting for fail with ErrInvalidTransition;
+// providers retry them, so events arriving before the payment was recorded
+// as pending are applied later.
+func (s *paymentService) HandleEvent(ctx context.Context, e Event) (*Intent, error) {
+	i, err := s.repository.GetIntentByReference(ctx, s.provider.Name(), e.Reference)
+	if err != nil {
+		return nil, err
+	}
+	for _, change := range i.StatusHistory {
+		if change.EventID == e.ID {
+			return i, nil
+		}
+	}
+
+	to, ok := outcomes[e.Operation][e.Outcome]
+	if !ok {
+		return nil, ErrInvalidTransition
+	}
+	if i.Status == to {
+		// The provider answered the request already.
+		return i, nil
+	}
+	if i.Status != pendingStatus[e.Operation] {
+		return nil, ErrInvalidTransition
+	}
+	applied, err := s.applyResult(ctx, i, e.Operation, Result{
+		Reference:     e.Reference,
+		Outcome:       e.Outcome,
+		FailureReason: e.FailureReason,
+	}, e.ID)
+	if err == errDuplicateEvent {
+		return s.repository.GetIntent(ctx, i.ID)
+	}
+	return applied, err
+}
+
+// applyResult moves a payment waiting for op to the status the outcome of
+// the operation leads to.
+func (s *paymentService) applyResult(ctx context.Context, i *Intent, op Operation, res Result, eventID string) (*Intent, error) {
+	to, ok := outcomes[op][res.Outcome]
+	if !ok {
+		return nil, fmt.Errorf("provider returned unknown outcome %q", res.Outcome)
+	}
+	if op == OperationAuthorize && res.Reference != "" && i.Reference == "" {
+		i.Reference = res.Reference
+		if to == i.Status {
+			// Still pending, but the reference matches the callback to come.
+			return i, s.repository.PutIntentReference(ctx, i.ID, i.Reference)
+		}
+	}
+	if to == i.Status {
+		return i, nil
+	}
+
+	reason := string(op) + " " + string(res.Outcome)
+	if res.FailureReason != "" {
+		reason += ": " + res.FailureReason
+	}
+	return s.changeStatus(ctx, i, StatusChange{To: to, Reason: reason, EventID: eventID}, res.FailureReason)
+}
+
+func (s *paymentService) changeStatus(ctx context.Context, i *Intent, change StatusChange, failureReason string) (*Intent, error) {
+	if !i.Status.CanTransitionTo(change.To) {
+		return nil, ErrInvalidTransition
+	}
+	change.From = i.Status
+	change.ChangedAt = time.Now().UTC()
+	// Orders follow their payments once captured or refunded.
+	synced := change.To != StatusCaptured && change.To != StatusRefunded
+	err := s.repository.PutIntentStatus(ctx, i.ID, change, i.Reference, failureReason, synced)
+	if err != nil {
+		return nil, err
+	}
+
+	i.Status = change.To
+	i.FailureReason = failureReason
+	i.OrderSynced = synced
+	i.UpdatedAt = change.ChangedAt
+	i.StatusHistory = append(i.StatusHistory, change)
+	return i, nil
+}
+
+// GetUnsyncedIntents returns captured and refunded payments whose orders
+// were not moved along yet, oldest first.
+func (s *paymentService) GetUnsyncedIntents(ctx context.Context, limit int) ([]Intent, error) {
+	return s.repository.GetUnsyncedIntents(ctx, limit)
+}
+
+// MarkOrderSynced records that the order of i was moved along with its
+// current status.
+func (s *paymentService) MarkOrderSynced(ctx context.Context, i *Intent) error {
+	if err := s.repository.PutOrderSynced(ctx, i.ID, i.Status); err != nil {
+		return err
+	}
+	i.OrderSynced = true
+	return nil
+}
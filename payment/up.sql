@@ -0,0 +1,45 @@
+-- A payment intent follows one payment of an order through the provider,
+-- from authorization to capture and possibly refund. Amounts are integers in
+-- the minor unit of their currency, e.g. cents.
+CREATE TABLE IF NOT EXISTS payment_intents (
+    id VARCHAR(36) PRIMARY KEY,
+    order_id VARCHAR(36) NOT NULL,
+    account_id VARCHAR(36) NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    currency CHAR(3) NOT NULL,
+    provider VARCHAR(50) NOT NULL,
+    reference VARCHAR(255) NOT NULL DEFAULT '',
+    payment_method VARCHAR(100) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL,
+    failure_reason TEXT NOT NULL DEFAULT '',
+    idempotency_key VARCHAR(255),
+    -- False while the order has not been moved along with a captured or
+    -- refunded payment.
+    order_synced BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (order_id, idempotency_key)
+);
+
+-- An order has at most one payment that was not declined or voided.
+CREATE UNIQUE INDEX IF NOT EXISTS payment_intents_active_order_idx ON payment_intents (order_id)
+    WHERE status NOT IN ('declined', 'voided');
+
+CREATE INDEX IF NOT EXISTS payment_intents_reference_idx ON payment_intents (provider, reference)
+    WHERE reference <> '';
+
+CREATE INDEX IF NOT EXISTS payment_intents_unsynced_idx ON payment_intents (updated_at)
+    WHERE NOT order_synced;
+
+-- Changes made by provider callbacks keep the ID of the event, so that
+-- repeated deliveries are only applied once.
+CREATE TABLE IF NOT EXISTS payment_status_history (
+    id BIGSERIAL PRIMARY KEY,
+    intent_id VARCHAR(36) NOT NULL REFERENCES payment_intents(id) ON DELETE CASCADE,
+    from_status VARCHAR(20),
+    to_status VARCHAR(20) NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    event_id VARCHAR(255),
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (intent_id, event_id)
+);